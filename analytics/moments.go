@@ -0,0 +1,28 @@
+package analytics
+
+import (
+	"math"
+)
+
+// moments accumulates the mean and variance of a series with Welford's
+// algorithm, so that the series does not have to be kept in memory.
+type moments struct {
+	count uint
+	mean  float64
+	m2    float64
+}
+
+func (m *moments) add(x float64) {
+	m.count++
+	delta := x - m.mean
+	m.mean += delta / float64(m.count)
+	m.m2 += delta * (x - m.mean)
+}
+
+// deviation returns the sample standard deviation of the series.
+func (m *moments) deviation() float64 {
+	if m.count < 2 {
+		return 0
+	}
+	return math.Sqrt(m.m2 / float64(m.count-1))
+}
@@ -0,0 +1,117 @@
+package analytics
+
+import (
+	"math"
+	"time"
+)
+
+// Period is an interval during which a strategy was worth less than its
+// benchmark, with the largest shortfall relative to the benchmark.
+type Period struct {
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Shortfall float64   `json:"shortfall"`
+}
+
+// Comparison holds the statistics of a strategy relative to a benchmark over
+// a run; the excess return is on the common input, while tracking error and
+// information ratio are annualized from the returns between records.
+type Comparison struct {
+	Strategy         string   `json:"strategy"`
+	Benchmark        string   `json:"benchmark"`
+	Excess           float64  `json:"excess"`
+	TrackingError    float64  `json:"tracking_error"`
+	InformationRatio float64  `json:"information_ratio"`
+	Periods          []Period `json:"periods"`
+}
+
+// Relative tracks the value of a strategy relative to a benchmark that was
+// started with the same input, during the same run.
+type Relative struct {
+	Strategy  string
+	Benchmark string
+	Input     float64
+	Ratio     float64 // value of the strategy relative to the benchmark
+	Excess    float64 // difference in value to the benchmark
+
+	start  time.Time
+	last   time.Time
+	value  float64
+	bench  float64
+	active moments // differences between the returns of each step
+	closed []Period
+	open   *Period
+}
+
+func NewRelative(strategy string, benchmark string, input float64) *Relative {
+
+	r := Relative{
+		Strategy:  strategy,
+		Benchmark: benchmark,
+		Input:     input,
+		closed:    []Period{},
+	}
+
+	return &r
+}
+
+// Observe adds the values of the strategy and of the benchmark at the given
+// time.
+func (r *Relative) Observe(timestamp time.Time, value float64, bench float64) {
+
+	if r.start.IsZero() {
+		r.start = timestamp
+	} else if r.value > 0 && r.bench > 0 {
+		r.active.add((value/r.value - 1) - (bench/r.bench - 1))
+	}
+
+	r.Ratio = ratio(value, bench)
+	r.Excess = value - bench
+
+	// An underperformance period starts when the strategy falls behind the
+	// benchmark, and ends when it catches up again.
+	switch {
+	case value < bench && r.open == nil:
+		r.open = &Period{
+			Start:     timestamp,
+			End:       timestamp,
+			Shortfall: 1 - r.Ratio,
+		}
+	case value < bench:
+		r.open.End = timestamp
+		r.open.Shortfall = math.Max(r.open.Shortfall, 1-r.Ratio)
+	case r.open != nil:
+		r.open.End = timestamp
+		r.closed = append(r.closed, *r.open)
+		r.open = nil
+	}
+
+	r.last = timestamp
+	r.value = value
+	r.bench = bench
+}
+
+// Summarize computes the statistics of the comparison so far; a period that
+// is still open ends at the last observation.
+func (r *Relative) Summarize() Comparison {
+
+	years := float64(r.last.Sub(r.start)) / float64(year)
+	scale := math.Sqrt(ratio(float64(r.active.count), years))
+
+	periods := make([]Period, len(r.closed), len(r.closed)+1)
+	copy(periods, r.closed)
+	if r.open != nil {
+		periods = append(periods, *r.open)
+	}
+
+	comparison := Comparison{
+		Strategy:         r.Strategy,
+		Benchmark:        r.Benchmark,
+		Excess:           ratio(r.value-r.bench, r.Input),
+		TrackingError:    r.active.deviation() * scale,
+		InformationRatio: ratio(r.active.mean, r.active.deviation()) * scale,
+		Periods:          periods,
+	}
+
+	return comparison
+}
@@ -0,0 +1,56 @@
+package analytics
+
+import (
+	"fmt"
+	"time"
+)
+
+// Columns are the headers of the statistics of a summary in tables, in the
+// order of the cells returned by Cells.
+var Columns = []string{"Return", "APR", "APY", "Volatility", "Sharpe", "Sortino", "Max Drawdown", "Duration", "Calmar", "Actions", "Fees", "Costs", "TWR", "MWR"}
+
+// Summary holds the performance statistics of a strategy over a run. Returns
+// and ratios are fractions, not percentages, and annualized statistics are
+// extrapolated from the length of the run.
+type Summary struct {
+	Strategy         string        `json:"strategy"`
+	Start            time.Time     `json:"start"`
+	End              time.Time     `json:"end"`
+	Input            float64       `json:"input"`
+	Value            float64       `json:"value"`
+	Return           float64       `json:"return"`
+	APR              float64       `json:"apr"`
+	APY              float64       `json:"apy"`
+	Volatility       float64       `json:"volatility"`
+	Sharpe           float64       `json:"sharpe"`
+	Sortino          float64       `json:"sortino"`
+	MaxDrawdown      float64       `json:"max_drawdown"`
+	DrawdownDuration time.Duration `json:"drawdown_duration"`
+	Calmar           float64       `json:"calmar"`
+	Actions          uint          `json:"actions"` // rehedges, rebalances, buys or rolls, depending on the strategy
+	Fees             float64       `json:"fees"`    // swap fees paid
+	Costs            float64       `json:"costs"`   // gas, keeper fees and sandwich losses paid
+	TWR              float64       `json:"twr"`     // annualized time-weighted return
+	MWR              float64       `json:"mwr"`     // annualized money-weighted return
+}
+
+// Cells formats the statistics of the summary for tables, with returns and
+// ratios in percent, in the order of Columns.
+func (s Summary) Cells() []string {
+	return []string{
+		fmt.Sprintf("%.2f%%", s.Return*100),
+		fmt.Sprintf("%.2f%%", s.APR*100),
+		fmt.Sprintf("%.2f%%", s.APY*100),
+		fmt.Sprintf("%.2f%%", s.Volatility*100),
+		fmt.Sprintf("%.2f", s.Sharpe),
+		fmt.Sprintf("%.2f", s.Sortino),
+		fmt.Sprintf("%.2f%%", s.MaxDrawdown*100),
+		s.DrawdownDuration.String(),
+		fmt.Sprintf("%.2f", s.Calmar),
+		fmt.Sprintf("%d", s.Actions),
+		fmt.Sprintf("%.2f", s.Fees),
+		fmt.Sprintf("%.2f", s.Costs),
+		fmt.Sprintf("%.2f%%", s.TWR*100),
+		fmt.Sprintf("%.2f%%", s.MWR*100),
+	}
+}
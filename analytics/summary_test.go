@@ -0,0 +1,39 @@
+package analytics
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSummaryCells(t *testing.T) {
+
+	summary := Summary{
+		Strategy:         "uniswap",
+		Return:           0.0123,
+		APR:              0.05,
+		APY:              -0.5,
+		Volatility:       0.3,
+		Sharpe:           1.234,
+		Sortino:          2.345,
+		MaxDrawdown:      0.1,
+		DrawdownDuration: 90 * time.Minute,
+		Calmar:           0.5,
+		Actions:          7,
+		Fees:             12.345,
+		Costs:            6.789,
+		TWR:              0.04,
+		MWR:              0.03,
+	}
+
+	cells := summary.Cells()
+	if len(cells) != len(Columns) {
+		t.Fatalf("wrong number of cells (have: %d, want: %d)", len(cells), len(Columns))
+	}
+
+	have := strings.Join(cells, "|")
+	want := "1.23%|5.00%|-50.00%|30.00%|1.23|2.35|10.00%|1h30m0s|0.50|7|12.35|6.79|4.00%|3.00%"
+	if have != want {
+		t.Errorf("wrong cells (have: %s, want: %s)", have, want)
+	}
+}
@@ -0,0 +1,243 @@
+package analytics
+
+import (
+	"math"
+	"time"
+)
+
+const year = 365 * 24 * time.Hour
+
+// tolerance is the magnitude below which a divisor is treated as zero; it
+// keeps rounding noise, like the deviation of the returns of a lending
+// position from the lend rate, from producing huge ratios.
+const tolerance = 1e-9
+
+// limit bounds the continuously compounded annual rate when solving for the
+// internal rate of return.
+const limit = 1e6
+
+// Tracker accumulates the statistics of the value of a strategy as the run
+// progresses, without keeping the value series in memory.
+type Tracker struct {
+	Strategy string
+	Input    float64 // stable coin amount deposited at the start
+
+	start time.Time
+	last  time.Time
+	value float64
+
+	returns  moments // returns of each step
+	excess   moments // returns of each step above the lend rate
+	downside float64 // sum of squared negative excess returns
+	growth   float64 // chain-linked growth since the first value
+
+	peak     float64
+	peakTime time.Time
+	depth    float64       // deepest drawdown since the last peak
+	drawdown float64       // deepest drawdown of the previous episodes
+	duration time.Duration // duration of the deepest drawdown episode
+
+	deposits []deposit // money put to work, if not all of the input at the start
+}
+
+// deposit is a cash flow into the strategy.
+type deposit struct {
+	timestamp time.Time
+	amount    float64
+}
+
+func NewTracker(strategy string, input float64) *Tracker {
+
+	t := Tracker{
+		Strategy: strategy,
+		Input:    input,
+		growth:   1,
+	}
+
+	return &t
+}
+
+// Deposit records that the given amount of the input was put to work at the
+// given time, for strategies that deploy their input gradually, like dollar
+// cost averaging. Without deposits, the whole input counts as deposited at
+// the start.
+func (t *Tracker) Deposit(timestamp time.Time, amount float64) {
+	t.deposits = append(t.deposits, deposit{timestamp: timestamp, amount: amount})
+}
+
+// Observe adds the value of the strategy at the given time, along with the
+// annual lend rate that applied since the previous value, which is the
+// risk-free rate for the excess returns.
+func (t *Tracker) Observe(timestamp time.Time, value float64, rate float64) {
+
+	if t.start.IsZero() {
+		t.start = timestamp
+		t.last = timestamp
+		t.value = value
+		t.peak = value
+		t.peakTime = timestamp
+		return
+	}
+
+	// A strategy that lost all its value has no meaningful returns.
+	if t.value > 0 {
+		ret := value/t.value - 1
+		riskless := rate * float64(timestamp.Sub(t.last)) / float64(year)
+		excess := ret - riskless
+
+		t.returns.add(ret)
+		t.excess.add(excess)
+		if excess < 0 {
+			t.downside += excess * excess
+		}
+		t.growth *= 1 + ret
+	}
+
+	// A drawdown episode lasts from a peak until the value recovers to it;
+	// its duration is only known once it recovers or the run ends.
+	if value >= t.peak {
+		if t.depth > t.drawdown {
+			t.drawdown = t.depth
+			t.duration = timestamp.Sub(t.peakTime)
+		}
+		t.peak = value
+		t.peakTime = timestamp
+		t.depth = 0
+	} else if t.peak > 0 {
+		t.depth = math.Max(t.depth, 1-value/t.peak)
+	}
+
+	t.last = timestamp
+	t.value = value
+}
+
+// Summarize computes the statistics of the run so far, given the fees and
+// costs the strategy paid and how often it was rehedged.
+func (t *Tracker) Summarize(fees float64, costs float64, actions uint) Summary {
+
+	years := float64(t.last.Sub(t.start)) / float64(year)
+	steps := float64(t.returns.count)
+	scale := math.Sqrt(ratio(steps, years))
+
+	total := ratio(t.value, t.Input) - 1
+
+	drawdown, duration := t.drawdown, t.duration
+	if t.depth > drawdown {
+		drawdown = t.depth
+		duration = t.last.Sub(t.peakTime)
+	}
+
+	var downside float64
+	if steps > 0 {
+		downside = math.Sqrt(t.downside / steps)
+	}
+
+	// The time-weighted return only starts from the value after entry, while
+	// the money-weighted return is the internal rate of return of the
+	// deposits, which includes the entry costs.
+	apy := annualize(1+total, years)
+
+	summary := Summary{
+		Strategy:         t.Strategy,
+		Start:            t.start,
+		End:              t.last,
+		Input:            t.Input,
+		Value:            t.value,
+		Return:           total,
+		APR:              ratio(total, years),
+		APY:              apy,
+		Volatility:       t.returns.deviation() * scale,
+		Sharpe:           ratio(t.excess.mean, t.excess.deviation()) * scale,
+		Sortino:          ratio(t.excess.mean, downside) * scale,
+		MaxDrawdown:      drawdown,
+		DrawdownDuration: duration,
+		Calmar:           ratio(apy, drawdown),
+		Actions:          actions,
+		Fees:             fees,
+		Costs:            costs,
+		TWR:              annualize(t.growth, years),
+		MWR:              t.irr(),
+	}
+
+	return summary
+}
+
+// irr returns the annual internal rate of return of the deposits, given the
+// last value. The part of the input that was not deposited is held in the
+// stable coin at face value, so it is not part of the terminal value. With the
+// input as the only deposit, it is the annualized total return.
+func (t *Tracker) irr() float64 {
+
+	deposits := t.deposits
+	terminal := t.value
+	if len(deposits) == 0 {
+		deposits = []deposit{{timestamp: t.start, amount: t.Input}}
+	} else {
+		terminal -= t.Input
+		for _, d := range deposits {
+			terminal += d.amount
+		}
+	}
+
+	// We solve for the continuously compounded rate, for which the value of
+	// the deposits at the end grows monotonically.
+	var total, span float64
+	years := make([]float64, 0, len(deposits))
+	for _, d := range deposits {
+		total += d.amount
+		years = append(years, float64(t.last.Sub(d.timestamp))/float64(year))
+		span = math.Max(span, years[len(years)-1])
+	}
+	grown := func(rate float64) float64 {
+		var value float64
+		for i, d := range deposits {
+			value += d.amount * math.Exp(rate*years[i])
+		}
+		return value
+	}
+
+	switch {
+	case terminal <= 0:
+		return -1
+	case span == 0:
+		return ratio(terminal, total) - 1
+	}
+
+	low, high := -1.0, 1.0
+	for grown(low) > terminal && low > -limit {
+		low *= 2
+	}
+	for grown(high) < terminal && high < limit {
+		high *= 2
+	}
+	for i := 0; i < 200; i++ {
+		middle := (low + high) / 2
+		if grown(middle) < terminal {
+			low = middle
+		} else {
+			high = middle
+		}
+	}
+
+	return math.Exp((low+high)/2) - 1
+}
+
+// ratio divides a by b, and returns zero instead of infinities when b is zero.
+func ratio(a float64, b float64) float64 {
+	if math.Abs(b) < tolerance {
+		return 0
+	}
+	return a / b
+}
+
+// annualize converts a growth factor over the given number of years into an
+// annual rate.
+func annualize(growth float64, years float64) float64 {
+	switch {
+	case growth <= 0:
+		return -1
+	case years == 0:
+		return growth - 1
+	}
+	return math.Pow(growth, 1/years) - 1
+}
@@ -0,0 +1,94 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTrackerMWR(t *testing.T) {
+
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(year)
+	half := start.Add(year / 2)
+
+	type flow struct {
+		timestamp time.Time
+		amount    float64
+	}
+
+	tests := []struct {
+		name     string
+		input    float64
+		deposits []flow
+		value    float64
+		want     float64
+	}{
+		{
+			name:  "single deposit is annualized return",
+			input: 200,
+			value: 220,
+			want:  0.10,
+		},
+		{
+			name:     "deposits over time",
+			input:    200,
+			deposits: []flow{{start, 100}, {half, 100}},
+			value:    100*1.1 + 100*math.Sqrt(1.1),
+			want:     0.10,
+		},
+		{
+			name:     "undeposited input is held at face value",
+			input:    200,
+			deposits: []flow{{start, 100}},
+			value:    100 + 110,
+			want:     0.10,
+		},
+		{
+			name:     "loss",
+			input:    200,
+			deposits: []flow{{start, 100}, {half, 100}},
+			value:    100*0.8 + 100*math.Sqrt(0.8),
+			want:     -0.20,
+		},
+		{
+			name:  "everything lost",
+			input: 200,
+			value: 0,
+			want:  -1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			tracker := NewTracker("test", test.input)
+			for _, d := range test.deposits {
+				tracker.Deposit(d.timestamp, d.amount)
+			}
+			tracker.Observe(start, test.input, 0)
+			tracker.Observe(end, test.value, 0)
+
+			summary := tracker.Summarize(0, 0, 0)
+			if math.Abs(summary.MWR-test.want) > 1e-9 {
+				t.Errorf("wrong money-weighted return (have: %f, want: %f)", summary.MWR, test.want)
+			}
+		})
+	}
+}
+
+func TestTrackerMWRSingleDeposit(t *testing.T) {
+
+	// With the input as the only deposit, the money-weighted return is the
+	// annualized total return, over any duration.
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := NewTracker("test", 1_000)
+	tracker.Observe(start, 990, 0.01)
+	tracker.Observe(start.Add(24*time.Hour), 1_010, 0.01)
+	tracker.Observe(start.Add(45*24*time.Hour), 1_030, 0.01)
+
+	summary := tracker.Summarize(0, 0, 0)
+	if math.Abs(summary.MWR-summary.APY) > 1e-9 {
+		t.Errorf("money-weighted return differs from annualized return (mwr: %f, apy: %f)", summary.MWR, summary.APY)
+	}
+}
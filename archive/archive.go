@@ -0,0 +1,73 @@
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/optakt/wilhelmus/analytics"
+)
+
+// Archive holds the outputs of a backtest run, so that reports can be
+// generated from it after the run. Amounts are in whole stable coins.
+type Archive struct {
+	Chain       string                 `json:"chain"`
+	Pair        string                 `json:"pair"`
+	Strategies  []string               `json:"strategies"`
+	Parameters  map[string]string      `json:"parameters"`
+	Points      []Point                `json:"points"`
+	Events      []Event                `json:"events"`
+	Summaries   []analytics.Summary    `json:"summaries"`
+	Comparisons []analytics.Comparison `json:"comparisons"`
+}
+
+// Point is the state of all strategies at one point in time.
+type Point struct {
+	Timestamp time.Time          `json:"timestamp"`
+	Price     float64            `json:"price"` // price of the volatile token in stable coin
+	Values    map[string]float64 `json:"values"`
+	Fees      map[string]float64 `json:"fees"`  // cumulative swap fees paid
+	Costs     map[string]float64 `json:"costs"` // cumulative gas and execution costs paid
+}
+
+// Event is an action taken by a strategy, such as a rehedge.
+type Event struct {
+	Timestamp time.Time `json:"timestamp"`
+	Strategy  string    `json:"strategy"`
+	Kind      string    `json:"kind"`
+	Price     float64   `json:"price"`
+}
+
+// Read loads an archive from the given JSON file.
+func Read(file string) (*Archive, error) {
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read archive file: %w", err)
+	}
+
+	var a Archive
+	err = json.Unmarshal(data, &a)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode archive (file: %s): %w", file, err)
+	}
+
+	return &a, nil
+}
+
+// Write stores the archive in the given JSON file.
+func (a *Archive) Write(file string) error {
+
+	data, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("could not encode archive: %w", err)
+	}
+
+	err = os.WriteFile(file, data, 0644)
+	if err != nil {
+		return fmt.Errorf("could not write archive file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package artifact
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/optakt/wilhelmus/analytics"
+)
+
+// Artifact is the summary of a backtest run, meant to be checked in and
+// compared between runs. It holds no timing information about the run
+// itself, so that runs on the same data with the same parameters produce
+// identical artifacts.
+type Artifact struct {
+	Chain       string                 `json:"chain"`
+	Pair        string                 `json:"pair"`
+	Parameters  map[string]string      `json:"parameters"`
+	Data        Data                   `json:"data"`
+	Strategies  []Strategy             `json:"strategies"`
+	Comparisons []analytics.Comparison `json:"comparisons"`
+}
+
+// Data identifies the records a run used.
+type Data struct {
+	Source      string    `json:"source"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Records     uint      `json:"records"`
+	Fingerprint string    `json:"fingerprint"` // SHA-256 of the records
+}
+
+// Strategy holds the final metrics of a strategy, including the value that
+// could have been withdrawn at the end of the run.
+type Strategy struct {
+	analytics.Summary
+	Realizable float64 `json:"realizable"`
+}
+
+// WriteJSON stores the artifact as indented JSON in the given file.
+func (a *Artifact) WriteJSON(file string) error {
+
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode summary: %w", err)
+	}
+	data = append(data, '\n')
+
+	err = os.WriteFile(file, data, 0644)
+	if err != nil {
+		return fmt.Errorf("could not write summary file: %w", err)
+	}
+
+	return nil
+}
+
+// WriteMarkdown stores the artifact rendered as Markdown in the given file.
+func (a *Artifact) WriteMarkdown(file string) error {
+
+	err := os.WriteFile(file, []byte(a.Markdown()), 0644)
+	if err != nil {
+		return fmt.Errorf("could not write summary file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package artifact
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/optakt/wilhelmus/analytics"
+)
+
+// Markdown renders the artifact as Markdown tables, with values rounded so
+// that only meaningful changes show up in diffs.
+func (a *Artifact) Markdown() string {
+
+	var md strings.Builder
+
+	fmt.Fprintf(&md, "# Backtest %s on %s\n\n", a.Pair, a.Chain)
+
+	fmt.Fprintf(&md, "## Data\n\n")
+	fmt.Fprintf(&md, "| Source | Start | End | Records | Fingerprint |\n")
+	fmt.Fprintf(&md, "|---|---|---|---:|---|\n")
+	fmt.Fprintf(&md, "| %s | %s | %s | %d | `%s` |\n\n",
+		a.Data.Source,
+		a.Data.Start.Format(time.RFC3339),
+		a.Data.End.Format(time.RFC3339),
+		a.Data.Records,
+		a.Data.Fingerprint,
+	)
+
+	fmt.Fprintf(&md, "## Strategies\n\n")
+	fmt.Fprintf(&md, "| Strategy | Value | Realizable | %s |\n", strings.Join(analytics.Columns, " | "))
+	fmt.Fprintf(&md, "|---|---:|---:%s|\n", strings.Repeat("|---:", len(analytics.Columns)))
+	for _, s := range a.Strategies {
+		fmt.Fprintf(&md, "| %s | %.2f | %.2f | %s |\n", s.Strategy, s.Value, s.Realizable, strings.Join(s.Cells(), " | "))
+	}
+	fmt.Fprintf(&md, "\n")
+
+	if len(a.Comparisons) > 0 {
+		fmt.Fprintf(&md, "## Benchmark\n\n")
+		fmt.Fprintf(&md, "| Strategy | Benchmark | Excess | Tracking Error | Information Ratio | Underperforming Periods |\n")
+		fmt.Fprintf(&md, "|---|---|---:|---:|---:|---:|\n")
+		for _, c := range a.Comparisons {
+			fmt.Fprintf(&md, "| %s | %s | %.2f%% | %.2f%% | %.2f | %d |\n",
+				c.Strategy,
+				c.Benchmark,
+				c.Excess*100,
+				c.TrackingError*100,
+				c.InformationRatio,
+				len(c.Periods),
+			)
+		}
+		fmt.Fprintf(&md, "\n")
+	}
+
+	names := make([]string, 0, len(a.Parameters))
+	for name := range a.Parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(&md, "## Parameters\n\n")
+	fmt.Fprintf(&md, "| Flag | Value |\n")
+	fmt.Fprintf(&md, "|---|---|\n")
+	for _, name := range names {
+		value := a.Parameters[name]
+		if value != "" {
+			value = "`" + value + "`"
+		}
+		fmt.Fprintf(&md, "| %s | %s |\n", name, value)
+	}
+
+	return md.String()
+}
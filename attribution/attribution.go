@@ -0,0 +1,159 @@
+package attribution
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/optakt/wilhelmus/b"
+	"github.com/optakt/wilhelmus/util"
+)
+
+// Attribution decomposes the value of a strategy relative to hold, step by
+// step. Within a step, the liquidity and token1 exposure at its start are
+// held constant, so the price move splits into the move on the difference in
+// exposure against hold, and the impermanent loss of the liquidity against
+// holding its tokens. Everything that the state does not explain ends up in
+// the residual, so that the components always add up to the difference.
+type Attribution struct {
+	Strategy string
+	Step     Breakdown // components of the last step
+	Total    Breakdown // components since the start
+
+	started  bool
+	previous State
+	hold     State
+	reserve0 *big.Int
+	reserve1 *big.Int
+}
+
+func New(strategy string) *Attribution {
+
+	a := Attribution{
+		Strategy: strategy,
+		Step:     zero(),
+		Total:    zero(),
+	}
+
+	return &a
+}
+
+// Update attributes the change of the difference between the strategy and
+// hold since the previous update. The first update attributes the difference
+// at entry to the fees and costs paid to enter.
+func (a *Attribution) Update(state State, hold State, reserve0 *big.Int, reserve1 *big.Int) error {
+
+	step := zero()
+
+	diff := big.NewInt(0).Sub(state.Value0, hold.Value0)
+
+	if !a.started {
+
+		step.Fees0.Sub(hold.Fees0, state.Fees0)
+		step.Gas0.Sub(hold.Cost0, state.Cost0)
+
+		a.started = true
+
+	} else {
+
+		// The price move over the step applies to the exposure at its start,
+		// valued at the current price.
+		previous1 := big.NewInt(0).Add(amount1(a.previous.Liquidity, a.reserve0, a.reserve1), a.previous.Extra1)
+		previous1.Sub(previous1, a.hold.Extra1)
+		exposure0, err := move(previous1, a.reserve0, a.reserve1, reserve0, reserve1)
+		if err != nil {
+			return fmt.Errorf("could not attribute exposure: %w", err)
+		}
+		step.Exposure0 = exposure0
+
+		// The liquidity is worth less than the tokens it held at the start of
+		// the step would be worth now.
+		held1, err := quote(amount1(a.previous.Liquidity, a.reserve0, a.reserve1), reserve1, reserve0)
+		if err != nil {
+			return fmt.Errorf("could not attribute impermanent loss: %w", err)
+		}
+		held0 := big.NewInt(0).Add(amount0(a.previous.Liquidity, a.reserve0, a.reserve1), held1)
+		step.Impermanent0.Sub(value0(a.previous.Liquidity, reserve0, reserve1), held0)
+
+		step.Income0.Sub(state.Income0, a.previous.Income0)
+		step.Rewards0.Sub(state.Rewards0, a.previous.Rewards0)
+		step.Yield0.Sub(state.Yield0, a.previous.Yield0)
+		interest0, err := quote(big.NewInt(0).Sub(a.previous.Interest1, state.Interest1), reserve1, reserve0)
+		if err != nil {
+			return fmt.Errorf("could not attribute interest: %w", err)
+		}
+		step.Interest0 = interest0
+		step.Funding0.Sub(state.Funding0, a.previous.Funding0)
+		step.Hedge0.Sub(state.Hedge0, a.previous.Hedge0)
+
+		step.Slippage0.Sub(a.previous.Fees0, state.Fees0)
+		step.Slippage0.Add(step.Slippage0, big.NewInt(0).Sub(hold.Fees0, a.hold.Fees0))
+		step.Gas0.Sub(a.previous.Cost0, state.Cost0)
+		step.Gas0.Add(step.Gas0, big.NewInt(0).Sub(hold.Cost0, a.hold.Cost0))
+	}
+
+	// The residual is the change in difference that was not explained.
+	change := big.NewInt(0).Sub(diff, a.Total.Sum())
+	step.Residual0.Sub(change, step.Sum())
+
+	for i, component := range a.Total.components() {
+		component.Add(component, step.components()[i])
+	}
+
+	a.Step = step
+	a.previous = state.clone()
+	a.hold = hold.clone()
+	a.reserve0 = big.NewInt(0).Set(reserve0)
+	a.reserve1 = big.NewInt(0).Set(reserve1)
+
+	return nil
+}
+
+// value0 returns the value of the given liquidity in token0.
+func value0(liquidity *big.Int, reserve0 *big.Int, reserve1 *big.Int) *big.Int {
+	value0 := amount0(liquidity, reserve0, reserve1)
+	value0.Mul(value0, b.D2)
+	return value0
+}
+
+// amount0 returns the amount of token0 held by the given liquidity.
+func amount0(liquidity *big.Int, reserve0 *big.Int, reserve1 *big.Int) *big.Int {
+	amount0 := big.NewInt(0).Mul(liquidity, big.NewInt(0).Sqrt(reserve0))
+	amount0.Div(amount0, big.NewInt(0).Sqrt(reserve1))
+	return amount0
+}
+
+// amount1 returns the amount of token1 held by the given liquidity.
+func amount1(liquidity *big.Int, reserve0 *big.Int, reserve1 *big.Int) *big.Int {
+	amount1 := big.NewInt(0).Mul(liquidity, big.NewInt(0).Sqrt(reserve1))
+	amount1.Div(amount1, big.NewInt(0).Sqrt(reserve0))
+	return amount1
+}
+
+// move returns the change in token0 value of the given token1 amount between
+// the previous and the current reserves.
+func move(amount1 *big.Int, previous0 *big.Int, previous1 *big.Int, reserve0 *big.Int, reserve1 *big.Int) (*big.Int, error) {
+
+	current0, err := quote(amount1, reserve1, reserve0)
+	if err != nil {
+		return nil, err
+	}
+	before0, err := quote(amount1, previous1, previous0)
+	if err != nil {
+		return nil, err
+	}
+
+	return big.NewInt(0).Sub(current0, before0), nil
+}
+
+// quote returns the value of the given amount of one token in the other token
+// of the pair. The attribution works on the smallest units of the tokens, so
+// it does not need to know their decimals.
+func quote(amountA *big.Int, reserveA *big.Int, reserveB *big.Int) (*big.Int, error) {
+
+	amountB, err := util.Quote(b.NewAmount(amountA, 0), b.NewAmount(reserveA, 0), b.NewAmount(reserveB, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	return amountB.Int(), nil
+}
@@ -0,0 +1,135 @@
+package attribution
+
+import (
+	"math/big"
+	"testing"
+)
+
+// pool returns constant product reserves for the given price of token1 in
+// token0, scaled by one million.
+func pool(price int64) (*big.Int, *big.Int) {
+	k, _ := big.NewInt(0).SetString("1000000000000000000000000000000000000000000000000", 10) // 1e48
+	reserve0 := big.NewInt(0).Mul(k, big.NewInt(price))
+	reserve0.Div(reserve0, big.NewInt(1_000_000))
+	reserve0.Sqrt(reserve0)
+	reserve1 := big.NewInt(0).Div(k, reserve0)
+	return reserve0, reserve1
+}
+
+func TestAttributionLiquidity(t *testing.T) {
+
+	prices := []int64{2_000_000_000, 2_200_000_000, 1_700_000_000, 1_900_000_000, 2_500_000_000, 2_000_000_000}
+
+	tests := []struct {
+		name   string
+		income int64 // swap fees earned per step
+		gas    int64 // gas paid per step
+	}{
+		{name: "with fees", income: 3_000_000_000_000_000, gas: 500_000_000_000_000},
+		{name: "zero fees", income: 0, gas: 500_000_000_000_000},
+		{name: "zero fees and gas", income: 0, gas: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			// Hold keeps the tokens that the liquidity held at entry, so the
+			// strategies only differ in how their exposure changes with the
+			// price, and in the fees and gas of the liquidity.
+			liquidity, _ := big.NewInt(0).SetString("1000000000000000000000", 10) // 1e21
+			reserve0, reserve1 := pool(prices[0])
+			hold0 := amount0(liquidity, reserve0, reserve1)
+			hold1 := amount1(liquidity, reserve0, reserve1)
+
+			a := New("uniswap")
+			income0 := big.NewInt(0)
+			cost0 := big.NewInt(0)
+			total := zero()
+			for i, price := range prices {
+
+				reserve0, reserve1 = pool(price)
+				if i > 0 {
+					income0.Add(income0, big.NewInt(test.income))
+					cost0.Add(cost0, big.NewInt(test.gas))
+				}
+
+				value0 := value0(liquidity, reserve0, reserve1)
+				value0.Add(value0, income0)
+				value0.Sub(value0, cost0)
+				state := State{
+					Value0:    value0,
+					Liquidity: liquidity,
+					Extra1:    big.NewInt(0),
+					Income0:   big.NewInt(0).Set(income0),
+					Rewards0:  big.NewInt(0),
+					Yield0:    big.NewInt(0),
+					Interest1: big.NewInt(0),
+					Funding0:  big.NewInt(0),
+					Hedge0:    big.NewInt(0),
+					Fees0:     big.NewInt(0),
+					Cost0:     big.NewInt(0).Set(cost0),
+				}
+
+				held0, err := quote(hold1, reserve1, reserve0)
+				if err != nil {
+					t.Fatalf("could not quote hold: %s", err)
+				}
+				holdValue0 := big.NewInt(0).Add(hold0, held0)
+				hold := Hold(holdValue0, hold1, big.NewInt(0), big.NewInt(0))
+
+				err = a.Update(state, hold, reserve0, reserve1)
+				if err != nil {
+					t.Fatalf("could not update attribution: %s", err)
+				}
+
+				// The total always adds up to the difference against hold.
+				diff := big.NewInt(0).Sub(value0, holdValue0)
+				if a.Total.Sum().Cmp(diff) != 0 {
+					t.Errorf("components do not add up to difference (step: %d, sum: %s, diff: %s)", i, a.Total.Sum(), diff)
+				}
+
+				// The price, impermanent loss, fee and gas components explain
+				// the step on their own, up to the rounding of the square roots,
+				// which is below a billionth of the value.
+				explained := big.NewInt(0)
+				for _, component := range []*big.Int{a.Step.Exposure0, a.Step.Impermanent0, a.Step.Income0, a.Step.Gas0} {
+					explained.Add(explained, component)
+				}
+				residual := big.NewInt(0).Sub(a.Step.Sum(), explained)
+				residual.Abs(residual)
+				if residual.Mul(residual, big.NewInt(1_000_000_000)).Cmp(value0) > 0 {
+					t.Errorf("step not explained by its components (step: %d, residual: %s)", i, a.Step.Residual0)
+				}
+
+				if i > 0 && a.Step.Income0.Cmp(big.NewInt(test.income)) != 0 {
+					t.Errorf("wrong income (step: %d, have: %s, want: %d)", i, a.Step.Income0, test.income)
+				}
+				if i > 0 && a.Step.Gas0.Cmp(big.NewInt(-test.gas)) != 0 {
+					t.Errorf("wrong gas (step: %d, have: %s, want: %d)", i, a.Step.Gas0, -test.gas)
+				}
+				if i > 0 && price != prices[i-1] && a.Step.Impermanent0.Sign() >= 0 {
+					t.Errorf("no impermanent loss on price move (step: %d, impermanent: %s)", i, a.Step.Impermanent0)
+				}
+
+				for j, component := range total.components() {
+					component.Add(component, a.Step.components()[j])
+				}
+			}
+
+			// Each step loses against the tokens held at its start, even
+			// though the loss of the whole run vanishes as the price returns.
+			if a.Total.Impermanent0.Sign() >= 0 {
+				t.Errorf("no impermanent loss over the run (impermanent: %s)", a.Total.Impermanent0)
+			}
+			if test.income == 0 && a.Total.Income0.Sign() != 0 {
+				t.Errorf("income without fees (income: %s)", a.Total.Income0)
+			}
+
+			for j, component := range total.components() {
+				if component.Cmp(a.Total.components()[j]) != 0 {
+					t.Errorf("total differs from sum of steps (component: %d, total: %s, steps: %s)", j, a.Total.components()[j], component)
+				}
+			}
+		})
+	}
+}
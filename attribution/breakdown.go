@@ -0,0 +1,72 @@
+package attribution
+
+import (
+	"math/big"
+)
+
+// Breakdown splits the difference between the value of a strategy and the
+// value of hold into its sources, in token0. Positive components are in
+// favour of the strategy.
+type Breakdown struct {
+	Exposure0    *big.Int // price moves on the difference in token1 exposure
+	Impermanent0 *big.Int // loss of the liquidity against holding its tokens
+	Income0      *big.Int // swap fees earned
+	Rewards0     *big.Int // reward tokens earned
+	Yield0       *big.Int // lending yield earned
+	Interest0    *big.Int // borrow interest paid
+	Funding0     *big.Int // funding received
+	Hedge0       *big.Int // result of options and other hedges
+	Fees0        *big.Int // swap fees paid to enter, beyond those of hold
+	Slippage0    *big.Int // swap fees and price impact paid to rehedge
+	Gas0         *big.Int // gas and execution costs, beyond those of hold
+	Residual0    *big.Int // remainder, such as differences in entry amounts
+}
+
+func zero() Breakdown {
+
+	breakdown := Breakdown{
+		Exposure0:    big.NewInt(0),
+		Impermanent0: big.NewInt(0),
+		Income0:      big.NewInt(0),
+		Rewards0:     big.NewInt(0),
+		Yield0:       big.NewInt(0),
+		Interest0:    big.NewInt(0),
+		Funding0:     big.NewInt(0),
+		Hedge0:       big.NewInt(0),
+		Fees0:        big.NewInt(0),
+		Slippage0:    big.NewInt(0),
+		Gas0:         big.NewInt(0),
+		Residual0:    big.NewInt(0),
+	}
+
+	return breakdown
+}
+
+// components lists the components in a fixed order, so that they can be
+// summed and accumulated together.
+func (b Breakdown) components() []*big.Int {
+	return []*big.Int{
+		b.Exposure0,
+		b.Impermanent0,
+		b.Income0,
+		b.Rewards0,
+		b.Yield0,
+		b.Interest0,
+		b.Funding0,
+		b.Hedge0,
+		b.Fees0,
+		b.Slippage0,
+		b.Gas0,
+		b.Residual0,
+	}
+}
+
+// Sum returns the total of all components, which equals the difference in
+// value against hold.
+func (b Breakdown) Sum() *big.Int {
+	sum := big.NewInt(0)
+	for _, component := range b.components() {
+		sum.Add(sum, component)
+	}
+	return sum
+}
@@ -0,0 +1,63 @@
+package attribution
+
+import (
+	"math/big"
+)
+
+// State is what the attribution needs to know about a strategy at one point
+// in time. Amounts are in token0 unless their name says otherwise, and all of
+// them except the value and holdings are cumulative since the start.
+type State struct {
+	Value0    *big.Int // value of the strategy
+	Liquidity *big.Int // liquidity provided to the pair
+	Extra1    *big.Int // token1 exposure besides the liquidity, negative for debt or shorts
+	Income0   *big.Int // swap fees earned by the liquidity
+	Rewards0  *big.Int // net value of the reward tokens
+	Yield0    *big.Int // yield earned on lent collateral
+	Interest1 *big.Int // interest owed on borrowed token1
+	Funding0  *big.Int // funding received on perpetual futures
+	Hedge0    *big.Int // result of hedges that are not part of the token1 exposure
+	Fees0     *big.Int // swap and trading fees paid
+	Cost0     *big.Int // gas and other execution costs paid
+}
+
+// Hold returns the state of a hold position with the given value, token1
+// amount and fees and costs paid, which serves as benchmark.
+func Hold(value0 *big.Int, amount1 *big.Int, fees0 *big.Int, cost0 *big.Int) State {
+
+	state := State{
+		Value0:    value0,
+		Liquidity: big.NewInt(0),
+		Extra1:    amount1,
+		Income0:   big.NewInt(0),
+		Rewards0:  big.NewInt(0),
+		Yield0:    big.NewInt(0),
+		Interest1: big.NewInt(0),
+		Funding0:  big.NewInt(0),
+		Hedge0:    big.NewInt(0),
+		Fees0:     fees0,
+		Cost0:     cost0,
+	}
+
+	return state
+}
+
+// clone copies the state, as positions update their amounts in place.
+func (s State) clone() State {
+
+	state := State{
+		Value0:    big.NewInt(0).Set(s.Value0),
+		Liquidity: big.NewInt(0).Set(s.Liquidity),
+		Extra1:    big.NewInt(0).Set(s.Extra1),
+		Income0:   big.NewInt(0).Set(s.Income0),
+		Rewards0:  big.NewInt(0).Set(s.Rewards0),
+		Yield0:    big.NewInt(0).Set(s.Yield0),
+		Interest1: big.NewInt(0).Set(s.Interest1),
+		Funding0:  big.NewInt(0).Set(s.Funding0),
+		Hedge0:    big.NewInt(0).Set(s.Hedge0),
+		Fees0:     big.NewInt(0).Set(s.Fees0),
+		Cost0:     big.NewInt(0).Set(s.Cost0),
+	}
+
+	return state
+}
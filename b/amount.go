@@ -0,0 +1,172 @@
+package b
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Amount is a token amount in the smallest unit of the token, together with
+// the number of decimals of the token, so that amounts of tokens with
+// different decimals can not be mixed up. Arithmetic is checked like the
+// uint256 math of the contracts, except that amounts can be negative, so that
+// they can hold differences such as losses.
+type Amount struct {
+	value    *big.Int
+	decimals uint
+}
+
+func NewAmount(value *big.Int, decimals uint) Amount {
+	return Amount{value: big.NewInt(0).Set(value), decimals: decimals}
+}
+
+// checked returns the given value as an amount with the given decimals, or an
+// error if it does not fit into 256 bits.
+func checked(value *big.Int, decimals uint) (Amount, error) {
+	if value.CmpAbs(MAX) > 0 {
+		return Amount{}, fmt.Errorf("amount overflows 256 bits (value: %s)", value)
+	}
+	return Amount{value: value, decimals: decimals}, nil
+}
+
+// int returns the value of the amount, which is zero for the zero value of the
+// type.
+func (a Amount) int() *big.Int {
+	if a.value == nil {
+		return D0
+	}
+	return a.value
+}
+
+// Int returns a copy of the amount in the smallest unit of the token.
+func (a Amount) Int() *big.Int {
+	return big.NewInt(0).Set(a.int())
+}
+
+func (a Amount) Decimals() uint {
+	return a.decimals
+}
+
+func (a Amount) Sign() int {
+	return a.int().Sign()
+}
+
+func (a Amount) Float() float64 {
+	return ToFloat(a.int(), a.decimals)
+}
+
+func (a Amount) Neg() Amount {
+	return Amount{value: big.NewInt(0).Neg(a.int()), decimals: a.decimals}
+}
+
+func (a Amount) Abs() Amount {
+	return Amount{value: big.NewInt(0).Abs(a.int()), decimals: a.decimals}
+}
+
+// Rescale converts the amount to the given number of decimals, rounding with
+// the given mode when decimals are dropped.
+func (a Amount) Rescale(decimals uint, mode Rounding) Amount {
+
+	if decimals >= a.decimals {
+		scale := big.NewInt(0).Exp(D10, big.NewInt(int64(decimals-a.decimals)), nil)
+		return Amount{value: big.NewInt(0).Mul(a.int(), scale), decimals: decimals}
+	}
+
+	scale := big.NewInt(0).Exp(D10, big.NewInt(int64(a.decimals-decimals)), nil)
+	return Amount{value: Div(a.int(), scale, mode), decimals: decimals}
+}
+
+// Add returns the sum of both amounts, which need to have the same decimals.
+func (a Amount) Add(o Amount) (Amount, error) {
+	if a.decimals != o.decimals {
+		return Amount{}, fmt.Errorf("mismatched decimals (left: %d, right: %d)", a.decimals, o.decimals)
+	}
+	return checked(big.NewInt(0).Add(a.int(), o.int()), a.decimals)
+}
+
+// Sub returns the difference of both amounts, which need to have the same
+// decimals.
+func (a Amount) Sub(o Amount) (Amount, error) {
+	if a.decimals != o.decimals {
+		return Amount{}, fmt.Errorf("mismatched decimals (left: %d, right: %d)", a.decimals, o.decimals)
+	}
+	return checked(big.NewInt(0).Sub(a.int(), o.int()), a.decimals)
+}
+
+// Mul returns the product of both amounts, whose decimals are the sum of the
+// decimals of both amounts; use Rescale to bring it back to a token.
+func (a Amount) Mul(o Amount) (Amount, error) {
+	return checked(big.NewInt(0).Mul(a.int(), o.int()), a.decimals+o.decimals)
+}
+
+// Div returns the quotient of both amounts, rounded with the given mode, whose
+// decimals are the decimals of the amount minus the decimals of the divisor.
+func (a Amount) Div(o Amount, mode Rounding) (Amount, error) {
+	if o.decimals > a.decimals {
+		return Amount{}, fmt.Errorf("divisor has more decimals (dividend: %d, divisor: %d)", a.decimals, o.decimals)
+	}
+	if o.Sign() <= 0 {
+		return Amount{}, fmt.Errorf("divisor is not positive (divisor: %s)", o.int())
+	}
+	return Amount{value: Div(a.int(), o.int(), mode), decimals: a.decimals - o.decimals}, nil
+}
+
+// Sqrt returns the square root of the amount, rounded down, whose decimals are
+// half the decimals of the amount.
+func (a Amount) Sqrt() (Amount, error) {
+	if a.decimals%2 != 0 {
+		return Amount{}, fmt.Errorf("square root of odd decimals (decimals: %d)", a.decimals)
+	}
+	if a.Sign() < 0 {
+		return Amount{}, fmt.Errorf("square root of negative amount (value: %s)", a.int())
+	}
+	return Amount{value: big.NewInt(0).Sqrt(a.int()), decimals: a.decimals / 2}, nil
+}
+
+// MulBps scales the amount by the given ratio, rounding with the given mode.
+func (a Amount) MulBps(p Bps, mode Rounding) (Amount, error) {
+	return checked(p.Scale(a.int(), mode), a.decimals)
+}
+
+// MulMultiple scales the amount by the given multiple, rounding with the given
+// mode.
+func (a Amount) MulMultiple(m Multiple, mode Rounding) (Amount, error) {
+	return checked(m.Scale(a.int(), mode), a.decimals)
+}
+
+// MulRay scales the amount by the given ray, rounding half up.
+func (a Amount) MulRay(r Ray) (Amount, error) {
+	return checked(r.Scale(a.int()), a.decimals)
+}
+
+// MulWad scales the amount by the given wad, rounding half up.
+func (a Amount) MulWad(w Wad) (Amount, error) {
+	return checked(w.Scale(a.int()), a.decimals)
+}
+
+// Cmp compares both amounts, which need to have the same decimals.
+func (a Amount) Cmp(o Amount) (int, error) {
+	if a.decimals != o.decimals {
+		return 0, fmt.Errorf("mismatched decimals (left: %d, right: %d)", a.decimals, o.decimals)
+	}
+	return a.int().Cmp(o.int()), nil
+}
+
+func (a Amount) String() string {
+	scale := big.NewInt(0).Exp(D10, big.NewInt(int64(a.decimals)), nil)
+	return big.NewRat(0, 1).SetFrac(a.int(), scale).FloatString(int(a.decimals))
+}
+
+// Sum adds up the given amounts, which need to have the same decimals.
+func Sum(first Amount, rest ...Amount) (Amount, error) {
+
+	sum := first
+	for _, amount := range rest {
+		var err error
+		sum, err = sum.Add(amount)
+		if err != nil {
+			return Amount{}, err
+		}
+	}
+
+	return Amount{value: sum.Int(), decimals: sum.decimals}, nil
+}
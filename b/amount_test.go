@@ -0,0 +1,117 @@
+package b
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestAmountArithmetic(t *testing.T) {
+
+	usdc := func(value int64) Amount { return NewAmount(big.NewInt(value), 6) }
+	weth := func(value int64) Amount { return NewAmount(big.NewInt(value), 18) }
+	huge := NewAmount(MAX, 18)
+
+	tests := []struct {
+		name     string
+		op       func() (Amount, error)
+		want     *big.Int
+		decimals uint
+		invalid  bool
+	}{
+		{name: "add", op: func() (Amount, error) { return usdc(1_500_000).Add(usdc(250_000)) }, want: big.NewInt(1_750_000), decimals: 6},
+		{name: "add mismatched", op: func() (Amount, error) { return usdc(1).Add(weth(1)) }, invalid: true},
+		{name: "add overflow", op: func() (Amount, error) { return huge.Add(weth(1)) }, invalid: true},
+		{name: "sub", op: func() (Amount, error) { return usdc(250_000).Sub(usdc(1_500_000)) }, want: big.NewInt(-1_250_000), decimals: 6},
+		{name: "sub mismatched", op: func() (Amount, error) { return weth(1).Sub(usdc(1)) }, invalid: true},
+		{name: "sub overflow", op: func() (Amount, error) { return huge.Neg().Sub(weth(1)) }, invalid: true},
+		{name: "mul", op: func() (Amount, error) { return usdc(2_000_000).Mul(weth(3)) }, want: big.NewInt(6_000_000), decimals: 24},
+		{name: "mul overflow", op: func() (Amount, error) { return huge.Mul(weth(2)) }, invalid: true},
+		{name: "div", op: func() (Amount, error) { return NewAmount(big.NewInt(7_000_000), 24).Div(weth(2), RoundDown) }, want: big.NewInt(3_500_000), decimals: 6},
+		{name: "div rounding", op: func() (Amount, error) { return NewAmount(big.NewInt(7), 24).Div(weth(2), RoundUp) }, want: big.NewInt(4), decimals: 6},
+		{name: "div decimals", op: func() (Amount, error) { return usdc(1).Div(weth(1), RoundDown) }, invalid: true},
+		{name: "div zero", op: func() (Amount, error) { return weth(1).Div(weth(0), RoundDown) }, invalid: true},
+		{name: "sqrt", op: func() (Amount, error) { return NewAmount(big.NewInt(17), 24).Sqrt() }, want: big.NewInt(4), decimals: 12},
+		{name: "sqrt odd", op: func() (Amount, error) { return NewAmount(big.NewInt(16), 7).Sqrt() }, invalid: true},
+		{name: "sqrt negative", op: func() (Amount, error) { return weth(-4).Sqrt() }, invalid: true},
+		{name: "mul bps", op: func() (Amount, error) { return usdc(1_000_001).MulBps(NewBps(big.NewInt(30)), RoundUp) }, want: big.NewInt(3_001), decimals: 6},
+		{name: "mul ray", op: func() (Amount, error) { return usdc(1_000_000).MulRay(NewRay(big.NewInt(0).Mul(E27, D2))) }, want: big.NewInt(2_000_000), decimals: 6},
+		{name: "sum", op: func() (Amount, error) { return Sum(usdc(1), usdc(2), usdc(3)) }, want: big.NewInt(6), decimals: 6},
+		{name: "sum mismatched", op: func() (Amount, error) { return Sum(usdc(1), usdc(2), weth(3)) }, invalid: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			have, err := test.op()
+			if test.invalid {
+				if err == nil {
+					t.Errorf("no error for invalid operation (result: %s)", have)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("could not compute amount: %s", err)
+			}
+			if have.Int().Cmp(test.want) != 0 {
+				t.Errorf("wrong value (have: %s, want: %s)", have.Int(), test.want)
+			}
+			if have.Decimals() != test.decimals {
+				t.Errorf("wrong decimals (have: %d, want: %d)", have.Decimals(), test.decimals)
+			}
+		})
+	}
+}
+
+func TestAmountRescale(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		amount   Amount
+		decimals uint
+		mode     Rounding
+		want     *big.Int
+	}{
+		{name: "up", amount: NewAmount(big.NewInt(1_234_567), 6), decimals: 18, mode: RoundDown, want: big.NewInt(1_234_567_000_000_000_000)},
+		{name: "same", amount: NewAmount(big.NewInt(1_234_567), 6), decimals: 6, mode: RoundUp, want: big.NewInt(1_234_567)},
+		{name: "down", amount: NewAmount(big.NewInt(1_234_567), 6), decimals: 2, mode: RoundDown, want: big.NewInt(123)},
+		{name: "half up", amount: NewAmount(big.NewInt(1_235_000), 6), decimals: 2, mode: RoundHalfUp, want: big.NewInt(124)},
+		{name: "half down", amount: NewAmount(big.NewInt(1_234_999), 6), decimals: 2, mode: RoundHalfUp, want: big.NewInt(123)},
+		{name: "up rounding", amount: NewAmount(big.NewInt(1_230_001), 6), decimals: 2, mode: RoundUp, want: big.NewInt(124)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			have := test.amount.Rescale(test.decimals, test.mode)
+			if have.Int().Cmp(test.want) != 0 {
+				t.Errorf("wrong value (have: %s, want: %s)", have.Int(), test.want)
+			}
+			if have.Decimals() != test.decimals {
+				t.Errorf("wrong decimals (have: %d, want: %d)", have.Decimals(), test.decimals)
+			}
+		})
+	}
+}
+
+func TestAmountZeroValue(t *testing.T) {
+
+	var zero Amount
+	one := NewAmount(D1, 0)
+
+	if zero.Sign() != 0 {
+		t.Errorf("zero amount has non-zero sign (sign: %d)", zero.Sign())
+	}
+	if zero.Float() != 0 {
+		t.Errorf("zero amount has non-zero float (float: %f)", zero.Float())
+	}
+	sum, err := zero.Add(one)
+	if err != nil || sum.Int().Cmp(D1) != 0 {
+		t.Errorf("zero amount added to one is not one (sum: %s, err: %v)", sum, err)
+	}
+	cmp, err := zero.Cmp(one)
+	if err != nil || cmp >= 0 {
+		t.Errorf("zero amount does not compare below one (cmp: %d, err: %v)", cmp, err)
+	}
+	if zero.String() != "0" {
+		t.Errorf("wrong string for zero amount (have: %s, want: 0)", zero.String())
+	}
+}
@@ -0,0 +1,63 @@
+package b
+
+import (
+	"math/big"
+)
+
+// Bps is a ratio in basis points, so 1/10000 units, as used for trading fees
+// and slippage tolerances.
+type Bps struct {
+	value *big.Int
+}
+
+func NewBps(value *big.Int) Bps {
+	return Bps{value: big.NewInt(0).Set(value)}
+}
+
+// int returns the value of the ratio, which is zero for the zero value of the
+// type.
+func (p Bps) int() *big.Int {
+	if p.value == nil {
+		return D0
+	}
+	return p.value
+}
+
+// Int returns a copy of the ratio as 1/10000 units.
+func (p Bps) Int() *big.Int {
+	return big.NewInt(0).Set(p.int())
+}
+
+func (p Bps) Sign() int {
+	return p.int().Sign()
+}
+
+func (p Bps) Cmp(o Bps) int {
+	return p.int().Cmp(o.int())
+}
+
+// Valid returns whether the ratio is at least zero and below one.
+func (p Bps) Valid() bool {
+	return p.int().Sign() >= 0 && p.int().Cmp(E4) < 0
+}
+
+func (p Bps) Float() float64 {
+	return ToFloat(p.int(), 4)
+}
+
+// Complement returns one minus the ratio.
+func (p Bps) Complement() Bps {
+	return Bps{value: big.NewInt(0).Sub(E4, p.int())}
+}
+
+// Scale multiplies the given integer by the ratio, rounding with the given
+// mode.
+func (p Bps) Scale(x *big.Int, mode Rounding) *big.Int {
+	z := big.NewInt(0).Mul(x, p.int())
+	return Div(z, E4, mode)
+}
+
+// Ray converts the ratio to a ray without loss of precision.
+func (p Bps) Ray() Ray {
+	return Ray{value: big.NewInt(0).Mul(p.int(), E23)}
+}
@@ -0,0 +1,38 @@
+package b
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestBpsZeroValue(t *testing.T) {
+
+	var zero Bps
+
+	if zero.Sign() != 0 {
+		t.Errorf("zero ratio has non-zero sign (sign: %d)", zero.Sign())
+	}
+	if zero.Int().Sign() != 0 {
+		t.Errorf("zero ratio has non-zero value (value: %s)", zero.Int())
+	}
+	if zero.Float() != 0 {
+		t.Errorf("zero ratio has non-zero float (float: %f)", zero.Float())
+	}
+	if !zero.Valid() {
+		t.Errorf("zero ratio is not valid")
+	}
+	if zero.Cmp(NewBps(D1)) >= 0 {
+		t.Errorf("zero ratio does not compare below one basis point")
+	}
+	if zero.Complement().Cmp(NewBps(E4)) != 0 {
+		t.Errorf("complement of zero ratio is not one (complement: %s)", zero.Complement().Int())
+	}
+	for _, mode := range []Rounding{RoundDown, RoundHalfUp, RoundUp} {
+		if zero.Scale(big.NewInt(1_000), mode).Sign() != 0 {
+			t.Errorf("zero ratio scales to non-zero value (mode: %d)", mode)
+		}
+	}
+	if zero.Ray().Sign() != 0 {
+		t.Errorf("zero ratio converts to non-zero ray")
+	}
+}
@@ -5,7 +5,8 @@ import (
 )
 
 var (
-	HPY  = big.NewInt(0).Mul(D365, D24)  // hours per year
-	SPY  = big.NewInt(0).Mul(HPY, D3600) // seconds per year
-	HALF = big.NewInt(0).Div(E27, D2)    // Half Ray
+	HPY  = big.NewInt(0).Mul(D365, D24)                      // hours per year
+	SPY  = big.NewInt(0).Mul(HPY, D3600)                     // seconds per year
+	HALF = big.NewInt(0).Div(E27, D2)                        // Half Ray
+	MAX  = big.NewInt(0).Sub(big.NewInt(0).Lsh(D1, 256), D1) // largest uint256
 )
@@ -9,7 +9,9 @@ var (
 	D1    = big.NewInt(1)
 	D2    = big.NewInt(2)
 	D3    = big.NewInt(3)
+	D4    = big.NewInt(4)
 	D6    = big.NewInt(6)
+	D9    = big.NewInt(9)
 	D10   = big.NewInt(10)
 	D14   = big.NewInt(14)
 	D18   = big.NewInt(18)
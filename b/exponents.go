@@ -6,7 +6,9 @@ import (
 
 var (
 	E3  = big.NewInt(0).Exp(D10, D3, nil)
+	E4  = big.NewInt(0).Exp(D10, D4, nil)
 	E6  = big.NewInt(0).Exp(D10, D6, nil)
+	E9  = big.NewInt(0).Exp(D10, D9, nil)
 	E14 = big.NewInt(0).Exp(D10, D14, nil)
 	E18 = big.NewInt(0).Exp(D10, D18, nil)
 	E23 = big.NewInt(0).Exp(D10, D23, nil)
@@ -0,0 +1,12 @@
+package b
+
+import (
+	"math"
+	"math/big"
+)
+
+func FromFloat(f float64, decimals uint) *big.Int {
+	d := math.Pow(10, float64(decimals))
+	n, _ := big.NewFloat(f * d).Int(nil)
+	return n
+}
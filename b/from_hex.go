@@ -2,18 +2,24 @@ package b
 
 import (
 	"encoding/hex"
+	"fmt"
 	"math/big"
 )
 
-func FromHex(v interface{}) *big.Int {
+// FromHex decodes a big integer stored as a hex-encoded string. It fails if
+// the value is missing, is not a string or is not valid hex.
+func FromHex(v interface{}) (*big.Int, error) {
+	if v == nil {
+		return nil, fmt.Errorf("value is missing")
+	}
 	s, ok := v.(string)
 	if !ok {
-		return big.NewInt(0)
+		return nil, fmt.Errorf("value is not a string (type: %T)", v)
 	}
 	bytes, err := hex.DecodeString(s)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("could not decode hex value: %w", err)
 	}
 	b := big.NewInt(0).SetBytes(bytes)
-	return b
+	return b, nil
 }
@@ -0,0 +1,42 @@
+package b
+
+import (
+	"testing"
+)
+
+func TestFromHex(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		input interface{}
+		want  string
+		fail  bool
+	}{
+		{name: "positive", input: "0f4240", want: "1000000"},
+		{name: "zero", input: "", want: "0"},
+		{name: "signed", input: "-0f4240", fail: true},
+		{name: "missing", input: nil, fail: true},
+		{name: "not a string", input: 42, fail: true},
+		{name: "odd length", input: "f4240", fail: true},
+		{name: "not hex", input: "xyz0", fail: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			value, err := FromHex(test.input)
+			if test.fail {
+				if err == nil {
+					t.Errorf("expected error (input: %v, value: %s)", test.input, value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (input: %v): %s", test.input, err)
+			}
+			if value.String() != test.want {
+				t.Errorf("wrong value (input: %v, have: %s, want: %s)", test.input, value, test.want)
+			}
+		})
+	}
+}
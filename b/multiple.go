@@ -0,0 +1,49 @@
+package b
+
+import (
+	"math/big"
+)
+
+// Multiple is a factor in basis points, so 1/10000 units, which can be above
+// one, as used for leverages, strike ratios and fee multiples. Unlike Bps, it
+// is not limited to ratios below one.
+type Multiple struct {
+	value *big.Int
+}
+
+func NewMultiple(value *big.Int) Multiple {
+	return Multiple{value: big.NewInt(0).Set(value)}
+}
+
+// int returns the value of the multiple, which is zero for the zero value of
+// the type.
+func (m Multiple) int() *big.Int {
+	if m.value == nil {
+		return D0
+	}
+	return m.value
+}
+
+// Int returns a copy of the multiple as 1/10000 units.
+func (m Multiple) Int() *big.Int {
+	return big.NewInt(0).Set(m.int())
+}
+
+func (m Multiple) Sign() int {
+	return m.int().Sign()
+}
+
+func (m Multiple) Cmp(o Multiple) int {
+	return m.int().Cmp(o.int())
+}
+
+func (m Multiple) Float() float64 {
+	return ToFloat(m.int(), 4)
+}
+
+// Scale multiplies the given integer by the multiple, rounding with the given
+// mode.
+func (m Multiple) Scale(x *big.Int, mode Rounding) *big.Int {
+	z := big.NewInt(0).Mul(x, m.int())
+	return Div(z, E4, mode)
+}
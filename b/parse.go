@@ -0,0 +1,81 @@
+package b
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ParseBps parses an exact decimal ratio, such as "0.0005", "5bps" or "2.5%",
+// into basis points, without going through floats. It fails if the ratio is
+// more precise than a basis point.
+func ParseBps(s string) (Bps, error) {
+
+	value, err := parse(s, E4)
+	if err != nil {
+		return Bps{}, err
+	}
+
+	return Bps{value: value}, nil
+}
+
+// ParseMultiple parses an exact decimal factor, such as "2", "1.05" or "150%",
+// into basis points, without going through floats. It fails if the factor is
+// more precise than a basis point.
+func ParseMultiple(s string) (Multiple, error) {
+
+	value, err := parse(s, E4)
+	if err != nil {
+		return Multiple{}, err
+	}
+
+	return Multiple{value: value}, nil
+}
+
+// ParseRay parses an exact decimal ratio, such as "0.025", "250bps" or "2.5%",
+// into a ray, without going through floats. It fails if the ratio is more
+// precise than a ray.
+func ParseRay(s string) (Ray, error) {
+
+	value, err := parse(s, E27)
+	if err != nil {
+		return Ray{}, err
+	}
+
+	return Ray{value: value}, nil
+}
+
+// parse converts the given ratio into an integer of the given unit. Ratios
+// can not be negative.
+func parse(s string, unit *big.Int) (*big.Int, error) {
+
+	number := strings.TrimSpace(s)
+	scale := big.NewRat(1, 1)
+	switch {
+	case strings.HasSuffix(number, "bps"):
+		number = strings.TrimSuffix(number, "bps")
+		scale.SetFrac64(1, 10_000)
+	case strings.HasSuffix(number, "bp"):
+		number = strings.TrimSuffix(number, "bp")
+		scale.SetFrac64(1, 10_000)
+	case strings.HasSuffix(number, "%"):
+		number = strings.TrimSuffix(number, "%")
+		scale.SetFrac64(1, 100)
+	}
+
+	ratio, ok := big.NewRat(0, 1).SetString(strings.TrimSpace(number))
+	if !ok {
+		return nil, fmt.Errorf("invalid ratio (ratio: %s)", s)
+	}
+	if ratio.Sign() < 0 {
+		return nil, fmt.Errorf("ratio is negative (ratio: %s)", s)
+	}
+	ratio.Mul(ratio, scale)
+	ratio.Mul(ratio, big.NewRat(0, 1).SetInt(unit))
+
+	if !ratio.IsInt() {
+		return nil, fmt.Errorf("ratio is too precise (ratio: %s, unit: 1/%s)", s, unit)
+	}
+
+	return big.NewInt(0).Set(ratio.Num()), nil
+}
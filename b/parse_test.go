@@ -0,0 +1,124 @@
+package b
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestParseBps(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		input string
+		want  int64
+		fail  bool
+	}{
+		{name: "decimal", input: "0.0005", want: 5},
+		{name: "basis points", input: "5bps", want: 5},
+		{name: "single basis point", input: "1bp", want: 1},
+		{name: "percent", input: "2.5%", want: 250},
+		{name: "surrounding spaces", input: " 30 bps ", want: 30},
+		{name: "zero", input: "0", want: 0},
+		{name: "sub basis point", input: "0.00005", fail: true},
+		{name: "sub basis point in basis points", input: "0.5bps", fail: true},
+		{name: "negative", input: "-0.0005", fail: true},
+		{name: "negative percent", input: "-1%", fail: true},
+		{name: "garbage", input: "abc", fail: true},
+		{name: "empty", input: "", fail: true},
+		{name: "unit only", input: "bps", fail: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			ratio, err := ParseBps(test.input)
+			if test.fail {
+				if err == nil {
+					t.Errorf("expected error (input: %q, ratio: %s)", test.input, ratio.Int())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (input: %q): %s", test.input, err)
+			}
+			if ratio.Int().Cmp(big.NewInt(test.want)) != 0 {
+				t.Errorf("wrong ratio (input: %q, have: %s, want: %d)", test.input, ratio.Int(), test.want)
+			}
+		})
+	}
+}
+
+func TestParseRay(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+		fail  bool
+	}{
+		{name: "decimal", input: "0.0005", want: "500000000000000000000000"},
+		{name: "basis points", input: "5bps", want: "500000000000000000000000"},
+		{name: "percent", input: "2.5%", want: "25000000000000000000000000"},
+		{name: "sub basis point", input: "0.00005", want: "50000000000000000000000"},
+		{name: "smallest unit", input: "0.000000000000000000000000001", want: "1"},
+		{name: "too precise", input: "0.0000000000000000000000000001", fail: true},
+		{name: "negative", input: "-0.0005", fail: true},
+		{name: "garbage", input: "1.2.3", fail: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			rate, err := ParseRay(test.input)
+			if test.fail {
+				if err == nil {
+					t.Errorf("expected error (input: %q, rate: %s)", test.input, rate.Int())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (input: %q): %s", test.input, err)
+			}
+			if rate.Int().String() != test.want {
+				t.Errorf("wrong rate (input: %q, have: %s, want: %s)", test.input, rate.Int(), test.want)
+			}
+		})
+	}
+}
+
+func TestParseMultiple(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		input string
+		want  int64
+		fail  bool
+	}{
+		{name: "leverage", input: "2", want: 20_000},
+		{name: "strike", input: "1.05", want: 10_500},
+		{name: "percent", input: "150%", want: 15_000},
+		{name: "large multiple", input: "100", want: 1_000_000},
+		{name: "sub basis point", input: "1.00001", fail: true},
+		{name: "negative", input: "-2", fail: true},
+		{name: "garbage", input: "2x", fail: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			multiple, err := ParseMultiple(test.input)
+			if test.fail {
+				if err == nil {
+					t.Errorf("expected error (input: %q, multiple: %s)", test.input, multiple.Int())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error (input: %q): %s", test.input, err)
+			}
+			if multiple.Int().Cmp(big.NewInt(test.want)) != 0 {
+				t.Errorf("wrong multiple (input: %q, have: %s, want: %d)", test.input, multiple.Int(), test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package b
+
+import (
+	"math/big"
+)
+
+// Ray is a fixed-point number with 27 decimals, as used by Aave for interest
+// rates and indexes. Multiplications and divisions round half up, like Aave's
+// rayMul and rayDiv:
+// => https://github.com/aave/protocol-v2/blob/master/contracts/protocol/libraries/math/WadRayMath.sol
+type Ray struct {
+	value *big.Int
+}
+
+func NewRay(value *big.Int) Ray {
+	return Ray{value: big.NewInt(0).Set(value)}
+}
+
+// int returns the value of the ray, which is zero for the zero value of the
+// type.
+func (r Ray) int() *big.Int {
+	if r.value == nil {
+		return D0
+	}
+	return r.value
+}
+
+// Int returns a copy of the ray as 1/10^27 units.
+func (r Ray) Int() *big.Int {
+	return big.NewInt(0).Set(r.int())
+}
+
+func (r Ray) Sign() int {
+	return r.int().Sign()
+}
+
+func (r Ray) Cmp(o Ray) int {
+	return r.int().Cmp(o.int())
+}
+
+func (r Ray) Float() float64 {
+	return ToFloat(r.int(), 27)
+}
+
+func (r Ray) Add(o Ray) Ray {
+	return Ray{value: big.NewInt(0).Add(r.int(), o.int())}
+}
+
+func (r Ray) Sub(o Ray) Ray {
+	return Ray{value: big.NewInt(0).Sub(r.int(), o.int())}
+}
+
+func (r Ray) Mul(o Ray) Ray {
+	z := big.NewInt(0).Mul(r.int(), o.int())
+	return Ray{value: Div(z, E27, RoundHalfUp)}
+}
+
+func (r Ray) Div(o Ray) Ray {
+	z := big.NewInt(0).Mul(r.int(), E27)
+	return Ray{value: Div(z, o.int(), RoundHalfUp)}
+}
+
+// Scale multiplies the given integer by the ray, rounding half up.
+func (r Ray) Scale(x *big.Int) *big.Int {
+	z := big.NewInt(0).Mul(x, r.int())
+	return Div(z, E27, RoundHalfUp)
+}
+
+// Wad converts the ray to a wad, rounding half up.
+func (r Ray) Wad() Wad {
+	return Wad{value: Div(r.int(), E9, RoundHalfUp)}
+}
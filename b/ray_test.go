@@ -0,0 +1,40 @@
+package b
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestRayZeroValue(t *testing.T) {
+
+	var zero Ray
+	one := NewRay(E27)
+
+	if zero.Sign() != 0 {
+		t.Errorf("zero ray has non-zero sign (sign: %d)", zero.Sign())
+	}
+	if zero.Int().Sign() != 0 {
+		t.Errorf("zero ray has non-zero value (value: %s)", zero.Int())
+	}
+	if zero.Float() != 0 {
+		t.Errorf("zero ray has non-zero float (float: %f)", zero.Float())
+	}
+	if zero.Cmp(one) >= 0 || one.Cmp(zero) <= 0 {
+		t.Errorf("zero ray does not compare below one")
+	}
+	if zero.Add(one).Cmp(one) != 0 {
+		t.Errorf("zero ray added to one is not one")
+	}
+	if one.Sub(zero).Cmp(one) != 0 {
+		t.Errorf("zero ray subtracted from one is not one")
+	}
+	if zero.Mul(one).Sign() != 0 {
+		t.Errorf("zero ray multiplied by one is not zero")
+	}
+	if zero.Div(one).Sign() != 0 {
+		t.Errorf("zero ray divided by one is not zero")
+	}
+	if zero.Scale(big.NewInt(1_000)).Sign() != 0 {
+		t.Errorf("zero ray scales to non-zero value")
+	}
+}
@@ -0,0 +1,31 @@
+package b
+
+import (
+	"math/big"
+)
+
+// Rounding modes for fixed-point divisions.
+type Rounding uint8
+
+const (
+	RoundDown   Rounding = iota // round towards negative infinity
+	RoundHalfUp                 // round to the nearest, with halves rounded up, like Aave's rayMul and rayDiv
+	RoundUp                     // round towards positive infinity
+)
+
+// Div divides x by the positive y, rounding the quotient with the given mode.
+func Div(x *big.Int, y *big.Int, mode Rounding) *big.Int {
+
+	z := big.NewInt(0).Set(x)
+	switch mode {
+
+	case RoundHalfUp:
+		z.Add(z, big.NewInt(0).Div(y, D2))
+
+	case RoundUp:
+		z.Add(z, y)
+		z.Sub(z, D1)
+	}
+
+	return z.Div(z, y)
+}
@@ -0,0 +1,70 @@
+package b
+
+import (
+	"math/big"
+)
+
+// Wad is a fixed-point number with 18 decimals. Multiplications and divisions
+// round half up, like Aave's wadMul and wadDiv.
+type Wad struct {
+	value *big.Int
+}
+
+func NewWad(value *big.Int) Wad {
+	return Wad{value: big.NewInt(0).Set(value)}
+}
+
+// int returns the value of the wad, which is zero for the zero value of the
+// type.
+func (w Wad) int() *big.Int {
+	if w.value == nil {
+		return D0
+	}
+	return w.value
+}
+
+// Int returns a copy of the wad as 1/10^18 units.
+func (w Wad) Int() *big.Int {
+	return big.NewInt(0).Set(w.int())
+}
+
+func (w Wad) Sign() int {
+	return w.int().Sign()
+}
+
+func (w Wad) Cmp(o Wad) int {
+	return w.int().Cmp(o.int())
+}
+
+func (w Wad) Float() float64 {
+	return ToFloat(w.int(), 18)
+}
+
+func (w Wad) Add(o Wad) Wad {
+	return Wad{value: big.NewInt(0).Add(w.int(), o.int())}
+}
+
+func (w Wad) Sub(o Wad) Wad {
+	return Wad{value: big.NewInt(0).Sub(w.int(), o.int())}
+}
+
+func (w Wad) Mul(o Wad) Wad {
+	z := big.NewInt(0).Mul(w.int(), o.int())
+	return Wad{value: Div(z, E18, RoundHalfUp)}
+}
+
+func (w Wad) Div(o Wad) Wad {
+	z := big.NewInt(0).Mul(w.int(), E18)
+	return Wad{value: Div(z, o.int(), RoundHalfUp)}
+}
+
+// Scale multiplies the given integer by the wad, rounding half up.
+func (w Wad) Scale(x *big.Int) *big.Int {
+	z := big.NewInt(0).Mul(x, w.int())
+	return Div(z, E18, RoundHalfUp)
+}
+
+// Ray converts the wad to a ray without loss of precision.
+func (w Wad) Ray() Ray {
+	return Ray{value: big.NewInt(0).Mul(w.int(), E9)}
+}
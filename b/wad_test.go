@@ -0,0 +1,52 @@
+package b
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestWadArithmetic(t *testing.T) {
+
+	half := NewWad(big.NewInt(0).Div(E18, D2))
+	one := NewWad(E18)
+	three := NewWad(big.NewInt(0).Mul(E18, D3))
+
+	if have := three.Mul(half); have.Cmp(NewWad(big.NewInt(1_500_000_000_000_000_000))) != 0 {
+		t.Errorf("wrong product (have: %s, want: 1.5e18)", have.Int())
+	}
+	if have := one.Div(three); have.Cmp(NewWad(big.NewInt(333_333_333_333_333_333))) != 0 {
+		t.Errorf("wrong quotient (have: %s, want: 333333333333333333)", have.Int())
+	}
+	if have := NewWad(D2).Div(three); have.Cmp(NewWad(D1)) != 0 {
+		t.Errorf("quotient does not round half up (have: %s, want: 1)", have.Int())
+	}
+	if have := half.Scale(big.NewInt(3)); have.Cmp(D2) != 0 {
+		t.Errorf("scale does not round half up (have: %s, want: 2)", have)
+	}
+	if have := three.Sub(one).Add(half); have.Float() != 2.5 {
+		t.Errorf("wrong sum (have: %f, want: 2.5)", have.Float())
+	}
+}
+
+func TestWadRay(t *testing.T) {
+
+	wad := NewWad(big.NewInt(1_234_567_890_123_456_789))
+
+	if have := wad.Ray().Wad(); have.Cmp(wad) != 0 {
+		t.Errorf("wad does not survive conversion to ray (have: %s, want: %s)", have.Int(), wad.Int())
+	}
+
+	ray := NewRay(big.NewInt(1_500_000_000))
+	if have := ray.Wad(); have.Cmp(NewWad(D2)) != 0 {
+		t.Errorf("ray does not round half up to wad (have: %s, want: 2)", have.Int())
+	}
+	ray = NewRay(big.NewInt(1_499_999_999))
+	if have := ray.Wad(); have.Cmp(NewWad(D1)) != 0 {
+		t.Errorf("ray does not round down to wad below half (have: %s, want: 1)", have.Int())
+	}
+
+	var zero Wad
+	if zero.Sign() != 0 || zero.Ray().Sign() != 0 || zero.Mul(wad).Sign() != 0 {
+		t.Errorf("zero wad does not behave as zero")
+	}
+}
@@ -0,0 +1,51 @@
+package check
+
+import (
+	"time"
+)
+
+// Kinds of issues found in the snapshots of a source.
+const (
+	KindDecode      = "decode"      // snapshot could not be decoded
+	KindGap         = "gap"         // interval without snapshots above the maximum gap, including at the edges of the range
+	KindOrder       = "order"       // timestamp before the one of the previous snapshot
+	KindDuplicate   = "duplicate"   // timestamp equal to the one of the previous snapshot
+	KindZero        = "zero"        // reserve that is zero
+	KindImplausible = "implausible" // reserve below the plausible minimum
+	KindJump        = "jump"        // reserve change above the maximum jump
+	KindVolume      = "volume"      // negative volume
+)
+
+// Issue is a single problem found in the snapshots of a source.
+type Issue struct {
+	Kind      string     `json:"kind"`
+	Timestamp time.Time  `json:"timestamp"`
+	Previous  *time.Time `json:"previous,omitempty"`
+	Detail    string     `json:"detail,omitempty"`
+}
+
+// Report is the machine-readable result of validating a source.
+type Report struct {
+	Records uint            `json:"records"`
+	Start   time.Time       `json:"start"`
+	End     time.Time       `json:"end"`
+	Counts  map[string]uint `json:"counts"`
+	Issues  []Issue         `json:"issues"`
+}
+
+// add records an issue; a zero previous timestamp means that the issue only
+// concerns a single snapshot.
+func (r *Report) add(kind string, timestamp time.Time, previous time.Time, detail string) {
+
+	issue := Issue{
+		Kind:      kind,
+		Timestamp: timestamp,
+		Detail:    detail,
+	}
+	if !previous.IsZero() {
+		issue.Previous = &previous
+	}
+
+	r.Counts[kind]++
+	r.Issues = append(r.Issues, issue)
+}
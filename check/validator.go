@@ -0,0 +1,183 @@
+package check
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/optakt/wilhelmus/b"
+	"github.com/optakt/wilhelmus/source"
+)
+
+// Validator scans the snapshots of a source for the issues that distort a
+// backtest without making it fail.
+type Validator struct {
+	MaxGap   time.Duration // longest expected interval between snapshots
+	MaxJump  b.Bps         // largest expected change of a reserve between snapshots
+	Minimum0 *big.Int      // smallest plausible reserve0
+	Minimum1 *big.Int      // smallest plausible reserve1
+}
+
+func New(maxGap time.Duration, maxJump b.Bps, minimum0 *big.Int, minimum1 *big.Int) (*Validator, error) {
+
+	switch {
+	case maxGap <= 0:
+		return nil, fmt.Errorf("maximum gap must be positive (max_gap: %s)", maxGap)
+	case maxJump.Sign() <= 0:
+		return nil, fmt.Errorf("maximum jump must be positive (max_jump: %f)", maxJump.Float())
+	case minimum0.Sign() < 0 || minimum1.Sign() < 0:
+		return nil, fmt.Errorf("minimum reserves must not be negative")
+	}
+
+	v := Validator{
+		MaxGap:   maxGap,
+		MaxJump:  maxJump,
+		Minimum0: minimum0,
+		Minimum1: minimum1,
+	}
+
+	return &v, nil
+}
+
+// Run consumes the source and reports the issues of its snapshots, which were
+// requested for the given time range. Only a failure of the source itself is
+// returned as error.
+func (v *Validator) Run(data source.Source, start time.Time, end time.Time) (*Report, error) {
+
+	report := Report{
+		Counts: make(map[string]uint),
+		Issues: []Issue{},
+	}
+
+	var last time.Time
+	var previous *source.Snapshot
+	for data.Next() {
+
+		snapshot, err := data.Snapshot()
+		report.Records++
+		if report.Start.IsZero() || snapshot.Timestamp.Before(report.Start) {
+			report.Start = snapshot.Timestamp
+		}
+		if snapshot.Timestamp.After(report.End) {
+			report.End = snapshot.Timestamp
+		}
+
+		// Gaps and ordering are measured against the last record, whether it
+		// was valid or not, as even a broken record shows that the collector
+		// was running at that time.
+		// Records missing at the start of the range don't show up between
+		// records, so the first one is measured against the start.
+		if last.IsZero() {
+			v.edge(&report, start, snapshot.Timestamp)
+		} else {
+			v.sequence(&report, last, snapshot.Timestamp)
+		}
+		last = snapshot.Timestamp
+
+		if err != nil {
+			report.add(KindDecode, snapshot.Timestamp, time.Time{}, err.Error())
+			continue
+		}
+
+		valid := v.reserves(&report, snapshot)
+		if valid && previous != nil {
+			v.jumps(&report, *previous, snapshot)
+		}
+
+		// Volumes are the deltas swapped since the previous record, so they
+		// can never be negative.
+		if snapshot.Volume0.Sign() < 0 || snapshot.Volume1.Sign() < 0 {
+			report.add(KindVolume, snapshot.Timestamp, time.Time{},
+				fmt.Sprintf("volume0: %s, volume1: %s", snapshot.Volume0, snapshot.Volume1))
+		}
+
+		// Jumps are measured against the last valid snapshot, so that a
+		// single broken record does not show up as two jumps.
+		if valid {
+			previous = &snapshot
+		}
+	}
+
+	err := data.Err()
+	if err != nil {
+		return nil, fmt.Errorf("could not read source: %w", err)
+	}
+
+	// In the same way, the end of the range is measured against the latest
+	// record, or against the start if there were no records at all.
+	if report.Records == 0 {
+		v.edge(&report, start, end)
+	} else {
+		v.edge(&report, report.End, end)
+	}
+
+	return &report, nil
+}
+
+func (v *Validator) edge(report *Report, previous time.Time, timestamp time.Time) {
+
+	elapsed := timestamp.Sub(previous)
+	if elapsed > v.MaxGap {
+		report.add(KindGap, timestamp, previous, fmt.Sprintf("elapsed: %s", elapsed))
+	}
+}
+
+func (v *Validator) sequence(report *Report, previous time.Time, timestamp time.Time) {
+
+	elapsed := timestamp.Sub(previous)
+	switch {
+	case elapsed < 0:
+		report.add(KindOrder, timestamp, previous, fmt.Sprintf("elapsed: %s", elapsed))
+	case elapsed == 0:
+		report.add(KindDuplicate, timestamp, previous, "")
+	case elapsed > v.MaxGap:
+		report.add(KindGap, timestamp, previous, fmt.Sprintf("elapsed: %s", elapsed))
+	}
+}
+
+func (v *Validator) reserves(report *Report, snapshot source.Snapshot) bool {
+
+	valid := true
+	for i, reserve := range []*big.Int{snapshot.Reserve0, snapshot.Reserve1} {
+
+		minimum := v.Minimum0
+		if i == 1 {
+			minimum = v.Minimum1
+		}
+
+		switch {
+		case reserve.Sign() <= 0:
+			report.add(KindZero, snapshot.Timestamp, time.Time{}, fmt.Sprintf("reserve%d: %s", i, reserve))
+			valid = false
+		case reserve.Cmp(minimum) < 0:
+			report.add(KindImplausible, snapshot.Timestamp, time.Time{}, fmt.Sprintf("reserve%d: %s, minimum: %s", i, reserve, minimum))
+			valid = false
+		}
+	}
+
+	return valid
+}
+
+func (v *Validator) jumps(report *Report, previous source.Snapshot, snapshot source.Snapshot) {
+
+	befores := []*big.Int{previous.Reserve0, previous.Reserve1}
+	afters := []*big.Int{snapshot.Reserve0, snapshot.Reserve1}
+	for i := range befores {
+
+		// The change is above the maximum if |after - before| * E4 exceeds
+		// before * jump, which avoids any division.
+		change := big.NewInt(0).Sub(afters[i], befores[i])
+		change.Abs(change)
+		change.Mul(change, b.E4)
+
+		limit := big.NewInt(0).Mul(befores[i], v.MaxJump.Int())
+		if change.Cmp(limit) <= 0 {
+			continue
+		}
+
+		ratio := big.NewRat(0, 1).SetFrac(afters[i], befores[i])
+		ratioFloat, _ := ratio.Float64()
+		report.add(KindJump, snapshot.Timestamp, previous.Timestamp,
+			fmt.Sprintf("reserve%d: %s, previous: %s, ratio: %f", i, afters[i], befores[i], ratioFloat))
+	}
+}
@@ -0,0 +1,262 @@
+package check
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/optakt/wilhelmus/b"
+	"github.com/optakt/wilhelmus/source"
+)
+
+// fixture is a source that replays fixed snapshots, some of which can fail to
+// decode, and then stops with the given error.
+type fixture struct {
+	snapshots []source.Snapshot
+	errs      map[int]error
+	err       error
+	index     int
+}
+
+func (f *fixture) Next() bool {
+	if f.index >= len(f.snapshots) {
+		return false
+	}
+	f.index++
+	return true
+}
+
+func (f *fixture) Snapshot() (source.Snapshot, error) {
+	return f.snapshots[f.index-1], f.errs[f.index-1]
+}
+
+func (f *fixture) Err() error {
+	return f.err
+}
+
+var start = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// snap returns a snapshot the given number of minutes after the start.
+func snap(minutes int, reserve0 int64, reserve1 int64, volume0 int64, volume1 int64) source.Snapshot {
+	return source.Snapshot{
+		Timestamp: start.Add(time.Duration(minutes) * time.Minute),
+		Reserve0:  big.NewInt(reserve0),
+		Reserve1:  big.NewInt(reserve1),
+		Volume0:   big.NewInt(volume0),
+		Volume1:   big.NewInt(volume1),
+	}
+}
+
+func TestValidatorRun(t *testing.T) {
+
+	broken := errors.New("could not decode reserve0")
+
+	tests := []struct {
+		name      string
+		snapshots []source.Snapshot
+		errs      map[int]error
+		want      []string
+	}{
+		{
+			name:      "clean",
+			snapshots: []source.Snapshot{snap(0, 10_000, 10_000, 1, 1), snap(10, 10_100, 9_900, 1, 1), snap(20, 10_000, 10_000, 0, 0)},
+			want:      nil,
+		},
+		{
+			name:      "decode",
+			snapshots: []source.Snapshot{snap(0, 10_000, 10_000, 1, 1), {Timestamp: start.Add(10 * time.Minute)}, snap(20, 10_000, 10_000, 1, 1)},
+			errs:      map[int]error{1: broken},
+			want:      []string{KindDecode},
+		},
+		{
+			name:      "gap",
+			snapshots: []source.Snapshot{snap(0, 10_000, 10_000, 1, 1), snap(61, 10_000, 10_000, 1, 1)},
+			want:      []string{KindGap},
+		},
+		{
+			name:      "order",
+			snapshots: []source.Snapshot{snap(10, 10_000, 10_000, 1, 1), snap(0, 10_000, 10_000, 1, 1)},
+			want:      []string{KindOrder},
+		},
+		{
+			name:      "duplicate",
+			snapshots: []source.Snapshot{snap(0, 10_000, 10_000, 1, 1), snap(0, 10_000, 10_000, 1, 1)},
+			want:      []string{KindDuplicate},
+		},
+		{
+			name:      "zero",
+			snapshots: []source.Snapshot{snap(0, 10_000, 10_000, 1, 1), snap(10, 0, 10_000, 1, 1), snap(20, 10_000, 10_000, 1, 1)},
+			want:      []string{KindZero},
+		},
+		{
+			name:      "implausible",
+			snapshots: []source.Snapshot{snap(0, 10_000, 10_000, 1, 1), snap(10, 10_000, 50, 1, 1), snap(20, 10_000, 10_000, 1, 1)},
+			want:      []string{KindImplausible},
+		},
+		{
+			name:      "jump",
+			snapshots: []source.Snapshot{snap(0, 10_000, 10_000, 1, 1), snap(10, 12_001, 10_000, 1, 1)},
+			want:      []string{KindJump},
+		},
+		{
+			name:      "jump at threshold",
+			snapshots: []source.Snapshot{snap(0, 10_000, 10_000, 1, 1), snap(10, 12_000, 8_000, 1, 1)},
+			want:      nil,
+		},
+		{
+			name:      "volume",
+			snapshots: []source.Snapshot{snap(0, 10_000, 10_000, 1, 1), snap(10, 10_000, 10_000, -5, 1)},
+			want:      []string{KindVolume},
+		},
+		{
+			name:      "gap measured from broken record",
+			snapshots: []source.Snapshot{snap(0, 10_000, 10_000, 1, 1), snap(50, 0, 10_000, 1, 1), snap(100, 10_000, 10_000, 1, 1)},
+			want:      []string{KindZero},
+		},
+		{
+			name:      "gap measured from undecodable record",
+			snapshots: []source.Snapshot{snap(0, 10_000, 10_000, 1, 1), {Timestamp: start.Add(50 * time.Minute)}, snap(100, 10_000, 10_000, 1, 1)},
+			errs:      map[int]error{1: broken},
+			want:      []string{KindDecode},
+		},
+		{
+			name:      "jump measured from last valid record",
+			snapshots: []source.Snapshot{snap(0, 10_000, 10_000, 1, 1), snap(10, 0, 10_000, 1, 1), snap(20, 10_000, 10_000, 1, 1)},
+			want:      []string{KindZero},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			validator, err := New(time.Hour, b.NewBps(big.NewInt(2_000)), big.NewInt(100), big.NewInt(100))
+			if err != nil {
+				t.Fatalf("could not create validator: %s", err)
+			}
+
+			end := start
+			for _, snapshot := range test.snapshots {
+				if snapshot.Timestamp.After(end) {
+					end = snapshot.Timestamp
+				}
+			}
+
+			report, err := validator.Run(&fixture{snapshots: test.snapshots, errs: test.errs}, start, end)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if report.Records != uint(len(test.snapshots)) {
+				t.Errorf("wrong number of records (have: %d, want: %d)", report.Records, len(test.snapshots))
+			}
+
+			var kinds []string
+			for _, issue := range report.Issues {
+				kinds = append(kinds, issue.Kind)
+			}
+			if len(kinds) != len(test.want) {
+				t.Fatalf("wrong issues (have: %v, want: %v)", kinds, test.want)
+			}
+			for i := range kinds {
+				if kinds[i] != test.want[i] {
+					t.Errorf("wrong issue (index: %d, have: %s, want: %s)", i, kinds[i], test.want[i])
+				}
+				if report.Counts[test.want[i]] == 0 {
+					t.Errorf("issue not counted (kind: %s)", test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidatorRange(t *testing.T) {
+
+	validator, err := New(time.Hour, b.NewBps(big.NewInt(2_000)), big.NewInt(100), big.NewInt(100))
+	if err != nil {
+		t.Fatalf("could not create validator: %s", err)
+	}
+
+	snapshots := []source.Snapshot{snap(10, 10_000, 10_000, 1, 1), snap(0, 10_000, 10_000, 1, 1), snap(20, 10_000, 10_000, 1, 1)}
+	report, err := validator.Run(&fixture{snapshots: snapshots}, start, start.Add(20*time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !report.Start.Equal(start) || !report.End.Equal(start.Add(20*time.Minute)) {
+		t.Errorf("wrong range (start: %s, end: %s)", report.Start, report.End)
+	}
+}
+
+func TestValidatorEdges(t *testing.T) {
+
+	validator, err := New(time.Hour, b.NewBps(big.NewInt(2_000)), big.NewInt(100), big.NewInt(100))
+	if err != nil {
+		t.Fatalf("could not create validator: %s", err)
+	}
+
+	tests := []struct {
+		name      string
+		snapshots []source.Snapshot
+		end       time.Duration
+		want      []time.Time // timestamps of the reported gaps
+	}{
+		{
+			name:      "covered",
+			snapshots: []source.Snapshot{snap(30, 10_000, 10_000, 1, 1), snap(90, 10_000, 10_000, 1, 1)},
+			end:       2 * time.Hour,
+			want:      nil,
+		},
+		{
+			name:      "missing at start",
+			snapshots: []source.Snapshot{snap(90, 10_000, 10_000, 1, 1), snap(120, 10_000, 10_000, 1, 1)},
+			end:       2 * time.Hour,
+			want:      []time.Time{start.Add(90 * time.Minute)},
+		},
+		{
+			name:      "missing at end",
+			snapshots: []source.Snapshot{snap(0, 10_000, 10_000, 1, 1), snap(30, 10_000, 10_000, 1, 1)},
+			end:       2 * time.Hour,
+			want:      []time.Time{start.Add(2 * time.Hour)},
+		},
+		{
+			name:      "no records",
+			snapshots: nil,
+			end:       2 * time.Hour,
+			want:      []time.Time{start.Add(2 * time.Hour)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			report, err := validator.Run(&fixture{snapshots: test.snapshots}, start, start.Add(test.end))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if len(report.Issues) != len(test.want) {
+				t.Fatalf("wrong number of issues (have: %d, want: %d)", len(report.Issues), len(test.want))
+			}
+			for i, issue := range report.Issues {
+				if issue.Kind != KindGap || !issue.Timestamp.Equal(test.want[i]) {
+					t.Errorf("wrong issue (kind: %s, have: %s, want: %s)", issue.Kind, issue.Timestamp, test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidatorSourceError(t *testing.T) {
+
+	validator, err := New(time.Hour, b.NewBps(big.NewInt(2_000)), big.NewInt(100), big.NewInt(100))
+	if err != nil {
+		t.Fatalf("could not create validator: %s", err)
+	}
+
+	failed := errors.New("connection lost")
+	_, err = validator.Run(&fixture{snapshots: []source.Snapshot{snap(0, 10_000, 10_000, 1, 1)}, err: failed}, start, start)
+	if !errors.Is(err, failed) {
+		t.Errorf("source error not returned (have: %v, want: %s)", err, failed)
+	}
+}
@@ -0,0 +1,85 @@
+package funding
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/optakt/wilhelmus/b"
+)
+
+// Funding holds the historical funding rates of a perpetual future. Each rate
+// is the hourly rate paid by longs to shorts; negative rates are paid by
+// shorts to longs.
+type Funding struct {
+	starts []time.Time
+	rates  []b.Ray
+}
+
+func New(file string) (*Funding, error) {
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read funding rates file: %w", err)
+	}
+
+	csvr := csv.NewReader(bytes.NewReader(data))
+	csvr.FieldsPerRecord = 2
+	records, err := csvr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("could not read funding rate records: %w", err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("funding rates have no entries")
+	}
+
+	entries := records[1:]
+	sort.Slice(entries, func(i int, j int) bool {
+		return entries[i][0] < entries[j][0]
+	})
+
+	ray := big.NewRat(0, 1).SetInt(b.E27)
+
+	f := Funding{
+		starts: make([]time.Time, 0, len(entries)),
+		rates:  make([]b.Ray, 0, len(entries)),
+	}
+	for _, entry := range entries {
+
+		start, err := time.Parse(time.RFC3339, entry[0])
+		if err != nil {
+			return nil, fmt.Errorf("could not parse funding rate time: %w", err)
+		}
+
+		value, ok := big.NewRat(0, 1).SetString(entry[1])
+		if !ok {
+			return nil, fmt.Errorf("could not parse funding rate value (value: %s)", entry[1])
+		}
+		value.Mul(value, ray)
+
+		rate := b.NewRay(big.NewInt(0).Quo(value.Num(), value.Denom()))
+
+		f.starts = append(f.starts, start)
+		f.rates = append(f.rates, rate)
+	}
+
+	return &f, nil
+}
+
+// Rate returns the hourly funding rate that applies at the given timestamp,
+// which is zero before the first entry of the series.
+func (f *Funding) Rate(timestamp time.Time) b.Ray {
+
+	index := sort.Search(len(f.starts), func(i int) bool {
+		return f.starts[i].After(timestamp)
+	})
+	if index == 0 {
+		return b.NewRay(b.D0)
+	}
+
+	return f.rates[index-1]
+}
@@ -0,0 +1,35 @@
+package funding
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewMalformed(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "single column", content: "time\n2022-01-01T00:00:00Z\n"},
+		{name: "missing rate", content: "time,rate\n2022-01-01T00:00:00Z,0.0001\n2022-01-01T01:00:00Z\n"},
+		{name: "extra column", content: "time,rate\n2022-01-01T00:00:00Z,0.0001,1\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			file := filepath.Join(t.TempDir(), "funding.csv")
+			err := os.WriteFile(file, []byte(test.content), 0644)
+			if err != nil {
+				t.Fatalf("could not write funding file: %s", err)
+			}
+
+			_, err = New(file)
+			if err == nil {
+				t.Errorf("malformed funding file was accepted")
+			}
+		})
+	}
+}
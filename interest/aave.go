@@ -0,0 +1,127 @@
+package interest
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/optakt/wilhelmus/b"
+)
+
+// Parameters of the Aave v2 default reserve interest rate strategy.
+type Parameters struct {
+	Base          b.Ray
+	Slope1        b.Ray
+	Slope2        b.Ray
+	Optimal       b.Ray
+	ReserveFactor b.Ray
+}
+
+// Aave derives the supply rate of a lending market from a historical series
+// of its utilization, using the Aave v2 default reserve interest rate strategy:
+// => https://github.com/aave/protocol-v2/blob/master/contracts/protocol/lendingpool/DefaultReserveInterestRateStrategy.sol
+type Aave struct {
+	params      Parameters
+	starts      []time.Time
+	utilization []b.Ray
+}
+
+func NewAave(file string, params Parameters) (*Aave, error) {
+
+	one := b.NewRay(b.E27)
+	if params.Optimal.Sign() <= 0 || params.Optimal.Cmp(one) >= 0 {
+		return nil, fmt.Errorf("optimal utilization must be between zero and one (optimal: %f)", params.Optimal.Float())
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read utilization file: %w", err)
+	}
+
+	csvr := csv.NewReader(bytes.NewReader(data))
+	csvr.FieldsPerRecord = 2
+	records, err := csvr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("could not read utilization records: %w", err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("utilization has no entries")
+	}
+
+	entries := records[1:]
+	sort.Slice(entries, func(i int, j int) bool {
+		return entries[i][0] < entries[j][0]
+	})
+
+	ray := big.NewRat(0, 1).SetInt(b.E27)
+
+	a := Aave{
+		params:      params,
+		starts:      make([]time.Time, 0, len(entries)),
+		utilization: make([]b.Ray, 0, len(entries)),
+	}
+	for _, entry := range entries {
+
+		start, err := time.Parse(time.RFC3339, entry[0])
+		if err != nil {
+			return nil, fmt.Errorf("could not parse utilization time: %w", err)
+		}
+
+		value, ok := big.NewRat(0, 1).SetString(entry[1])
+		if !ok {
+			return nil, fmt.Errorf("could not parse utilization value (value: %s)", entry[1])
+		}
+		value.Mul(value, ray)
+
+		utilization := b.NewRay(big.NewInt(0).Quo(value.Num(), value.Denom()))
+		if utilization.Sign() < 0 || utilization.Cmp(one) > 0 {
+			return nil, fmt.Errorf("utilization must be between zero and one (utilization: %s)", entry[1])
+		}
+
+		a.starts = append(a.starts, start)
+		a.utilization = append(a.utilization, utilization)
+	}
+
+	return &a, nil
+}
+
+// Rate returns the supply rate for the utilization that applies at the given
+// timestamp, which is the first value of the series before its start.
+func (a *Aave) Rate(timestamp time.Time) b.Ray {
+
+	index := sort.Search(len(a.starts), func(i int) bool {
+		return a.starts[i].After(timestamp)
+	})
+	if index > 0 {
+		index--
+	}
+	utilization := a.utilization[index]
+
+	// Below the optimal utilization, the borrow rate grows along the first
+	// slope; above it, it grows along the much steeper second slope.
+	one := b.NewRay(b.E27)
+	borrow := a.params.Base
+	switch {
+
+	case utilization.Cmp(a.params.Optimal) <= 0:
+		borrow = borrow.Add(a.params.Slope1.Mul(utilization).Div(a.params.Optimal))
+
+	default:
+		excess := utilization.Sub(a.params.Optimal)
+		remainder := one.Sub(a.params.Optimal)
+		borrow = borrow.Add(a.params.Slope1)
+		borrow = borrow.Add(a.params.Slope2.Mul(excess).Div(remainder))
+	}
+
+	// Suppliers earn the borrow interest on the utilized part of the reserve,
+	// minus the share that goes to the protocol treasury.
+	share := one.Sub(a.params.ReserveFactor)
+
+	supply := borrow.Mul(utilization).Mul(share)
+
+	return supply
+}
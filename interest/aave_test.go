@@ -0,0 +1,42 @@
+package interest
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/optakt/wilhelmus/b"
+)
+
+func TestNewAaveMalformed(t *testing.T) {
+
+	params := Parameters{
+		Optimal: b.NewRay(big.NewInt(0).Div(big.NewInt(0).Mul(b.E27, big.NewInt(9)), big.NewInt(10))),
+	}
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "single column", content: "time\n2022-01-01T00:00:00Z\n"},
+		{name: "missing utilization", content: "time,utilization\n2022-01-01T00:00:00Z,0.5\n2022-01-02T00:00:00Z\n"},
+		{name: "extra column", content: "time,utilization\n2022-01-01T00:00:00Z,0.5,0.6\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			file := filepath.Join(t.TempDir(), "utilization.csv")
+			err := os.WriteFile(file, []byte(test.content), 0644)
+			if err != nil {
+				t.Fatalf("could not write utilization file: %s", err)
+			}
+
+			_, err = NewAave(file, params)
+			if err == nil {
+				t.Errorf("malformed utilization file was accepted")
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package interest
+
+import (
+	"time"
+
+	"github.com/optakt/wilhelmus/b"
+)
+
+// Constant is a lending market with a fixed supply rate.
+type Constant struct {
+	rate b.Ray
+}
+
+func NewConstant(rate b.Ray) *Constant {
+
+	c := Constant{
+		rate: rate,
+	}
+
+	return &c
+}
+
+func (c *Constant) Rate(timestamp time.Time) b.Ray {
+	return c.rate
+}
@@ -0,0 +1,13 @@
+package interest
+
+import (
+	"time"
+
+	"github.com/optakt/wilhelmus/b"
+)
+
+// Model provides the annual supply rate of a lending market at a given point
+// in time.
+type Model interface {
+	Rate(timestamp time.Time) b.Ray
+}
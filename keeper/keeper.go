@@ -0,0 +1,128 @@
+package keeper
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"time"
+
+	"github.com/optakt/wilhelmus/b"
+)
+
+// Fee modes for the automation fee charged by the keeper on each execution.
+const (
+	FeeNone       = "none"       // no automation fee
+	FeeFixed      = "fixed"      // fixed amount of the stable token
+	FeeGas        = "gas"        // multiple of the gas cost of the execution
+	FeePercentage = "percentage" // percentage of the notional value of the execution
+)
+
+// Keeper models the execution of automated actions by a keeper network like
+// Autonomy Network: an action is executed some time after its condition was
+// met, may fail and be retried, and is charged an automation fee.
+type Keeper struct {
+	Delay      time.Duration // minimum delay between condition and execution
+	Records    uint          // minimum number of records between condition and execution
+	Mode       string
+	Fixed0     *big.Int   // fixed fee in the stable token
+	Multiple   b.Multiple // gas cost multiple
+	Rate       b.Bps      // notional fee rate
+	Failure    float64    // probability that an execution fails
+	Executions uint
+	Failures   uint
+	Fees0      *big.Int // automation fees paid
+
+	rng     *rand.Rand
+	armed   bool
+	since   time.Time
+	elapsed uint
+}
+
+func New(delay time.Duration, records uint, mode string, fixed0 *big.Int, multiple b.Multiple, rate b.Bps, failure float64, rng *rand.Rand) (*Keeper, error) {
+
+	switch mode {
+	case FeeNone, FeeFixed, FeeGas, FeePercentage:
+	default:
+		return nil, fmt.Errorf("unknown keeper fee mode (mode: %s)", mode)
+	}
+
+	if failure < 0 || failure >= 1 {
+		return nil, fmt.Errorf("keeper failure probability must be at least zero and below one (failure: %f)", failure)
+	}
+
+	k := Keeper{
+		Delay:    delay,
+		Records:  records,
+		Mode:     mode,
+		Fixed0:   fixed0,
+		Multiple: multiple,
+		Rate:     rate,
+		Failure:  failure,
+		Fees0:    big.NewInt(0),
+		rng:      rng,
+	}
+
+	return &k, nil
+}
+
+// Ready is called on every record with whether the condition of the action is
+// currently met. Once the condition was met, the keeper is armed, and it
+// executes the action as soon as the delay has passed, retrying on the next
+// record if the execution fails. If the condition clears before execution,
+// the keeper is disarmed, as it checks the condition again when executing.
+// It returns whether the action is executed; the execution only counts once
+// its fee is charged, as there may be nothing left to do by then.
+func (k *Keeper) Ready(timestamp time.Time, met bool) bool {
+
+	if k.armed && !met {
+		k.armed = false
+		return false
+	}
+
+	if !k.armed {
+		if !met {
+			return false
+		}
+		k.armed = true
+		k.since = timestamp
+		k.elapsed = 0
+	} else {
+		k.elapsed++
+	}
+
+	if timestamp.Sub(k.since) < k.Delay || k.elapsed < k.Records {
+		return false
+	}
+
+	if k.Failure > 0 && k.rng.Float64() < k.Failure {
+		k.Failures++
+		return false
+	}
+
+	k.armed = false
+
+	return true
+}
+
+// Fee returns the automation fee for an execution with the given gas cost and
+// notional value, and records the execution and its fee.
+func (k *Keeper) Fee(gas0 *big.Int, notional0 *big.Int) *big.Int {
+
+	fee0 := big.NewInt(0)
+	switch k.Mode {
+
+	case FeeFixed:
+		fee0.Set(k.Fixed0)
+
+	case FeeGas:
+		fee0 = k.Multiple.Scale(gas0, b.RoundUp)
+
+	case FeePercentage:
+		fee0 = k.Rate.Scale(notional0, b.RoundUp)
+	}
+
+	k.Executions++
+	k.Fees0.Add(k.Fees0, fee0)
+
+	return fee0
+}
@@ -0,0 +1,65 @@
+package keeper
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/optakt/wilhelmus/b"
+	"github.com/optakt/wilhelmus/position"
+)
+
+func TestKeeperReady(t *testing.T) {
+
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	target := b.NewAmount(big.NewInt(1_000_000), 18)
+
+	tests := []struct {
+		name    string
+		amounts []int64 // hedged amount on each hourly record
+		want    []bool
+	}{
+		{
+			name:    "stays out of band",
+			amounts: []int64{1_000_000, 1_100_000, 1_100_000, 1_100_000, 1_100_000},
+			want:    []bool{false, false, false, true, false},
+		},
+		{
+			name:    "price reverts during delay",
+			amounts: []int64{1_000_000, 1_100_000, 1_010_000, 1_010_000, 1_010_000},
+			want:    []bool{false, false, false, false, false},
+		},
+		{
+			name:    "leaves band again after reverting",
+			amounts: []int64{1_100_000, 1_000_000, 1_100_000, 1_100_000, 1_100_000},
+			want:    []bool{false, false, false, false, true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			trigger, err := position.NewTrigger(position.TriggerSymmetric, b.NewBps(big.NewInt(500)), b.NewBps(big.NewInt(500)), b.NewBps(big.NewInt(0)), 0, 0, start)
+			if err != nil {
+				t.Fatalf("could not create trigger: %s", err)
+			}
+			k, err := New(2*time.Hour, 0, FeeNone, big.NewInt(0), b.Multiple{}, b.Bps{}, 0, rand.New(rand.NewSource(0)))
+			if err != nil {
+				t.Fatalf("could not create keeper: %s", err)
+			}
+
+			for i, amount := range test.amounts {
+				timestamp := start.Add(time.Duration(i) * time.Hour)
+				close1, err := trigger.Check(timestamp, b.NewAmount(big.NewInt(amount), 18), target, position.Conditions{})
+				if err != nil {
+					t.Fatalf("could not check trigger: %s", err)
+				}
+				have := k.Ready(timestamp, close1.Sign() != 0)
+				if have != test.want[i] {
+					t.Errorf("wrong execution (record: %d, have: %t, want: %t)", i, have, test.want[i])
+				}
+			}
+		})
+	}
+}
@@ -1,10 +1,11 @@
 package main
 
 import (
-	"context"
-	"fmt"
+	"io"
 	"math/big"
+	"math/rand"
 	"os"
+	"sort"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -12,47 +13,162 @@ import (
 
 	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
 
+	"github.com/optakt/wilhelmus/analytics"
+	"github.com/optakt/wilhelmus/archive"
+	"github.com/optakt/wilhelmus/artifact"
+	"github.com/optakt/wilhelmus/attribution"
 	"github.com/optakt/wilhelmus/b"
+	"github.com/optakt/wilhelmus/funding"
+	"github.com/optakt/wilhelmus/interest"
+	"github.com/optakt/wilhelmus/keeper"
+	"github.com/optakt/wilhelmus/mev"
+	"github.com/optakt/wilhelmus/option"
 	"github.com/optakt/wilhelmus/position"
+	"github.com/optakt/wilhelmus/reward"
+	"github.com/optakt/wilhelmus/source"
 	"github.com/optakt/wilhelmus/station"
+	"github.com/optakt/wilhelmus/synthetic"
+	"github.com/optakt/wilhelmus/token"
 	"github.com/optakt/wilhelmus/util"
 	"github.com/optakt/wilhelmus/write"
 )
 
-const (
-	statement = `from(bucket: "metrics")
-	|> range(start: %s, stop: %s)
-	|> filter(fn: (r) => r["_measurement"] == "Uniswap v2")
-	|> filter(fn: (r) => r["chain"] == "%s")
-	|> filter(fn: (r) => r["pair"] == "%s")
-	|> filter(fn: (r) => r["_field"] == "volume0" or r["_field"] == "reserve1" or r["_field"] == "reserve0" or r["_field"] == "volume1")
-	|> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")`
-)
-
 func main() {
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "validate":
+			validate(os.Args[2:])
+			return
+		case "scenario":
+			scenarios(os.Args[2:])
+			return
+		case "report":
+			reports(os.Args[2:])
+			return
+		}
+	}
+
+	backtest(os.Args[1:], os.Stdout)
+}
+
+// result is the outcome of a backtest, with the value realized by unwinding
+// each strategy at the end of the run.
+type result struct {
+	Input0      b.Amount
+	Exits       map[string]position.Exit
+	Summaries   []analytics.Summary
+	Comparisons []analytics.Comparison
+}
+
+// backtest runs all strategies over the records of the configured pair and
+// writes their values to the sinks. Logs are written to the given output.
+func backtest(args []string, output io.Writer) result {
+
 	var (
 		logLevel     string
 		writeResults bool
 
 		chainName        string
 		pairName         string
+		tokenFiles       []string
 		startTime        string
 		endTime          string
 		gasPrices        string
+		gasDaily         bool
+		nativePrice      float64
 		inputValue       uint64
-		flagRehedgeRatio float64
+		flagRehedgeRatio string
+		rehedgePolicy    string
+		flagRehedgeLower string
+		flagRehedgeUpper string
+		flagRehedgeInner string
+		rehedgeInterval  time.Duration
+		rehedgeReference float64
+		flagExitTimes    []string
+
+		keeperDelay        time.Duration
+		keeperRecords      uint
+		keeperFee          string
+		flagKeeperFixed    float64
+		flagKeeperMultiple string
+		flagKeeperRate     string
+		keeperFailure      float64
+		keeperSeed         int64
+
+		flagMEVTolerance string
+
+		syntheticModel          string
+		syntheticStep           time.Duration
+		syntheticSeed           int64
+		syntheticPrice          float64
+		syntheticLiquidity      float64
+		syntheticDrift          float64
+		syntheticVolatility     float64
+		syntheticJumpIntensity  float64
+		syntheticJumpMean       float64
+		syntheticJumpVolatility float64
+		syntheticRegimes        []string
+		syntheticScale          float64
+		syntheticTurnover       float64
+		syntheticSensitivity    float64
+
+		dcaInterval        time.Duration
+		dcaTranches        uint64
+		rebalanceInterval  time.Duration
+		flagRebalanceDrift string
+
+		compoundPolicy       string
+		harvestInterval      time.Duration
+		flagHarvestThreshold float64
+
+		rewardSchedule     string
+		rewardPrices       string
+		rewardDecimals     uint
+		rewardSale         string
+		rewardSaleInterval time.Duration
+		flagRewardSwapRate string
+
+		perpFunding         string
+		flagPerpLeverage    string
+		flagPerpFeeRate     string
+		flagPerpMaintenance string
+
+		optionKind         string
+		optionTenor        time.Duration
+		flagOptionStrike   string
+		optionVolatility   string
+		optionWindow       time.Duration
+		flagOptionFallback float64
+		flagOptionFeeRate  string
 
 		influxAPI              string
 		influxToken            string
 		influxOrg              string
 		influxBucketMetrics    string
 		influxBucketStrategies string
-
-		flagSwapRate   float64
-		flagFlashRate  float64
-		flagLoanRate   float64
-		flagBorrowRate float64
+		sourceName             string
+		dataPolicy             string
+		benchmark              string
+		archiveFile            string
+		archiveStep            time.Duration
+		summaryJSON            string
+		summaryMarkdown        string
+		resampleStep           time.Duration
+		resampleFill           bool
+
+		flagSwapRate   string
+		flagFlashRate  string
+		flagLoanRate   string
+		flagBorrowRate string
+
+		lendModel             string
+		lendUtilization       string
+		flagLendBase          string
+		flagLendSlope1        string
+		flagLendSlope2        string
+		flagLendOptimal       string
+		flagLendReserveFactor string
 
 		flagTransferGas uint64
 		flagApproveGas  uint64
@@ -67,6 +183,11 @@ func main() {
 		flagLendGas  uint64
 		flagClaimGas uint64
 
+		flagHarvestGas uint64
+
+		flagPerpGas   uint64
+		flagOptionGas uint64
+
 		flagBorrowGas   uint64
 		flagIncreaseGas uint64
 		flagDecreaseGas uint64
@@ -81,22 +202,104 @@ func main() {
 
 	pflag.StringVarP(&chainName, "chain-name", "c", "Ethereum Mainnet", "chain name to filter metrics")
 	pflag.StringVarP(&pairName, "pair-name", "p", "USDC/WETH", "asset pair to filter metrics")
+	pflag.StringSliceVar(&tokenFiles, "token-files", nil, "JSON files with tokens and pairs per chain, in addition to the built-in ones")
 	pflag.StringVarP(&startTime, "start-time", "s", oya.Format(time.RFC3339), "start timestamp for the backtest")
 	pflag.StringVarP(&endTime, "end-time", "e", now.Format(time.RFC3339), "end timestamp for the backtest")
 	pflag.StringVarP(&gasPrices, "gas-prices", "g", "gas-prices/ethereum.csv", "CSV containing daily gas price averages")
+	pflag.BoolVar(&gasDaily, "gas-daily", false, "whether to pay gas at the price of the day of each action instead of the first record")
+	pflag.Float64Var(&nativePrice, "native-price", 0, "stable coin price of the native token, to convert gas costs for pairs whose volatile token is not native")
 	pflag.Uint64VarP(&inputValue, "input-value", "v", 1_000_000, "stable coin input amount")
-	pflag.Float64VarP(&flagRehedgeRatio, "rehedge-ratio", "r", 0.01, "ratio between debt and collateral at which we rehedge")
+	pflag.StringVarP(&flagRehedgeRatio, "rehedge-ratio", "r", "0.01", "ratio between debt and collateral at which we rehedge")
+	pflag.StringVar(&rehedgePolicy, "rehedge-policy", position.TriggerSymmetric, "when to rehedge hedged positions (symmetric, asymmetric, time, hysteresis, gas, volatility)")
+	pflag.StringVar(&flagRehedgeLower, "rehedge-lower", "0.01", "ratio below the debt at which we rehedge for the asymmetric policy")
+	pflag.StringVar(&flagRehedgeUpper, "rehedge-upper", "0.01", "ratio above the debt at which we rehedge for the asymmetric policy")
+	pflag.StringVar(&flagRehedgeInner, "rehedge-inner", "0.0025", "ratio back to which we rehedge for the hysteresis policy")
+	pflag.DurationVar(&rehedgeInterval, "rehedge-interval", 24*time.Hour, "interval between rehedges for the time policy, and horizon for the gas policy")
+	pflag.Float64Var(&rehedgeReference, "rehedge-reference", 0.8, "volatility at which the band is unscaled for the volatility policy")
+	pflag.StringSliceVar(&flagExitTimes, "exit-times", nil, "timestamps at which to simulate exiting positions, in addition to the end")
+
+	pflag.DurationVar(&keeperDelay, "keeper-delay", 0, "minimum delay between a rehedge becoming due and its execution by the keeper")
+	pflag.UintVar(&keeperRecords, "keeper-records", 0, "minimum number of records between a rehedge becoming due and its execution by the keeper")
+	pflag.StringVar(&keeperFee, "keeper-fee", keeper.FeeNone, "automation fee charged by the keeper per execution (none, fixed, gas, percentage)")
+	pflag.Float64Var(&flagKeeperFixed, "keeper-fee-fixed", 1, "stable coin amount charged per execution for the fixed keeper fee")
+	pflag.StringVar(&flagKeeperMultiple, "keeper-fee-multiple", "0.3", "multiple of the gas cost charged per execution for the gas keeper fee")
+	pflag.StringVar(&flagKeeperRate, "keeper-fee-rate", "0.001", "ratio of the rehedged value charged per execution for the percentage keeper fee")
+	pflag.Float64Var(&keeperFailure, "keeper-failure", 0, "probability that a keeper execution fails and is retried on the next record")
+	pflag.Int64Var(&keeperSeed, "keeper-seed", 1, "seed for the random keeper execution failures")
+
+	pflag.StringVar(&flagMEVTolerance, "mev-tolerance", "0", "slippage tolerance of swaps exposed to sandwich attacks (disables MEV estimation if zero)")
+
+	pflag.DurationVar(&dcaInterval, "dca-interval", 7*24*time.Hour, "interval between buys of the dollar-cost averaging baseline")
+	pflag.Uint64Var(&dcaTranches, "dca-tranches", 52, "number of buys of the dollar-cost averaging baseline")
+	pflag.DurationVar(&rebalanceInterval, "rebalance-interval", 0, "interval between rebalances of the constant-mix baseline (disabled if zero)")
+	pflag.StringVar(&flagRebalanceDrift, "rebalance-drift", "0.05", "ratio between both legs at which the constant-mix baseline rebalances (disabled if zero)")
+
+	pflag.StringVar(&compoundPolicy, "compound-policy", position.CompoundContinuous, "when to add liquidity fees to positions (continuous, periodic, threshold, never)")
+	pflag.DurationVar(&harvestInterval, "harvest-interval", 24*time.Hour, "interval between fee harvests for periodic compounding")
+	pflag.Float64Var(&flagHarvestThreshold, "harvest-threshold", 1_000, "stable coin value of pending fees at which to harvest for threshold compounding")
+
+	pflag.StringVar(&rewardSchedule, "reward-schedule", "", "CSV containing liquidity mining emission rates (disables rewards if empty)")
+	pflag.StringVar(&rewardPrices, "reward-prices", "", "CSV containing daily reward token prices in stable coin")
+	pflag.UintVar(&rewardDecimals, "reward-decimals", 18, "number of decimals of the reward token")
+	pflag.StringVar(&rewardSale, "reward-sale", position.SellImmediate, "when to sell reward tokens (immediate, periodic, hold)")
+	pflag.DurationVar(&rewardSaleInterval, "reward-sale-interval", 24*time.Hour, "interval between reward token sales for periodic sales")
+	pflag.StringVar(&flagRewardSwapRate, "reward-swap-rate", "0.003", "fee rate for reward token swap")
+
+	pflag.StringVar(&perpFunding, "perp-funding", "", "CSV containing hourly perpetual funding rates (disables perp hedge if empty)")
+	pflag.StringVar(&flagPerpLeverage, "perp-leverage", "2", "leverage of the short perpetual position of the perp hedge")
+	pflag.StringVar(&flagPerpFeeRate, "perp-fee-rate", "0.0005", "fee rate for trading perpetual futures")
+	pflag.StringVar(&flagPerpMaintenance, "perp-maintenance", "0.05", "maintenance margin ratio of the perpetual position")
+
+	pflag.StringVar(&optionKind, "option-kind", "", "kind of options bought to protect a liquidity position (put, straddle; disables options hedge if empty)")
+	pflag.DurationVar(&optionTenor, "option-tenor", 7*24*time.Hour, "time to expiry of options, after which they are rolled")
+	pflag.StringVar(&flagOptionStrike, "option-strike", "1", "strike price of options as ratio of the spot price")
+	pflag.StringVar(&optionVolatility, "option-volatility", "", "CSV containing implied volatility (uses realized volatility if empty)")
+	pflag.DurationVar(&optionWindow, "option-window", 30*24*time.Hour, "window over which realized volatility is estimated")
+	pflag.Float64Var(&flagOptionFallback, "option-fallback", 0.8, "volatility to use until realized volatility can be estimated")
+	pflag.StringVar(&flagOptionFeeRate, "option-fee-rate", "0.0003", "fee rate for trading options")
+
+	pflag.StringVar(&syntheticModel, "synthetic-model", "gbm", "price model of the synthetic source (gbm, jump, regime, replay)")
+	pflag.DurationVar(&syntheticStep, "synthetic-step", time.Hour, "interval between synthetic records")
+	pflag.Int64Var(&syntheticSeed, "synthetic-seed", 1, "seed for the random synthetic price paths")
+	pflag.Float64Var(&syntheticPrice, "synthetic-price", 1500, "initial price of the volatile token in stable coin for the synthetic source")
+	pflag.Float64Var(&syntheticLiquidity, "synthetic-liquidity", 100_000_000, "initial stable coin reserve of the pair for the synthetic source")
+	pflag.Float64Var(&syntheticDrift, "synthetic-drift", 0, "annualized drift of the gbm and jump price models")
+	pflag.Float64Var(&syntheticVolatility, "synthetic-volatility", 0.8, "annualized volatility of the gbm and jump price models")
+	pflag.Float64Var(&syntheticJumpIntensity, "synthetic-jump-intensity", 4, "expected number of jumps per year of the jump price model")
+	pflag.Float64Var(&syntheticJumpMean, "synthetic-jump-mean", -0.1, "mean log size of jumps of the jump price model")
+	pflag.Float64Var(&syntheticJumpVolatility, "synthetic-jump-volatility", 0.1, "standard deviation of the log size of jumps of the jump price model")
+	pflag.StringSliceVar(&syntheticRegimes, "synthetic-regimes", []string{"0.5:0.6:2160h", "-2:1.5:168h"}, "regimes of the regime price model as drift:volatility:duration")
+	pflag.Float64Var(&syntheticScale, "synthetic-scale", 1, "multiplier for the historical returns of the replay price model")
+	pflag.Float64Var(&syntheticTurnover, "synthetic-turnover", 0.1, "fraction of the synthetic pair value traded per day")
+	pflag.Float64Var(&syntheticSensitivity, "synthetic-sensitivity", 1, "fraction of the synthetic pair value traded per unit of absolute log return")
 
 	pflag.StringVarP(&influxAPI, "influx-api", "i", "https://eu-central-1-1.aws.cloud2.influxdata.com", "InfluxDB API URL")
 	pflag.StringVarP(&influxToken, "influx-token", "t", "", "InfluxDB authentication token")
 	pflag.StringVarP(&influxOrg, "influx-org", "o", "optakt", "InfluxDB organization name")
 	pflag.StringVar(&influxBucketMetrics, "influx-bucket-metrics", "metrics", "InfluxDB bucket name for Uniswap metrics")
 	pflag.StringVar(&influxBucketStrategies, "influx-bucket-strategies", "strategies", "InfluxDB bucket for position values")
-
-	pflag.Float64Var(&flagSwapRate, "swap-rate", 0.003, "fee rate for asset swap")
-	pflag.Float64Var(&flagFlashRate, "flash-rate", 0.0009, "fee rate for flash loan")
-	pflag.Float64Var(&flagLoanRate, "lend-rate", 0.005, "interest rate for lending asset")
-	pflag.Float64Var(&flagBorrowRate, "borrow-rate", 0.025, "interest rate for borrowing asset")
+	pflag.StringVar(&sourceName, "source", "influx", "source of the records (influx, synthetic)")
+	pflag.StringVar(&archiveFile, "archive-file", "", "JSON file to store the outputs of the run in for reports (disabled if empty)")
+	pflag.DurationVar(&archiveStep, "archive-step", 0, "minimum interval between points stored in the archive")
+	pflag.StringVar(&summaryJSON, "summary-json", "", "JSON file for the summary of the run, with parameters, data fingerprint and final metrics (disabled if empty)")
+	pflag.StringVar(&summaryMarkdown, "summary-markdown", "", "Markdown file for the summary of the run (disabled if empty)")
+	pflag.StringVar(&benchmark, "benchmark", "", "strategy to report all others relative to (hold, lend, token1; disabled if empty)")
+	pflag.StringVar(&dataPolicy, "data-policy", source.PolicyAbort, "policy for invalid records (skip, carry, abort)")
+	pflag.DurationVar(&resampleStep, "resample-step", 0, "step to which records are aggregated, such as 1m, 1h or 24h (disabled if zero)")
+	pflag.BoolVar(&resampleFill, "resample-fill", false, "whether to fill steps without records with the previous reserves when resampling")
+
+	pflag.StringVar(&flagSwapRate, "swap-rate", "0.003", "fee rate for asset swap")
+	pflag.StringVar(&flagFlashRate, "flash-rate", "0.0009", "fee rate for flash loan")
+	pflag.StringVar(&flagLoanRate, "lend-rate", "0.005", "interest rate for lending asset")
+	pflag.StringVar(&flagBorrowRate, "borrow-rate", "0.025", "interest rate for borrowing asset")
+
+	pflag.StringVar(&lendModel, "lend-model", "constant", "interest rate model for lending asset (constant, aave)")
+	pflag.StringVar(&lendUtilization, "lend-utilization", "", "CSV containing lending market utilization for the aave interest rate model")
+	pflag.StringVar(&flagLendBase, "lend-base", "0", "base borrow rate of the aave interest rate model")
+	pflag.StringVar(&flagLendSlope1, "lend-slope1", "0.04", "borrow rate slope below optimal utilization of the aave interest rate model")
+	pflag.StringVar(&flagLendSlope2, "lend-slope2", "0.6", "borrow rate slope above optimal utilization of the aave interest rate model")
+	pflag.StringVar(&flagLendOptimal, "lend-optimal", "0.9", "optimal utilization of the aave interest rate model")
+	pflag.StringVar(&flagLendReserveFactor, "lend-reserve-factor", "0.1", "reserve factor of the aave interest rate model")
 
 	pflag.Uint64Var(&flagTransferGas, "transfer-gas", 65601, "gas cost for token transfer")
 	pflag.Uint64Var(&flagApproveGas, "approve-gas", 24102, "gas cost for transfer approval")
@@ -111,25 +314,139 @@ func main() {
 	pflag.Uint64Var(&flagLendGas, "lend-gas", 217479, "gas cost for lending asset")
 	pflag.Uint64Var(&flagClaimGas, "claim-gas", 333793, "gas cost to claim back loan")
 
+	pflag.Uint64Var(&flagHarvestGas, "harvest-gas", 180000, "gas cost for harvesting accrued fees")
+
+	pflag.Uint64Var(&flagPerpGas, "perp-gas", 250000, "gas cost for opening or adjusting a perpetual position")
+	pflag.Uint64Var(&flagOptionGas, "option-gas", 200000, "gas cost for buying or settling options")
+
 	pflag.Uint64Var(&flagBorrowGas, "borrow-gas", 295250, "gas cost for borrowing asset")
 	pflag.Uint64Var(&flagDecreaseGas, "unborrow-gas", 193729, "gas cost for reducing debt")
 	pflag.Uint64Var(&flagIncreaseGas, "increase-gas", 271980, "gas cost for increasing debt")
 	pflag.Uint64Var(&flagRepayGas, "repay-gas", 188929, "gas cost to repay full debt")
 
-	pflag.Parse()
+	_ = pflag.CommandLine.Parse(args)
 
-	log := zerolog.New(os.Stdout)
+	log := zerolog.New(output)
 	level, err := zerolog.ParseLevel(logLevel)
 	if err != nil {
 		log.Fatal().Err(err).Str("log_level", logLevel).Msg("invalid log level")
 	}
 	log = log.Level(level)
 
+	// Summaries are compared between runs, so their time range can't default
+	// to one that moves with the time of the run.
+	if summaryJSON != "" || summaryMarkdown != "" {
+		if !pflag.CommandLine.Changed("start-time") || !pflag.CommandLine.Changed("end-time") {
+			log.Fatal().Msg("summaries require explicit start and end times")
+		}
+	}
+
+	exitTimes := make([]time.Time, 0, len(flagExitTimes))
+	for _, flagExitTime := range flagExitTimes {
+		exitTime, err := time.Parse(time.RFC3339, flagExitTime)
+		if err != nil {
+			log.Fatal().Err(err).Str("exit_time", flagExitTime).Msg("invalid exit time")
+		}
+		exitTimes = append(exitTimes, exitTime)
+	}
+	sort.Slice(exitTimes, func(i int, j int) bool {
+		return exitTimes[i].Before(exitTimes[j])
+	})
+
+	switch benchmark {
+	case "", "hold", "lend", "token1":
+	default:
+		log.Fatal().Str("benchmark", benchmark).Msg("invalid benchmark (hold, lend, token1)")
+	}
+
+	registry, err := token.NewRegistry(tokenFiles...)
+	if err != nil {
+		log.Fatal().Err(err).Strs("token_files", tokenFiles).Msg("could not create token registry")
+	}
+	pair, err := registry.Pair(chainName, pairName)
+	if err != nil {
+		log.Fatal().Err(err).Str("chain_name", chainName).Str("pair_name", pairName).Msg("could not find pair")
+	}
+
+	// Internally, token0 is always the stable token and token1 the volatile
+	// one, so values of pairs where the stable token is token1 are flipped
+	// when reading them from the records.
+	token0 := pair.Stable()
+	token1 := pair.Volatile()
+
+	// Amounts of both tokens are kept with their decimals, and all arithmetic
+	// on them is checked. It only fails on mismatched decimals or overflows,
+	// which are bugs in the backtest rather than conditions to recover from.
+	checked := func(amount b.Amount, err error) b.Amount {
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not compute amount")
+		}
+		return amount
+	}
+	zero0 := token0.Amount(big.NewInt(0))
+	zero1 := token1.Amount(big.NewInt(0))
+	unit1 := token1.Amount(token1.Unit())
+
+	// Gas is paid in the native token of the chain. If it is the volatile
+	// token of the pair, gas costs are converted to the stable token with the
+	// price of the pair; otherwise, with the given price of the native token.
+	native, err := registry.Native(chainName)
+	if err != nil {
+		log.Fatal().Err(err).Str("chain_name", chainName).Msg("could not find native token")
+	}
+	if !token1.Native && nativePrice <= 0 {
+		log.Fatal().Str("pair_name", pairName).Str("token1", token1.Symbol).Str("native", native.Symbol).Msg("native price required for pair whose volatile token is not native")
+	}
+	nativePrice0 := token0.Int(nativePrice)
+	native0 := func(amount *big.Int, reserve0 b.Amount, reserve1 b.Amount) b.Amount {
+		if token1.Native {
+			return checked(util.Quote(token1.Amount(amount), reserve1, reserve0))
+		}
+		amount0 := big.NewInt(0).Mul(amount, nativePrice0)
+		return token0.Amount(amount0.Div(amount0, native.Unit()))
+	}
+
 	station, err := station.New(gasPrices)
 	if err != nil {
 		log.Fatal().Err(err).Str("gas_prices", gasPrices).Msg("could not create gas station")
 	}
 
+	var (
+		schedule *reward.Schedule
+		feed     *reward.Feed
+	)
+	if rewardSchedule != "" {
+		schedule, err = reward.NewSchedule(rewardSchedule)
+		if err != nil {
+			log.Fatal().Err(err).Str("reward_schedule", rewardSchedule).Msg("could not create reward schedule")
+		}
+		feed, err = reward.NewFeed(rewardPrices, token0.Decimals)
+		if err != nil {
+			log.Fatal().Err(err).Str("reward_prices", rewardPrices).Msg("could not create reward price feed")
+		}
+	}
+
+	var fund *funding.Funding
+	if perpFunding != "" {
+		fund, err = funding.New(perpFunding)
+		if err != nil {
+			log.Fatal().Err(err).Str("perp_funding", perpFunding).Msg("could not create funding rates")
+		}
+	}
+
+	var estimator option.Estimator
+	switch {
+	case optionKind != "" && optionKind != option.Put && optionKind != option.Straddle:
+		log.Fatal().Str("option_kind", optionKind).Msg("invalid option kind")
+	case optionVolatility != "":
+		estimator, err = option.NewImplied(optionVolatility)
+		if err != nil {
+			log.Fatal().Err(err).Str("option_volatility", optionVolatility).Msg("could not create implied volatility")
+		}
+	default:
+		estimator = option.NewRealized(optionWindow, flagOptionFallback)
+	}
+
 	client := influxdb2.NewClientWithOptions(influxAPI, influxToken,
 		influxdb2.DefaultOptions().SetHTTPRequestTimeout(uint(15*time.Minute)),
 	)
@@ -141,34 +458,113 @@ func main() {
 		}
 	}()
 
-	inbound := client.QueryAPI(influxOrg)
-	query := fmt.Sprintf(statement, startTime, endTime, chainName, pairName)
-	result, err := inbound.Query(context.Background(), query)
-	if err != nil {
-		log.Fatal().Err(err).Msg("could not execute query")
+	// Convert the USD value given as input into an amount of token0.
+	input := big.NewInt(0).SetUint64(inputValue)
+	input0 := token0.Amount(input.Mul(input, token0.Unit())) // we want to operate at the most granular level
+
+	// Convert the harvest threshold into an amount of token0.
+	harvestThreshold0 := token0.Amount(token0.Int(flagHarvestThreshold))
+
+	// Rates and ratios are parsed from their exact decimal representation, so
+	// that no precision is lost to floats, and checked against sane ranges,
+	// given in basis points.
+	parseBps := func(flag string, value string, min int64, max int64) b.Bps {
+		ratio, err := b.ParseBps(value)
+		if err != nil {
+			log.Fatal().Err(err).Str(flag, value).Msg("could not parse ratio")
+		}
+		if ratio.Cmp(b.NewBps(big.NewInt(min))) < 0 || ratio.Cmp(b.NewBps(big.NewInt(max))) > 0 {
+			log.Fatal().Str(flag, value).Float64("min", float64(min)/10_000).Float64("max", float64(max)/10_000).Msg("ratio out of range")
+		}
+		return ratio
 	}
-	if !result.Next() {
-		log.Fatal().Msg("no records found")
+	parseMultiple := func(flag string, value string, min int64, max int64) b.Multiple {
+		multiple, err := b.ParseMultiple(value)
+		if err != nil {
+			log.Fatal().Err(err).Str(flag, value).Msg("could not parse multiple")
+		}
+		if multiple.Cmp(b.NewMultiple(big.NewInt(min))) < 0 || multiple.Cmp(b.NewMultiple(big.NewInt(max))) > 0 {
+			log.Fatal().Str(flag, value).Float64("min", float64(min)/10_000).Float64("max", float64(max)/10_000).Msg("multiple out of range")
+		}
+		return multiple
 	}
-	err = result.Err()
-	if err != nil {
-		log.Fatal().Err(result.Err()).Msg("could not stream first record")
+	parseRay := func(flag string, value string, min int64, max int64) b.Ray {
+		rate, err := b.ParseRay(value)
+		if err != nil {
+			log.Fatal().Err(err).Str(flag, value).Msg("could not parse rate")
+		}
+		if rate.Cmp(b.NewBps(big.NewInt(min)).Ray()) < 0 || rate.Cmp(b.NewBps(big.NewInt(max)).Ray()) > 0 {
+			log.Fatal().Str(flag, value).Float64("min", float64(min)/10_000).Float64("max", float64(max)/10_000).Msg("rate out of range")
+		}
+		return rate
+	}
+
+	rebalanceDrift := parseBps("rebalance_drift", flagRebalanceDrift, 0, 9_999)
+
+	// The asymmetric policy has its own bounds below and above the target,
+	// while all other policies use the hedge ratio on both sides.
+	rehedgeRatio := parseBps("rehedge_ratio", flagRehedgeRatio, 1, 9_999)
+	rehedgeLower := rehedgeRatio
+	rehedgeUpper := rehedgeRatio
+	if rehedgePolicy == position.TriggerAsymmetric {
+		rehedgeLower = parseBps("rehedge_lower", flagRehedgeLower, 1, 9_999)
+		rehedgeUpper = parseBps("rehedge_upper", flagRehedgeUpper, 1, 9_999)
 	}
+	rehedgeInner := parseBps("rehedge_inner", flagRehedgeInner, 0, 9_999)
 
-	// Convert the USD value given as input into a big integer.
-	input0 := big.NewInt(0).SetUint64(inputValue)
-	input0.Mul(input0, b.E6) // USDC has 6 decimals, we want to operate at the most granular level
+	swapRate := parseBps("swap_rate", flagSwapRate, 0, 9_999)
+	rewardSwapRate := parseBps("reward_swap_rate", flagRewardSwapRate, 0, 9_999)
 
-	// Convert the hedge ratio to big integer.
-	rehedgeRatio := big.NewInt(int64(flagRehedgeRatio * 1_000))
+	var sandwich *mev.Sandwich
+	mevTolerance := parseBps("mev_tolerance", flagMEVTolerance, 0, 9_999)
+	if mevTolerance.Sign() != 0 {
+		sandwich, err = mev.NewSandwich(mevTolerance, swapRate)
+		if err != nil {
+			log.Fatal().Err(err).Str("mev_tolerance", flagMEVTolerance).Msg("could not create sandwich model")
+		}
+		log.Warn().Msg("sandwich attacks are not charged on the exit swaps of liquidity positions or on reward sales")
+	}
 
-	// We keep track of the flash rate as 1/1000 units
-	swapRate := big.NewInt(int64(flagSwapRate * 1_000))
+	// We keep track of the fixed keeper fee in the stable coin.
+	keeperFixed0 := token0.Int(flagKeeperFixed)
+	keeperMultiple := parseMultiple("keeper_fee_multiple", flagKeeperMultiple, 0, 1_000_000)
+	keeperRate := parseBps("keeper_fee_rate", flagKeeperRate, 0, 9_999)
+
+	perpLeverage := parseMultiple("perp_leverage", flagPerpLeverage, 1, 1_000_000)
+	perpMaintenance := parseBps("perp_maintenance", flagPerpMaintenance, 1, 9_999)
+	perpFeeRate := parseBps("perp_fee_rate", flagPerpFeeRate, 0, 9_999)
+
+	optionStrike := parseMultiple("option_strike", flagOptionStrike, 1, 100_000)
+	optionFeeRate := parseBps("option_fee_rate", flagOptionFeeRate, 0, 9_999)
+
+	flashRate := parseRay("flash_rate", flagFlashRate, 0, 9_999)
+	loanRate := parseRay("lend_rate", flagLoanRate, 0, 100_000)
+	borrowRate := parseRay("borrow_rate", flagBorrowRate, 0, 100_000)
+
+	// The lend rate is either constant, or derived from the utilization of
+	// the lending market with the Aave interest rate model.
+	var model interest.Model
+	switch lendModel {
+
+	case "constant":
+		model = interest.NewConstant(loanRate)
+
+	case "aave":
+		params := interest.Parameters{
+			Base:          parseRay("lend_base", flagLendBase, 0, 100_000),
+			Slope1:        parseRay("lend_slope1", flagLendSlope1, 0, 100_000),
+			Slope2:        parseRay("lend_slope2", flagLendSlope2, 0, 100_000),
+			Optimal:       parseRay("lend_optimal", flagLendOptimal, 1, 9_999),
+			ReserveFactor: parseRay("lend_reserve_factor", flagLendReserveFactor, 0, 9_999),
+		}
+		model, err = interest.NewAave(lendUtilization, params)
+		if err != nil {
+			log.Fatal().Err(err).Str("lend_utilization", lendUtilization).Msg("could not create aave interest rate model")
+		}
 
-	// We keep rate of loan interest rates as 1/10^27 units (Ray)
-	flashRate := big.NewInt(0).Mul(big.NewInt(int64(flagFlashRate*10_000)), b.E23)
-	loanRate := big.NewInt(0).Mul(big.NewInt(int64(flagLoanRate*10_000)), b.E23)
-	borrowRate := big.NewInt(0).Mul(big.NewInt(int64(flagBorrowRate*10_000)), b.E23)
+	default:
+		log.Fatal().Str("lend_model", lendModel).Msg("invalid lend model")
+	}
 
 	// Convert the gas costs into big integers.
 	approveGas := big.NewInt(0).SetUint64(flagApproveGas) // approve ERC20 transfer
@@ -178,48 +574,210 @@ func main() {
 	createGas := big.NewInt(0).SetUint64(flagCreateGas) // create liquidity position on Uniswap v2
 	addGas := big.NewInt(0).SetUint64(flagAddGas)       // add liquidity on Uniswap v2
 	removeGas := big.NewInt(0).SetUint64(flagRemoveGas) // remove liquidity on Uniswap v2
-	// closeCas := big.NewInt(0).SetUint64(flagCloseGas)   // close liquidity position on Uniswap v2
+	closeGas := big.NewInt(0).SetUint64(flagCloseGas)   // close liquidity position on Uniswap v2
 
-	lendGas := big.NewInt(0).SetUint64(flagLendGas) // lend asset on Aave
-	// claimGas := big.NewInt(0).SetUint64(flagClaimGas) // claim loan plus yield on Aave
+	lendGas := big.NewInt(0).SetUint64(flagLendGas)   // lend asset on Aave
+	claimGas := big.NewInt(0).SetUint64(flagClaimGas) // claim loan plus yield on Aave
 
 	borrowGas := big.NewInt(0).SetUint64(flagBorrowGas)     // borrow asset on Aave
 	increaseGas := big.NewInt(0).SetUint64(flagIncreaseGas) // increase debt on Aaave
 	decreaseGas := big.NewInt(0).SetUint64(flagDecreaseGas) // decrease debt on Aave
-	// repayGas := big.NewInt(0).SetUint64(flagRepayGas)       // repoy loan on Aave
+	repayGas := big.NewInt(0).SetUint64(flagRepayGas)       // repay loan on Aave
+
+	harvestGas := big.NewInt(0).SetUint64(flagHarvestGas) // harvest accrued fees from a farm
+
+	perpGas := big.NewInt(0).SetUint64(flagPerpGas)     // open or adjust perpetual position
+	optionGas := big.NewInt(0).SetUint64(flagOptionGas) // buy or settle options
+
+	// Claiming reward tokens is a harvest, followed by a swap to the stable coin.
+	saleGas := big.NewInt(0).Add(harvestGas, approveGas)
+	saleGas.Add(saleGas, swapGas)
+
+	// Harvesting the fees and adding them as liquidity happen together.
+	compoundGas := big.NewInt(0).Add(harvestGas, addGas)
+
+	// Sum up the gas costs for unwinding each position into the stable coin.
+	exitHoldGas := big.NewInt(0).Add(approveGas, swapGas)
+
+	exitUniGas := big.NewInt(0).Add(closeGas, exitHoldGas)
+
+	exitAutoGas := big.NewInt(0).Add(closeGas, repayGas)
+	exitAutoGas.Add(exitAutoGas, claimGas)
+	exitAutoGas.Add(exitAutoGas, exitHoldGas)
+
+	exitPerpGas := big.NewInt(0).Add(exitUniGas, perpGas)
+
+	exitOptionGas := big.NewInt(0).Add(exitUniGas, optionGas)
+
+	// Records either come from the metrics collected into InfluxDB, or are
+	// generated from a price model, which can also replay the collected ones.
+	inbound := client.QueryAPI(influxOrg)
+	var metrics source.Source
+	switch sourceName {
+
+	case "influx":
+		metrics, err = source.NewInflux(inbound, influxBucketMetrics, startTime, endTime, chainName, pairName)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not initialize metrics source")
+		}
+
+	case "synthetic":
+		var model synthetic.Model
+		switch syntheticModel {
+		case "gbm":
+			model, err = synthetic.NewGBM(syntheticDrift, syntheticVolatility)
+		case "jump":
+			model, err = synthetic.NewJump(syntheticDrift, syntheticVolatility, syntheticJumpIntensity, syntheticJumpMean, syntheticJumpVolatility)
+		case "regime":
+			regimes := make([]synthetic.Regime, 0, len(syntheticRegimes))
+			for _, flagRegime := range syntheticRegimes {
+				regime, err := synthetic.ParseRegime(flagRegime)
+				if err != nil {
+					log.Fatal().Err(err).Str("synthetic_regime", flagRegime).Msg("invalid synthetic regime")
+				}
+				regimes = append(regimes, regime)
+			}
+			model, err = synthetic.NewSwitching(regimes...)
+		case "replay":
+			var history source.Source
+			history, err = source.NewInflux(inbound, influxBucketMetrics, startTime, endTime, chainName, pairName)
+			if err != nil {
+				log.Fatal().Err(err).Msg("could not initialize metrics source to replay")
+			}
+			history, err = source.NewQuality(history, source.PolicySkip)
+			if err != nil {
+				log.Fatal().Err(err).Msg("could not initialize data policy to replay")
+			}
+			model, err = synthetic.NewReplay(history, pair.Flipped(), syntheticScale, syntheticStep)
+		default:
+			log.Fatal().Str("synthetic_model", syntheticModel).Msg("invalid synthetic model")
+		}
+		if err != nil {
+			log.Fatal().Err(err).Str("synthetic_model", syntheticModel).Msg("could not create synthetic model")
+		}
 
-	// Read the first record to initialize the positions.
-	record := result.Record()
-	timestamp := record.Time()
-	values := record.Values()
+		turnover, err := synthetic.NewTurnover(syntheticTurnover, syntheticSensitivity)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not create synthetic turnover")
+		}
+
+		start, err := time.Parse(time.RFC3339, startTime)
+		if err != nil {
+			log.Fatal().Err(err).Str("start_time", startTime).Msg("invalid start time")
+		}
+		end, err := time.Parse(time.RFC3339, endTime)
+		if err != nil {
+			log.Fatal().Err(err).Str("end_time", endTime).Msg("invalid end time")
+		}
+
+		// The initial reserves are given in the stable coin and converted to
+		// the token order of the pair contract.
+		stable := token0.Int(syntheticLiquidity)
+		volatile := token1.Int(syntheticLiquidity / syntheticPrice)
+		initial0, initial1 := pair.Orient(stable, volatile)
 
-	// The values from InfluxDB come as hex-encoded strings for now, so convert
-	// them back to the original big integers read from the contracts.
-	// NOTE: this is because InfluxDB doesn't support number above 64 bits, and
-	// with `float64` we get too much imprecision. QuestDB supports 256-bit
-	// integers and might be the better option.
-	reserve0 := b.FromHex(values["reserve0"])
-	reserve1 := b.FromHex(values["reserve1"])
+		syntheticRand := rand.New(rand.NewSource(syntheticSeed))
+		metrics, err = synthetic.NewGenerator(start, end, syntheticStep, initial0, initial1, model, turnover, swapRate, pair.Flipped(), syntheticRand)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not create synthetic generator")
+		}
+
+	default:
+		log.Fatal().Str("source", sourceName).Msg("invalid source")
+	}
+
+	quality, err := source.NewQuality(metrics, dataPolicy)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not initialize data policy")
+	}
+	var sampled source.Source = quality
+	if resampleStep != 0 {
+		sampled, err = source.NewResample(quality, resampleStep, resampleFill)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not initialize resampling")
+		}
+	}
+	data := source.NewFingerprint(sampled)
+	if !data.Next() {
+		err = data.Err()
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not stream first record")
+		}
+		log.Fatal().Msg("no records found")
+	}
+
+	// Read the first record to initialize the positions. The data policy only
+	// lets valid snapshots through, so reading them can not fail.
+	snapshot, _ := data.Snapshot()
+	timestamp := snapshot.Timestamp
+	first := timestamp
+	reserve0, reserve1 := pair.Amounts(snapshot.Reserve0, snapshot.Reserve1)
 
 	gasPrice1, err := station.Gasprice(timestamp)
 	if err != nil {
 		log.Fatal().Err(err).Time("timestamp", timestamp).Msg("could not get gas price for timestamp")
 	}
 
-	holdDiv := big.NewInt(0).Add(b.D2000, swapRate)
+	// Swaps sent through the public mempool lose value to sandwich attacks,
+	// which we estimate and report for each swap, so that the loss can be
+	// charged to the strategy. The swap sells token0 for token1 if zeroForOne
+	// is set, and token1 for token0 otherwise.
+	extract := func(timestamp time.Time, strategy string, action string, size uint64, amountIn b.Amount, zeroForOne bool, reserve0 b.Amount, reserve1 b.Amount) b.Amount {
+
+		if sandwich == nil {
+			return token0.Amount(big.NewInt(0))
+		}
+
+		var amount0, front0, loss0 b.Amount
+		if zeroForOne {
+			front, loss1, err := sandwich.Extract(amountIn, reserve0, reserve1)
+			if err != nil {
+				log.Fatal().Err(err).Str("strategy", strategy).Msg("could not estimate sandwich attack")
+			}
+			amount0 = amountIn
+			front0 = front
+			loss0 = checked(util.Quote(loss1, reserve1, reserve0))
+		} else {
+			front, loss, err := sandwich.Extract(amountIn, reserve1, reserve0)
+			if err != nil {
+				log.Fatal().Err(err).Str("strategy", strategy).Msg("could not estimate sandwich attack")
+			}
+			amount0 = checked(util.Quote(amountIn, reserve1, reserve0))
+			front0 = checked(util.Quote(front, reserve1, reserve0))
+			loss0 = loss
+		}
+
+		if writeResults {
+			write.SandwichPoint(timestamp, strategy, action, size, amount0, front0, loss0, pair, outbound)
+		}
+
+		log.Debug().
+			Str("strategy", strategy).
+			Str("action", action).
+			Float64("amount0", amount0.Float()).
+			Float64("front0", front0.Float()).
+			Float64("loss0", loss0.Float()).
+			Msg("estimated sandwich attack on swap")
+
+		return loss0
+	}
 
-	hold0 := big.NewInt(0).Mul(input0, b.D1000)
-	hold0.Div(hold0, holdDiv)
+	// We swap the exact amount of stable coins that leaves us with the same
+	// ratio as the pair's reserves after the swap, which accounts for both the
+	// swap fee and the price impact of the swap itself.
+	swap0 := checked(util.CalculateOptimalSwap(input0, reserve0, swapRate))
 
-	hold1 := util.Quote(hold0, reserve0, reserve1)
+	hold0 := checked(input0.Sub(swap0))
+	hold1 := checked(util.CalculateAmountOut(swap0, reserve0, reserve1, swapRate))
 
-	fee0 := big.NewInt(0).Sub(input0, hold0)
-	fee0.Sub(fee0, hold0)
+	fee0 := checked(input0.Sub(hold0))
+	fee0 = checked(fee0.Sub(checked(util.Quote(hold1, reserve1, reserve0))))
 
 	costHold1 := big.NewInt(0).Add(approveGas, swapGas)
 	costHold1.Mul(costHold1, gasPrice1)
 
-	costHold0 := util.Quote(costHold1, reserve1, reserve0)
+	costHold0 := native0(costHold1, reserve0, reserve1)
+	costHold0 = checked(costHold0.Add(extract(timestamp, "hold", "entry", inputValue, swap0, true, reserve0, reserve1)))
 
 	hold := position.Hold{
 		Size:    inputValue,
@@ -230,56 +788,136 @@ func main() {
 	}
 
 	log.Debug().
-		Float64("amount0", b.ToFloat(hold.Amount0, 6)).
-		Float64("amount1", b.ToFloat(hold.Amount1, 18)).
-		Float64("fees0", b.ToFloat(hold.Fees0, 6)).
-		Float64("cost0", b.ToFloat(hold.Cost0, 6)).
+		Float64("amount0", hold.Amount0.Float()).
+		Float64("amount1", hold.Amount1.Float()).
+		Float64("fees0", hold.Fees0.Float()).
+		Float64("cost0", hold.Cost0.Float()).
 		Msg("hold position initialized")
 
-	liqUni := big.NewInt(0).Mul(hold0, hold1)
+	// The dollar-cost averaging baseline approves the pair once and buys its
+	// first tranche right away.
+	if dcaTranches == 0 {
+		log.Fatal().Msg("number of dollar-cost averaging tranches must be positive")
+	}
+
+	tranches := b.NewAmount(big.NewInt(0).SetUint64(dcaTranches), 0)
+	tranche0 := checked(input0.Div(tranches, b.RoundDown))
+
+	costDCA1 := big.NewInt(0).Mul(approveGas, gasPrice1)
+	costDCA0 := native0(costDCA1, reserve0, reserve1)
+
+	dca := position.DCA{
+		Size:     inputValue,
+		Tranche0: tranche0,
+		Amount0:  input0,
+		Amount1:  zero1,
+		Fees0:    zero0,
+		Cost0:    costDCA0,
+		Buys:     0,
+	}
+
+	buyCost1 := big.NewInt(0).Mul(swapGas, gasPrice1)
+	buyCost0 := native0(buyCost1, reserve0, reserve1)
+	dcaFirst0 := checked(dca.Next0())
+	buyCost0 = checked(buyCost0.Add(extract(timestamp, "dca", "buy", dca.Size, dcaFirst0, true, reserve0, reserve1)))
+	_, err = dca.Buy(reserve0, reserve1, swapRate, buyCost0)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not buy first dca tranche")
+	}
+	dcaNext := timestamp.Add(dcaInterval)
+
+	log.Debug().
+		Float64("amount0", dca.Amount0.Float()).
+		Float64("amount1", dca.Amount1.Float()).
+		Float64("fees0", dca.Fees0.Float()).
+		Float64("cost0", dca.Cost0.Float()).
+		Msg("dca position initialized")
+
+	// The constant-mix baseline starts out exactly like the hold position.
+	rebalance := position.Rebalance{
+		Size:    inputValue,
+		Drift:   rebalanceDrift,
+		Amount0: hold.Amount0,
+		Amount1: hold.Amount1,
+		Fees0:   hold.Fees0,
+		Cost0:   hold.Cost0,
+		Count:   0,
+	}
+	rebalanceLast := timestamp
+
+	log.Debug().
+		Float64("amount0", rebalance.Amount0.Float()).
+		Float64("amount1", rebalance.Amount1.Float()).
+		Float64("fees0", rebalance.Fees0.Float()).
+		Float64("cost0", rebalance.Cost0.Float()).
+		Msg("rebalance position initialized")
+
+	costLend1 := big.NewInt(0).Add(approveGas, lendGas)
+	costLend1.Mul(costLend1, gasPrice1)
+
+	costLend0 := native0(costLend1, reserve0, reserve1)
+
+	lend := position.Lend{
+		Size:       inputValue,
+		Rate:       model.Rate(timestamp),
+		Principal0: input0,
+		Yield0:     zero0,
+		Cost0:      costLend0,
+	}
+
+	log.Debug().
+		Float64("principal0", lend.Principal0.Float()).
+		Float64("cost0", lend.Cost0.Float()).
+		Msg("lend position initialized")
+
+	liqUni := big.NewInt(0).Mul(hold0.Int(), hold1.Int())
 	liqUni.Sqrt(liqUni)
 
-	feesUni0 := big.NewInt(0).Set(hold.Fees0)
+	feesUni0 := hold.Fees0
 
 	costUni1 := big.NewInt(0).Add(approveGas, swapGas)
 	costUni1.Add(costUni1, createGas)
 	costUni1.Mul(costUni1, gasPrice1)
 
-	costUni0 := util.Quote(costUni1, reserve1, reserve0)
+	costUni0 := native0(costUni1, reserve0, reserve1)
+	costUni0 = checked(costUni0.Add(extract(timestamp, "uniswap", "entry", inputValue, swap0, true, reserve0, reserve1)))
 
 	uniswap := position.Uniswap{
 		Size:      inputValue,
 		Liquidity: liqUni,
+		Pending0:  zero0,
+		Pending1:  zero1,
 		Fees0:     feesUni0,
 		Cost0:     costUni0,
-		Profit0:   big.NewInt(0),
+		Profit0:   zero0,
+		Rewards:   position.NewRewards(token0.Decimals, rewardDecimals),
 	}
 
 	log.Debug().
-		Float64("liquidity", b.ToFloat(liqUni, 12)).
-		Float64("amount0", b.ToFloat(hold0, 6)).
-		Float64("amount1", b.ToFloat(hold1, 18)).
-		Float64("fees0", b.ToFloat(uniswap.Fees0, 6)).
-		Float64("cost0", b.ToFloat(uniswap.Cost0, 6)).
+		Float64("liquidity", pair.Liquidity(liqUni)).
+		Float64("amount0", hold0.Float()).
+		Float64("amount1", hold1.Float()).
+		Float64("fees0", uniswap.Fees0.Float()).
+		Float64("cost0", uniswap.Cost0.Float()).
 		Msg("uniswap position initialized")
 
-	autoDivA := big.NewInt(0).Mul(flashRate, swapRate) // 0.003 * 0.0009
-	autoDivB := big.NewInt(0).Mul(flashRate, b.E3)     // 0.0009
+	autoDivA := flashRate.Mul(swapRate.Ray()) // 0.003 * 0.0009
+	autoDivB := flashRate                     // 0.0009
 
-	autoDiv := big.NewInt(0).Add(autoDivA, autoDivB) // 0.0009 + 0.003 * 0.0009
-	autoDiv.Add(autoDiv, b.E30)                      // 1 + 0.0009 + 0.003 * 0.0009
+	autoDiv := autoDivA.Add(autoDivB)      // 0.0009 + 0.003 * 0.0009
+	autoDiv = autoDiv.Add(b.NewRay(b.E27)) // 1 + 0.0009 + 0.003 * 0.0009
 
-	auto0 := big.NewInt(0).Mul(input0, b.E30)
-	auto0.Div(auto0, autoDiv)
+	auto0 := input0.Rescale(input0.Decimals()+27, b.RoundDown)
+	auto0 = checked(auto0.Div(b.NewAmount(autoDiv.Int(), 27), b.RoundDown))
 
-	auto1 := util.Quote(auto0, reserve0, reserve1)
+	auto1 := checked(util.Quote(auto0, reserve0, reserve1))
 
-	liqAuto := big.NewInt(0).Mul(auto0, auto1)
+	liqAuto := big.NewInt(0).Mul(auto0.Int(), auto1.Int())
 	liqAuto.Sqrt(liqAuto)
 
-	principal0 := big.NewInt(0).Add(auto0, auto0)
+	principal0 := checked(auto0.Add(auto0))
 
-	autoFee0 := big.NewInt(0).Sub(input0, auto0)
+	autoFee0 := checked(input0.Sub(auto0))
 
 	costAuto1 := big.NewInt(0).Add(flashGas, createGas)
 	costAuto1.Add(costAuto1, approveGas)
@@ -289,284 +927,1332 @@ func main() {
 	costAuto1.Add(costAuto1, swapGas)
 	costAuto1.Mul(costAuto1, gasPrice1)
 
-	costAuto0 := util.Quote(costAuto1, reserve1, reserve0)
+	// The flash loan fee is paid in token1, which we buy with the stable coin,
+	// so that this is the only swap of the entry.
+	costAuto0 := native0(costAuto1, reserve0, reserve1)
+	costAuto0 = checked(costAuto0.Add(extract(timestamp, "autohedge", "entry", inputValue, autoFee0, true, reserve0, reserve1)))
 
 	autohedge := position.Autohedge{
 		Size:       inputValue,
 		Rehedge:    rehedgeRatio,
 		Liquidity:  liqAuto,
+		Pending0:   zero0,
+		Pending1:   zero1,
 		Principal0: principal0,
 		Debt1:      auto1,
 		Fees0:      autoFee0,
 		Cost0:      costAuto0,
-		Yield0:     big.NewInt(0),
-		Interest1:  big.NewInt(0),
-		Profit0:    big.NewInt(0),
+		Yield0:     zero0,
+		Interest1:  zero1,
+		Profit0:    zero0,
+		Rewards:    position.NewRewards(token0.Decimals, rewardDecimals),
 		Count:      0,
 	}
 
 	log.Debug().
-		Float64("liquidity", b.ToFloat(liqAuto, 12)).
-		Float64("amount0", b.ToFloat(auto0, 6)).
-		Float64("amount1", b.ToFloat(auto1, 18)).
-		Float64("principal0", b.ToFloat(autohedge.Principal0, 6)).
-		Float64("debt1", b.ToFloat(autohedge.Debt1, 18)).
-		Float64("fees0", b.ToFloat(autohedge.Fees0, 6)).
-		Float64("cost0", b.ToFloat(autohedge.Cost0, 6)).
+		Float64("liquidity", pair.Liquidity(liqAuto)).
+		Float64("amount0", auto0.Float()).
+		Float64("amount1", auto1.Float()).
+		Float64("principal0", autohedge.Principal0.Float()).
+		Float64("debt1", autohedge.Debt1.Float()).
+		Float64("fees0", autohedge.Fees0.Float()).
+		Float64("cost0", autohedge.Cost0.Float()).
 		Msg("autohedge position initialized")
 
+	// The perp hedge splits the input between the liquidity position and the
+	// margin for a short perpetual position, sized to the token1 leg, so that
+	// the short is opened at the configured leverage.
+	var perpHedge *position.PerpHedge
+	if fund != nil {
+
+		perpMul := big.NewInt(0).Mul(perpLeverage.Int(), b.D2)
+		perpDiv := big.NewInt(0).Add(perpMul, b.E4)
+
+		perpInput0 := checked(input0.Mul(b.NewAmount(perpMul, 0)))
+		perpInput0 = checked(perpInput0.Div(b.NewAmount(perpDiv, 0), b.RoundDown))
+
+		margin0 := checked(input0.Sub(perpInput0))
+
+		perpSwap0 := checked(util.CalculateOptimalSwap(perpInput0, reserve0, swapRate))
+
+		perp0 := checked(perpInput0.Sub(perpSwap0))
+		perp1 := checked(util.CalculateAmountOut(perpSwap0, reserve0, reserve1, swapRate))
+
+		liqPerp := big.NewInt(0).Mul(perp0.Int(), perp1.Int())
+		liqPerp.Sqrt(liqPerp)
+
+		feesPerp0 := checked(perpInput0.Sub(perp0))
+		feesPerp0 = checked(feesPerp0.Sub(checked(util.Quote(perp1, reserve1, reserve0))))
+
+		costPerp1 := big.NewInt(0).Add(approveGas, swapGas)
+		costPerp1.Add(costPerp1, createGas)
+		costPerp1.Add(costPerp1, approveGas)
+		costPerp1.Add(costPerp1, perpGas)
+		costPerp1.Mul(costPerp1, gasPrice1)
+
+		costPerp0 := native0(costPerp1, reserve0, reserve1)
+		costPerp0 = checked(costPerp0.Add(extract(timestamp, "perphedge", "entry", inputValue, perpSwap0, true, reserve0, reserve1)))
+
+		perpHedge = &position.PerpHedge{
+			Size:      inputValue,
+			Rehedge:   rehedgeRatio,
+			Liquidity: liqPerp,
+			Pending0:  zero0,
+			Pending1:  zero1,
+			Short1:    zero1,
+			Entry0:    zero0,
+			Margin0:   margin0,
+			Funding0:  zero0,
+			Fees0:     feesPerp0,
+			Cost0:     costPerp0,
+			Profit0:   zero0,
+			Rewards:   position.NewRewards(token0.Decimals, rewardDecimals),
+		}
+		_, err = perpHedge.Adjust(perp1, perpFeeRate, reserve0, reserve1)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not open perp hedge short")
+		}
+
+		log.Debug().
+			Float64("liquidity", pair.Liquidity(liqPerp)).
+			Float64("amount0", perp0.Float()).
+			Float64("amount1", perp1.Float()).
+			Float64("short1", perpHedge.Short1.Float()).
+			Float64("margin0", perpHedge.Margin0.Float()).
+			Float64("fees0", perpHedge.Fees0.Float()).
+			Float64("cost0", perpHedge.Cost0.Float()).
+			Msg("perp hedge position initialized")
+	}
+
+	// The options hedge enters the same liquidity position as the uniswap
+	// strategy and buys options on its token1 leg, which are rolled at expiry.
+	var options *position.Options
+	if optionKind != "" {
+
+		costOptions1 := big.NewInt(0).Add(approveGas, swapGas)
+		costOptions1.Add(costOptions1, createGas)
+		costOptions1.Add(costOptions1, optionGas)
+		costOptions1.Mul(costOptions1, gasPrice1)
+
+		costOptions0 := native0(costOptions1, reserve0, reserve1)
+		costOptions0 = checked(costOptions0.Add(extract(timestamp, "options", "entry", inputValue, swap0, true, reserve0, reserve1)))
+
+		options = &position.Options{
+			Size:       inputValue,
+			Kind:       optionKind,
+			Unit1:      unit1,
+			Liquidity:  big.NewInt(0).Set(liqUni),
+			Pending0:   zero0,
+			Pending1:   zero1,
+			Contracts1: zero1,
+			Strike0:    zero0,
+			Expiry:     timestamp,
+			Mark0:      zero0,
+			Premium0:   zero0,
+			Payoff0:    zero0,
+			Fees0:      feesUni0,
+			Cost0:      costOptions0,
+			Profit0:    zero0,
+			Rewards:    position.NewRewards(token0.Decimals, rewardDecimals),
+		}
+	}
+
+	// Options are priced with Black-Scholes, using the pair's price as spot
+	// price and the lend rate as risk-free rate.
+	optionPrice := func(timestamp time.Time, strike0 b.Amount, expiry time.Time, reserve0 b.Amount, reserve1 b.Amount) b.Amount {
+
+		spot := checked(util.Quote(unit1, reserve1, reserve0)).Float()
+		strike := strike0.Float()
+		years := expiry.Sub(timestamp).Hours() / float64(b.HPY.Int64())
+		volatility := estimator.Volatility(timestamp)
+
+		premium := option.Premium(optionKind, spot, strike, years, volatility, loanRate.Float())
+
+		return token0.Amount(token0.Int(premium))
+	}
+
+	// Rolling buys new options covering the current token1 leg of the position.
+	rollOptions := func(timestamp time.Time, reserve0 b.Amount, reserve1 b.Amount) {
+
+		strike0 := checked(util.Quote(unit1, reserve1, reserve0))
+		strike0 = checked(strike0.MulMultiple(optionStrike, b.RoundDown))
+
+		expiry := timestamp.Add(optionTenor)
+		price0 := optionPrice(timestamp, strike0, expiry, reserve0, reserve1)
+
+		contracts0 := big.NewInt(0).Mul(options.Liquidity, big.NewInt(0).Sqrt(reserve0.Int()))
+		contracts0.Div(contracts0, big.NewInt(0).Sqrt(reserve1.Int()))
+		contracts1 := checked(util.Quote(token0.Amount(contracts0), reserve0, reserve1))
+
+		err := options.Buy(contracts1, strike0, expiry, price0, optionFeeRate, reserve0, reserve1)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not buy options")
+		}
+
+		log.Debug().
+			Time("timestamp", timestamp).
+			Float64("contracts1", options.Contracts1.Float()).
+			Float64("strike0", options.Strike0.Float()).
+			Float64("price0", price0.Float()).
+			Float64("premium0", options.Premium0.Float()).
+			Float64("fees0", options.Fees0.Float()).
+			Time("expiry", options.Expiry).
+			Uint("rolls", options.Rolls).
+			Msg("bought options for options hedge position")
+	}
+
+	// The volatility estimator is used for option pricing and for some of
+	// the rehedge trigger policies.
+	estimator.Observe(timestamp, checked(util.Quote(unit1, reserve1, reserve0)).Float())
+
+	if options != nil {
+		rollOptions(timestamp, reserve0, reserve1)
+	}
+
+	// Each hedged position keeps track of its own rehedges.
+	autoTrigger, err := position.NewTrigger(rehedgePolicy, rehedgeLower, rehedgeUpper, rehedgeInner, rehedgeInterval, rehedgeReference, timestamp)
+	if err != nil {
+		log.Fatal().Err(err).Str("rehedge_policy", rehedgePolicy).Msg("could not create autohedge trigger")
+	}
+	perpTrigger, err := position.NewTrigger(rehedgePolicy, rehedgeLower, rehedgeUpper, rehedgeInner, rehedgeInterval, rehedgeReference, timestamp)
+	if err != nil {
+		log.Fatal().Err(err).Str("rehedge_policy", rehedgePolicy).Msg("could not create perp hedge trigger")
+	}
+
+	// Each hedged position has its rehedges executed by its own keeper, which
+	// draws its failures from its own generator with the same seed, so that
+	// enabling one hedge doesn't change the failures of the other.
+	autoKeeper, err := keeper.New(keeperDelay, keeperRecords, keeperFee, keeperFixed0, keeperMultiple, keeperRate, keeperFailure, rand.New(rand.NewSource(keeperSeed)))
+	if err != nil {
+		log.Fatal().Err(err).Str("keeper_fee", keeperFee).Msg("could not create autohedge keeper")
+	}
+	perpKeeper, err := keeper.New(keeperDelay, keeperRecords, keeperFee, keeperFixed0, keeperMultiple, keeperRate, keeperFailure, rand.New(rand.NewSource(keeperSeed)))
+	if err != nil {
+		log.Fatal().Err(err).Str("keeper_fee", keeperFee).Msg("could not create perp hedge keeper")
+	}
+
+	// Each liquidity position keeps track of its own harvests.
+	uniCompounder, err := position.NewCompounder(compoundPolicy, harvestInterval, harvestThreshold0, timestamp)
+	if err != nil {
+		log.Fatal().Err(err).Str("compound_policy", compoundPolicy).Msg("could not create uniswap compounder")
+	}
+	autoCompounder, err := position.NewCompounder(compoundPolicy, harvestInterval, harvestThreshold0, timestamp)
+	if err != nil {
+		log.Fatal().Err(err).Str("compound_policy", compoundPolicy).Msg("could not create autohedge compounder")
+	}
+	perpCompounder, err := position.NewCompounder(compoundPolicy, harvestInterval, harvestThreshold0, timestamp)
+	if err != nil {
+		log.Fatal().Err(err).Str("compound_policy", compoundPolicy).Msg("could not create perp hedge compounder")
+	}
+	optionsCompounder, err := position.NewCompounder(compoundPolicy, harvestInterval, harvestThreshold0, timestamp)
+	if err != nil {
+		log.Fatal().Err(err).Str("compound_policy", compoundPolicy).Msg("could not create options hedge compounder")
+	}
+
+	// Each liquidity position also keeps track of its own reward sales.
+	uniSeller, err := position.NewSeller(rewardSale, rewardSaleInterval, timestamp)
+	if err != nil {
+		log.Fatal().Err(err).Str("reward_sale", rewardSale).Msg("could not create uniswap reward seller")
+	}
+	autoSeller, err := position.NewSeller(rewardSale, rewardSaleInterval, timestamp)
+	if err != nil {
+		log.Fatal().Err(err).Str("reward_sale", rewardSale).Msg("could not create autohedge reward seller")
+	}
+	perpSeller, err := position.NewSeller(rewardSale, rewardSaleInterval, timestamp)
+	if err != nil {
+		log.Fatal().Err(err).Str("reward_sale", rewardSale).Msg("could not create perp hedge reward seller")
+	}
+	optionsSeller, err := position.NewSeller(rewardSale, rewardSaleInterval, timestamp)
+	if err != nil {
+		log.Fatal().Err(err).Str("reward_sale", rewardSale).Msg("could not create options hedge reward seller")
+	}
+
 	log.Info().
 		Time("timestamp", timestamp).
-		Float64("input", b.ToFloat(input0, 6)).
-		Float64("hold", b.ToFloat(hold.Value0(reserve0, reserve1), 6)).
-		Float64("uniswap", b.ToFloat(uniswap.Value0(reserve0, reserve1), 6)).
-		Float64("autohedge", b.ToFloat(autohedge.Value0(reserve0, reserve1), 6)).
+		Float64("input", input0.Float()).
+		Float64("hold", checked(hold.Value0(reserve0, reserve1)).Float()).
+		Float64("uniswap", checked(uniswap.Value0(reserve0, reserve1)).Float()).
+		Float64("autohedge", checked(autohedge.Value0(reserve0, reserve1)).Float()).
+		Float64("dca", checked(dca.Value0(reserve0, reserve1)).Float()).
+		Float64("rebalance", checked(rebalance.Value0(reserve0, reserve1)).Float()).
+		Float64("lend", checked(lend.Value0(reserve0, reserve1)).Float()).
 		Msg("position values initialized")
 
+	// All strategies of the run are listed once, so that the analytics, the
+	// archive and the attribution go through them in the same order.
+	strategies := register(&hold, &uniswap, &autohedge, &dca, &rebalance, &lend, perpHedge, options)
+	names := make([]string, 0, len(strategies))
+	for _, s := range strategies {
+		names = append(names, s.Name)
+	}
+
+	// The analytics track the value of each strategy over the run, with the
+	// lend rate as risk-free rate. The dollar-cost averaging baseline puts its
+	// input to work tranche by tranche, which its money-weighted return
+	// accounts for.
+	trackers := make(map[string]*analytics.Tracker, len(strategies))
+	for _, s := range strategies {
+		trackers[s.Name] = analytics.NewTracker(s.Name, input0.Float())
+	}
+	trackers["dca"].Deposit(first, dcaFirst0.Float())
+
+	// When comparing against a benchmark, every other strategy is also
+	// tracked relative to it. Holding only the volatile token is not one of
+	// the strategies, so it is valued without any costs from the entry price.
+	benchmark1 := checked(util.Quote(input0, reserve0, reserve1))
+	relatives := make(map[string]*analytics.Relative, len(strategies))
+	for _, s := range strategies {
+		if benchmark != "" && s.Name != benchmark {
+			relatives[s.Name] = analytics.NewRelative(s.Name, benchmark, input0.Float())
+		}
+	}
+
+	// The archive keeps the values, costs and actions of all strategies for
+	// reports after the run; actions are recorded whenever their counters
+	// increase, even if the point is skipped because of the archive step. The
+	// last skipped point is kept, so that the archive always ends with the
+	// final record.
+	var (
+		record   *archive.Archive
+		archived time.Time
+		skipped  *archive.Point
+	)
+	if archiveFile != "" {
+		record = &archive.Archive{
+			Chain:       chainName,
+			Pair:        pairName,
+			Strategies:  names,
+			Parameters:  parameters(pflag.CommandLine),
+			Points:      []archive.Point{},
+			Events:      []archive.Event{},
+			Summaries:   []analytics.Summary{},
+			Comparisons: []analytics.Comparison{},
+		}
+	}
+	counts := make(map[string]uint)
+
+	observe := func(timestamp time.Time, reserve0 b.Amount, reserve1 b.Amount, rate b.Ray) {
+
+		values := map[string]float64{
+			"token1": checked(util.Quote(benchmark1, reserve1, reserve0)).Float(),
+		}
+		for _, s := range strategies {
+			values[s.Name] = checked(s.Value0(reserve0, reserve1)).Float()
+		}
+
+		for _, s := range strategies {
+			trackers[s.Name].Observe(timestamp, values[s.Name], rate.Float())
+			relative, ok := relatives[s.Name]
+			if !ok {
+				continue
+			}
+			relative.Observe(timestamp, values[s.Name], values[benchmark])
+			if writeResults {
+				write.RelativePoint(timestamp, *relative, pair, outbound)
+			}
+		}
+
+		if record == nil {
+			return
+		}
+
+		price := checked(util.Quote(unit1, reserve1, reserve0)).Float()
+
+		for _, s := range strategies {
+			count := s.actions()
+			if count > counts[s.Name] {
+				record.Events = append(record.Events, archive.Event{
+					Timestamp: timestamp,
+					Strategy:  s.Name,
+					Kind:      s.Action,
+					Price:     price,
+				})
+			}
+			counts[s.Name] = count
+		}
+
+		fees := make(map[string]float64, len(strategies))
+		costs := make(map[string]float64, len(strategies))
+		for _, s := range strategies {
+			fees[s.Name] = s.Fees0().Float()
+			costs[s.Name] = s.Cost0().Float()
+		}
+
+		point := archive.Point{
+			Timestamp: timestamp,
+			Price:     price,
+			Values:    values,
+			Fees:      fees,
+			Costs:     costs,
+		}
+		if !archived.IsZero() && timestamp.Sub(archived) < archiveStep {
+			skipped = &point
+			return
+		}
+		archived = timestamp
+		skipped = nil
+
+		record.Points = append(record.Points, point)
+	}
+	observe(timestamp, reserve0, reserve1, model.Rate(timestamp))
+
+	// The liquidity positions are also attributed against hold, to separate
+	// the price exposure from the fee income and the costs.
+	attributed := make([]strategy, 0, len(strategies))
+	for _, s := range strategies {
+		if s.State != nil {
+			attributed = append(attributed, s)
+		}
+	}
+	attributions := make(map[string]*attribution.Attribution, len(attributed))
+	for _, s := range attributed {
+		attributions[s.Name] = attribution.New(s.Name)
+	}
+	attribute := func(timestamp time.Time, reserve0 b.Amount, reserve1 b.Amount) {
+
+		holdValue0 := checked(hold.Value0(reserve0, reserve1))
+		holdState := attribution.Hold(holdValue0.Int(), hold.Amount1.Int(), hold.Fees0.Int(), hold.Cost0.Int())
+		for _, s := range attributed {
+			state, err := s.State(reserve0, reserve1)
+			if err != nil {
+				log.Fatal().Err(err).Str("strategy", s.Name).Msg("could not compute attribution state")
+			}
+			err = attributions[s.Name].Update(state, holdState, reserve0.Int(), reserve1.Int())
+			if err != nil {
+				log.Fatal().Err(err).Str("strategy", s.Name).Msg("could not update attribution")
+			}
+			if writeResults {
+				write.AttributionPoint(timestamp, s.Size, *attributions[s.Name], pair, outbound)
+			}
+		}
+	}
+	attribute(timestamp, reserve0, reserve1)
+
+	// The points of all positions are written for every record. Writing one
+	// only fails if valuing its position fails.
+	points := func(timestamp time.Time, reserve0 b.Amount, reserve1 b.Amount) {
+
+		errs := []error{
+			write.HoldPoint(timestamp, reserve0, reserve1, hold, pair, outbound),
+			write.UniswapPoint(timestamp, reserve0, reserve1, uniswap, pair, outbound),
+			write.AutohedgePoint(timestamp, reserve0, reserve1, autohedge, pair, outbound),
+			write.DCAPoint(timestamp, reserve0, reserve1, dca, pair, outbound),
+			write.RebalancePoint(timestamp, reserve0, reserve1, rebalance, pair, outbound),
+			write.LendPoint(timestamp, reserve0, reserve1, lend, pair, outbound),
+		}
+		if perpHedge != nil {
+			errs = append(errs, write.PerpHedgePoint(timestamp, reserve0, reserve1, *perpHedge, pair, outbound))
+		}
+		if options != nil {
+			errs = append(errs, write.OptionsPoint(timestamp, reserve0, reserve1, *options, pair, outbound))
+		}
+		for _, err := range errs {
+			if err != nil {
+				log.Fatal().Err(err).Msg("could not write position point")
+			}
+		}
+	}
 	if writeResults {
-		write.HoldPoint(timestamp, reserve0, reserve1, hold, outbound)
-		write.UniswapPoint(timestamp, reserve0, reserve1, uniswap, outbound)
-		write.AutohedgePoint(timestamp, reserve0, reserve1, autohedge, outbound)
+		points(timestamp, reserve0, reserve1)
+	}
+
+	// Reward tokens that are still held have to be claimed and sold on exit.
+	rewardExit := func(exit *position.Exit, rewards position.Rewards, reserve0 b.Amount, reserve1 b.Amount, gasPrice1 *big.Int) {
+
+		fees0 := checked(rewards.Value0.MulBps(rewardSwapRate, b.RoundDown))
+		exit.Fees0 = checked(exit.Fees0.Add(fees0))
+
+		cost1 := big.NewInt(0).Mul(saleGas, gasPrice1)
+		cost0 := native0(cost1, reserve0, reserve1)
+		exit.Cost0 = checked(exit.Cost0.Add(cost0))
 	}
 
+	// The exit simulation unwinds all positions into the stable coin at the
+	// given time, without actually closing them, so we can report the value
+	// we would have been able to withdraw next to the mark-to-market value.
+	// Sandwich attacks are only charged on the exit swaps of the positions
+	// that hold token1 directly; liquidity positions swap against the reserves
+	// left after their withdrawal, which the sandwich model does not cover.
+	exit := func(timestamp time.Time, reserve0 b.Amount, reserve1 b.Amount, gasPrice1 *big.Int) map[string]position.Exit {
+
+		exits := make(map[string]position.Exit)
+
+		holdCost1 := big.NewInt(0).Mul(exitHoldGas, gasPrice1)
+		holdCost0 := native0(holdCost1, reserve0, reserve1)
+		holdCost0 = checked(holdCost0.Add(extract(timestamp, "hold", "exit", hold.Size, hold.Amount1, false, reserve0, reserve1)))
+		holdExit, err := hold.Exit(reserve0, reserve1, swapRate, holdCost0)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not exit hold position")
+		}
+
+		uniCost1 := big.NewInt(0).Mul(exitUniGas, gasPrice1)
+		uniCost0 := native0(uniCost1, reserve0, reserve1)
+		uniExit, err := uniswap.Exit(reserve0, reserve1, swapRate, uniCost0)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not exit uniswap position")
+		}
+		if uniswap.Rewards.Amount.Sign() > 0 {
+			rewardExit(&uniExit, uniswap.Rewards, reserve0, reserve1, gasPrice1)
+		}
+
+		autoCost1 := big.NewInt(0).Mul(exitAutoGas, gasPrice1)
+		autoCost0 := native0(autoCost1, reserve0, reserve1)
+		autoExit, err := autohedge.Exit(reserve0, reserve1, swapRate, autoCost0)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not exit autohedge position")
+		}
+		if autohedge.Rewards.Amount.Sign() > 0 {
+			rewardExit(&autoExit, autohedge.Rewards, reserve0, reserve1, gasPrice1)
+		}
+
+		dcaCost1 := big.NewInt(0).Mul(exitHoldGas, gasPrice1)
+		dcaCost0 := native0(dcaCost1, reserve0, reserve1)
+		dcaCost0 = checked(dcaCost0.Add(extract(timestamp, "dca", "exit", dca.Size, dca.Amount1, false, reserve0, reserve1)))
+		dcaExit, err := dca.Exit(reserve0, reserve1, swapRate, dcaCost0)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not exit dca position")
+		}
+
+		rebalanceCost1 := big.NewInt(0).Mul(exitHoldGas, gasPrice1)
+		rebalanceCost0 := native0(rebalanceCost1, reserve0, reserve1)
+		rebalanceCost0 = checked(rebalanceCost0.Add(extract(timestamp, "rebalance", "exit", rebalance.Size, rebalance.Amount1, false, reserve0, reserve1)))
+		rebalanceExit, err := rebalance.Exit(reserve0, reserve1, swapRate, rebalanceCost0)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not exit rebalance position")
+		}
+
+		lendCost1 := big.NewInt(0).Mul(claimGas, gasPrice1)
+		lendCost0 := native0(lendCost1, reserve0, reserve1)
+		lendExit, err := lend.Exit(reserve0, reserve1, lendCost0)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not exit lend position")
+		}
+
+		if perpHedge != nil {
+
+			perpCost1 := big.NewInt(0).Mul(exitPerpGas, gasPrice1)
+			perpCost0 := native0(perpCost1, reserve0, reserve1)
+			perpExit, err := perpHedge.Exit(reserve0, reserve1, swapRate, perpFeeRate, perpCost0)
+			if err != nil {
+				log.Fatal().Err(err).Msg("could not exit perp hedge position")
+			}
+			if perpHedge.Rewards.Amount.Sign() > 0 {
+				rewardExit(&perpExit, perpHedge.Rewards, reserve0, reserve1, gasPrice1)
+			}
+
+			if writeResults {
+				err = write.ExitPoint(timestamp, "perphedge", perpHedge.Size, perpExit, pair, outbound)
+				if err != nil {
+					log.Fatal().Err(err).Msg("could not write exit point")
+				}
+			}
+			exits["perphedge"] = perpExit
+
+			log.Info().
+				Time("timestamp", timestamp).
+				Float64("perphedge_value", perpExit.Value0.Float()).
+				Float64("perphedge_realizable", checked(perpExit.Realizable0()).Float()).
+				Msg("perp hedge value realized")
+		}
+
+		if options != nil {
+
+			optionsCost1 := big.NewInt(0).Mul(exitOptionGas, gasPrice1)
+			optionsCost0 := native0(optionsCost1, reserve0, reserve1)
+			optionsExit, err := options.Exit(reserve0, reserve1, swapRate, optionFeeRate, optionsCost0)
+			if err != nil {
+				log.Fatal().Err(err).Msg("could not exit options hedge position")
+			}
+			if options.Rewards.Amount.Sign() > 0 {
+				rewardExit(&optionsExit, options.Rewards, reserve0, reserve1, gasPrice1)
+			}
+
+			if writeResults {
+				err = write.ExitPoint(timestamp, "options", options.Size, optionsExit, pair, outbound)
+				if err != nil {
+					log.Fatal().Err(err).Msg("could not write exit point")
+				}
+			}
+			exits["options"] = optionsExit
+
+			log.Info().
+				Time("timestamp", timestamp).
+				Float64("options_value", optionsExit.Value0.Float()).
+				Float64("options_realizable", checked(optionsExit.Realizable0()).Float()).
+				Msg("options hedge value realized")
+		}
+
+		if writeResults {
+			errs := []error{
+				write.ExitPoint(timestamp, "hold", hold.Size, holdExit, pair, outbound),
+				write.ExitPoint(timestamp, "uniswap", uniswap.Size, uniExit, pair, outbound),
+				write.ExitPoint(timestamp, "autohedge", autohedge.Size, autoExit, pair, outbound),
+				write.ExitPoint(timestamp, "dca", dca.Size, dcaExit, pair, outbound),
+				write.ExitPoint(timestamp, "rebalance", rebalance.Size, rebalanceExit, pair, outbound),
+				write.ExitPoint(timestamp, "lend", lend.Size, lendExit, pair, outbound),
+			}
+			for _, err := range errs {
+				if err != nil {
+					log.Fatal().Err(err).Msg("could not write exit point")
+				}
+			}
+		}
+
+		log.Info().
+			Time("timestamp", timestamp).
+			Float64("hold_value", holdExit.Value0.Float()).
+			Float64("hold_realizable", checked(holdExit.Realizable0()).Float()).
+			Float64("uniswap_value", uniExit.Value0.Float()).
+			Float64("uniswap_realizable", checked(uniExit.Realizable0()).Float()).
+			Float64("autohedge_value", autoExit.Value0.Float()).
+			Float64("autohedge_realizable", checked(autoExit.Realizable0()).Float()).
+			Float64("dca_value", dcaExit.Value0.Float()).
+			Float64("dca_realizable", checked(dcaExit.Realizable0()).Float()).
+			Float64("rebalance_value", rebalanceExit.Value0.Float()).
+			Float64("rebalance_realizable", checked(rebalanceExit.Realizable0()).Float()).
+			Float64("lend_value", lendExit.Value0.Float()).
+			Float64("lend_realizable", checked(lendExit.Realizable0()).Float()).
+			Msg("position values realized")
+
+		exits["hold"] = holdExit
+		exits["uniswap"] = uniExit
+		exits["autohedge"] = autoExit
+		exits["dca"] = dcaExit
+		exits["rebalance"] = rebalanceExit
+		exits["lend"] = lendExit
+
+		return exits
+	}
+
+	// Gas is paid at the price of the first record, unless daily gas prices
+	// are enabled; exits always pay the gas price of the day they happen on,
+	// as they are what we could withdraw at that time.
+	exitPrice1 := gasPrice1
+
 	last := timestamp
-	for result.Next() {
+	for data.Next() {
 
-		record := result.Record()
-		timestamp := record.Time()
-		values := record.Values()
+		snapshot, _ = data.Snapshot()
+		timestamp = snapshot.Timestamp
+		reserve0, reserve1 = pair.Amounts(snapshot.Reserve0, snapshot.Reserve1)
 
-		reserve0 := b.FromHex(values["reserve0"])
-		reserve1 := b.FromHex(values["reserve1"])
+		exitPrice1, err = station.Gasprice(timestamp)
+		if err != nil {
+			log.Fatal().Err(err).Time("timestamp", timestamp).Msg("could not get gas price for timestamp")
+		}
+		if gasDaily {
+			gasPrice1 = exitPrice1
+		}
 
-		volume0 := b.FromHex(values["volume0"])
-		volume1 := b.FromHex(values["volume1"])
+		volume0, volume1 := pair.Amounts(snapshot.Volume0, snapshot.Volume1)
 
-		liquidity := big.NewInt(0).Mul(reserve0, reserve1)
+		liquidity := big.NewInt(0).Mul(reserve0.Int(), reserve1.Int())
 		liquidity.Sqrt(liquidity)
 
-		sqrtReserve0 := big.NewInt(0).Sqrt(reserve0)
-		sqrtReserve1 := big.NewInt(0).Sqrt(reserve1)
+		sqrtReserve0 := big.NewInt(0).Sqrt(reserve0.Int())
+		sqrtReserve1 := big.NewInt(0).Sqrt(reserve1.Int())
 
 		log := log.With().
 			Time("timestamp", timestamp).
 			Logger()
 
 		log.Debug().
-			Float64("reserve0", b.ToFloat(reserve0, 6)).
-			Float64("reserve1", b.ToFloat(reserve1, 18)).
-			Float64("volume0", b.ToFloat(volume0, 6)).
-			Float64("volume1", b.ToFloat(volume1, 18)).
-			Float64("liquidity", b.ToFloat(liquidity, 12)).
+			Float64("reserve0", reserve0.Float()).
+			Float64("reserve1", reserve1.Float()).
+			Float64("volume0", volume0.Float()).
+			Float64("volume1", volume1.Float()).
+			Float64("liquidity", pair.Liquidity(liquidity)).
 			Msg("extracted datapoint from record")
 
 		elapsed := big.NewInt(int64(timestamp.Sub(last).Seconds()))
 
-		realLoanRate := util.CalculateCompoundedInterest(loanRate, elapsed)
-		yieldDelta0 := big.NewInt(0).Add(autohedge.Principal0, autohedge.Yield0)
-		yieldDelta0.Mul(yieldDelta0, realLoanRate)
-		yieldDelta0.Div(yieldDelta0, b.E27)
-		autohedge.Yield0.Add(autohedge.Yield0, yieldDelta0)
+		if schedule != nil {
+
+			rewardRate := schedule.Rate(last)
+			price0, err := feed.Price(timestamp)
+			if err != nil {
+				log.Fatal().Err(err).Msg("could not get reward price for timestamp")
+			}
+			rewardPrice0 := token0.Amount(price0)
+
+			// Rewards are accrued, marked and sold in the same way for all
+			// liquidity positions.
+			reward := func(rewards *position.Rewards, seller *position.Seller, liquidity0 *big.Int, saleCost0 b.Amount) b.Amount {
+				earned := checked(rewards.Accrue(rewardRate, elapsed, liquidity0, liquidity))
+				err := rewards.Mark(rewardPrice0)
+				if err != nil {
+					log.Fatal().Err(err).Msg("could not mark rewards")
+				}
+				if seller.Sell(timestamp, *rewards) {
+					err = rewards.Sell(rewardSwapRate, saleCost0)
+					if err != nil {
+						log.Fatal().Err(err).Msg("could not sell rewards")
+					}
+				}
+				return earned
+			}
+
+			saleCost1 := big.NewInt(0).Mul(saleGas, gasPrice1)
+			saleCost0 := native0(saleCost1, reserve0, reserve1)
+
+			earnedUni := reward(&uniswap.Rewards, uniSeller, uniswap.Liquidity, saleCost0)
+			earnedAuto := reward(&autohedge.Rewards, autoSeller, autohedge.Liquidity, saleCost0)
+
+			log.Debug().
+				Float64("price0", rewardPrice0.Float()).
+				Float64("uniswap_earned", earnedUni.Float()).
+				Float64("uniswap_rewards0", checked(uniswap.Rewards.Net0()).Float()).
+				Uint("uniswap_sales", uniswap.Rewards.Sales).
+				Float64("autohedge_earned", earnedAuto.Float()).
+				Float64("autohedge_rewards0", checked(autohedge.Rewards.Net0()).Float()).
+				Uint("autohedge_sales", autohedge.Rewards.Sales).
+				Msg("accrued liquidity mining rewards")
+
+			if perpHedge != nil {
+				reward(&perpHedge.Rewards, perpSeller, perpHedge.Liquidity, saleCost0)
+			}
+
+			if options != nil {
+				reward(&options.Rewards, optionsSeller, options.Liquidity, saleCost0)
+			}
+		}
+
+		// The lend rate that applied since the last record is used for both
+		// the autohedge collateral and the lend position.
+		lendRate := model.Rate(last)
+
+		realLoanRate := util.CalculateCompoundedInterest(lendRate, elapsed)
+		yieldDelta0 := checked(checked(autohedge.Principal0.Add(autohedge.Yield0)).MulRay(realLoanRate))
+		autohedge.Yield0 = checked(autohedge.Yield0.Add(yieldDelta0))
 
 		realBorrowRate := util.CalculateCompoundedInterest(borrowRate, elapsed)
-		interestDelta1 := big.NewInt(0).Add(autohedge.Debt1, autohedge.Interest1)
-		interestDelta1.Mul(interestDelta1, realBorrowRate)
-		interestDelta1.Div(interestDelta1, b.E27)
-		autohedge.Interest1.Add(autohedge.Interest1, interestDelta1)
+		interestDelta1 := checked(checked(autohedge.Debt1.Add(autohedge.Interest1)).MulRay(realBorrowRate))
+		autohedge.Interest1 = checked(autohedge.Interest1.Add(interestDelta1))
+
+		lendDelta0 := checked(lend.Accrue(lendRate, elapsed))
 
 		last = timestamp
 
 		log.Debug().
-			Float64("principal0", b.ToFloat(autohedge.Principal0, 6)).
-			Float64("yield0", b.ToFloat(autohedge.Yield0, 6)).
-			Float64("gain0", b.ToFloat(yieldDelta0, 6)).
-			Float64("debt1", b.ToFloat(autohedge.Debt1, 18)).
-			Float64("interest1", b.ToFloat(autohedge.Interest1, 18)).
-			Float64("loss1", b.ToFloat(interestDelta1, 18)).
+			Float64("principal0", autohedge.Principal0.Float()).
+			Float64("yield0", autohedge.Yield0.Float()).
+			Float64("gain0", yieldDelta0.Float()).
+			Float64("debt1", autohedge.Debt1.Float()).
+			Float64("interest1", autohedge.Interest1.Float()).
+			Float64("loss1", interestDelta1.Float()).
+			Float64("lend0", lendDelta0.Float()).
 			Msg("compounded principal yield and debt interest")
 
-		uni0 := big.NewInt(0).Mul(uniswap.Liquidity, sqrtReserve0)
-		uni0.Div(uni0, sqrtReserve1)
+		if dca.Amount0.Sign() > 0 && !timestamp.Before(dcaNext) {
 
-		uni1 := util.Quote(uni0, reserve0, reserve1)
+			cost1 := big.NewInt(0).Mul(swapGas, gasPrice1)
+			cost0 := native0(cost1, reserve0, reserve1)
+			in0 := checked(dca.Next0())
+			cost0 = checked(cost0.Add(extract(timestamp, "dca", "buy", dca.Size, in0, true, reserve0, reserve1)))
+			out1 := checked(dca.Buy(reserve0, reserve1, swapRate, cost0))
+			dcaNext = dcaNext.Add(dcaInterval)
+			trackers["dca"].Deposit(timestamp, in0.Float())
 
-		profitUni0 := big.NewInt(0).Mul(volume0, swapRate)
-		profitUni0.Div(profitUni0, b.E3)
-		profitUni0.Mul(profitUni0, uniswap.Liquidity)
-		profitUni0.Div(profitUni0, liquidity)
-		uni0.Add(uni0, profitUni0)
+			log.Debug().
+				Float64("out1", out1.Float()).
+				Float64("amount0", dca.Amount0.Float()).
+				Float64("amount1", dca.Amount1.Float()).
+				Float64("fees0", dca.Fees0.Float()).
+				Float64("cost0", dca.Cost0.Float()).
+				Uint("buys", dca.Buys).
+				Msg("bought tranche for dca position")
+		}
 
-		profitUni1 := big.NewInt(0).Mul(volume1, swapRate)
-		profitUni1.Div(profitUni1, b.E3)
-		profitUni1.Mul(profitUni1, swapRate)
-		profitUni1.Div(profitUni1, liquidity)
-		uni1.Add(uni1, profitUni1)
+		drifted, err := rebalance.Drifted(reserve0, reserve1)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not check rebalance drift")
+		}
+		periodic := rebalanceInterval > 0 && timestamp.Sub(rebalanceLast) >= rebalanceInterval
+		if periodic || drifted {
+
+			cost1 := big.NewInt(0).Mul(swapGas, gasPrice1)
+			cost0 := native0(cost1, reserve0, reserve1)
+			delta0 := checked(rebalance.Delta0(reserve0, reserve1))
+			switch delta0.Sign() {
+			case 1:
+				cost0 = checked(cost0.Add(extract(timestamp, "rebalance", "rebalance", rebalance.Size, delta0, true, reserve0, reserve1)))
+			case -1:
+				in1 := checked(util.Quote(delta0.Neg(), reserve0, reserve1))
+				cost0 = checked(cost0.Add(extract(timestamp, "rebalance", "rebalance", rebalance.Size, in1, false, reserve0, reserve1)))
+			}
+			swapped1 := checked(rebalance.Rebalance(reserve0, reserve1, swapRate, cost0))
+			rebalanceLast = timestamp
+
+			log.Debug().
+				Float64("swapped1", swapped1.Float()).
+				Float64("amount0", rebalance.Amount0.Float()).
+				Float64("amount1", rebalance.Amount1.Float()).
+				Float64("fees0", rebalance.Fees0.Float()).
+				Float64("cost0", rebalance.Cost0.Float()).
+				Uint("count", rebalance.Count).
+				Msg("rebalanced constant-mix position")
+		}
 
-		uniswap.Liquidity = big.NewInt(0).Mul(uni0, uni1)
-		uniswap.Liquidity.Sqrt(uniswap.Liquidity)
+		profitUni0, profitUni1, err := position.Fees(volume0, volume1, swapRate, liquidity, uniswap.Liquidity)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not compute uniswap fees")
+		}
+		uniswap.Pending0 = checked(uniswap.Pending0.Add(profitUni0))
+		uniswap.Pending1 = checked(uniswap.Pending1.Add(profitUni1))
 
-		uniswap.Profit0.Add(uniswap.Profit0, profitUni0)
-		uniswap.Profit0.Add(uniswap.Profit0, util.Quote(profitUni1, reserve1, reserve0))
+		uniswap.Profit0 = checked(uniswap.Profit0.Add(profitUni0))
+		uniswap.Profit0 = checked(uniswap.Profit0.Add(checked(util.Quote(profitUni1, reserve1, reserve0))))
 
 		log.Debug().
-			Float64("amount0", b.ToFloat(uni0, 6)).
-			Float64("amount1", b.ToFloat(uni1, 18)).
-			Float64("profit0", b.ToFloat(profitUni0, 6)).
-			Float64("profit1", b.ToFloat(profitUni1, 18)).
-			Float64("liquidity", b.ToFloat(uniswap.Liquidity, 12)).
+			Float64("profit0", profitUni0.Float()).
+			Float64("profit1", profitUni1.Float()).
+			Float64("pending0", uniswap.Pending0.Float()).
+			Float64("pending1", uniswap.Pending1.Float()).
 			Msg("added profit to uniswap position")
 
-		auto0 := big.NewInt(0).Mul(autohedge.Liquidity, sqrtReserve0)
-		auto0.Div(auto0, sqrtReserve1)
+		pendingUni0 := checked(uniswap.Unharvested0(reserve0, reserve1))
+		harvestUni, err := uniCompounder.Harvest(timestamp, pendingUni0)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not check uniswap harvest")
+		}
+		if harvestUni {
+
+			err = uniswap.Compound(reserve0, reserve1)
+			if err != nil {
+				log.Fatal().Err(err).Msg("could not compound uniswap fees")
+			}
+
+			if uniCompounder.Costly() {
+				cost1 := big.NewInt(0).Mul(compoundGas, gasPrice1)
+				cost0 := native0(cost1, reserve0, reserve1)
+				uniswap.Cost0 = checked(uniswap.Cost0.Add(cost0))
+				uniswap.Harvests++
+
+				log.Debug().
+					Float64("harvested0", pendingUni0.Float()).
+					Float64("liquidity", pair.Liquidity(uniswap.Liquidity)).
+					Float64("cost0", uniswap.Cost0.Float()).
+					Uint("harvests", uniswap.Harvests).
+					Msg("harvested fees of uniswap position")
+			}
+		}
 
-		auto1 := util.Quote(auto0, reserve0, reserve1)
+		profitAuto0, profitAuto1, err := position.Fees(volume0, volume1, swapRate, liquidity, autohedge.Liquidity)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not compute autohedge fees")
+		}
+		autohedge.Pending0 = checked(autohedge.Pending0.Add(profitAuto0))
+		autohedge.Pending1 = checked(autohedge.Pending1.Add(profitAuto1))
 
-		profitAuto0 := big.NewInt(0).Mul(volume0, swapRate)
-		profitAuto0.Div(profitAuto0, b.E3)
-		profitAuto0.Mul(profitAuto0, autohedge.Liquidity)
-		profitAuto0.Div(profitAuto0, liquidity)
-		auto0.Add(auto0, profitAuto0)
+		autohedge.Profit0 = checked(autohedge.Profit0.Add(profitAuto0))
+		autohedge.Profit0 = checked(autohedge.Profit0.Add(checked(util.Quote(profitAuto1, reserve1, reserve0))))
 
-		profitAuto1 := big.NewInt(0).Mul(volume1, swapRate)
-		profitAuto1.Div(profitAuto1, b.E3)
-		profitAuto1.Mul(profitAuto1, autohedge.Liquidity)
-		profitAuto1.Div(profitAuto1, liquidity)
-		auto1.Add(auto1, profitAuto1)
+		log.Debug().
+			Float64("profit0", profitAuto0.Float()).
+			Float64("profit1", profitAuto1.Float()).
+			Float64("pending0", autohedge.Pending0.Float()).
+			Float64("pending1", autohedge.Pending1.Float()).
+			Msg("added profit to autohedge position")
 
-		autohedge.Liquidity = big.NewInt(0).Mul(auto0, auto1)
-		autohedge.Liquidity.Sqrt(autohedge.Liquidity)
+		pendingAuto0 := checked(autohedge.Unharvested0(reserve0, reserve1))
+		harvestAuto, err := autoCompounder.Harvest(timestamp, pendingAuto0)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not check autohedge harvest")
+		}
+		if harvestAuto {
+
+			err = autohedge.Compound(reserve0, reserve1)
+			if err != nil {
+				log.Fatal().Err(err).Msg("could not compound autohedge fees")
+			}
+
+			if autoCompounder.Costly() {
+				cost1 := big.NewInt(0).Mul(compoundGas, gasPrice1)
+				cost0 := native0(cost1, reserve0, reserve1)
+				autohedge.Cost0 = checked(autohedge.Cost0.Add(cost0))
+				autohedge.Harvests++
+
+				log.Debug().
+					Float64("harvested0", pendingAuto0.Float()).
+					Float64("liquidity", pair.Liquidity(autohedge.Liquidity)).
+					Float64("cost0", autohedge.Cost0.Float()).
+					Uint("harvests", autohedge.Harvests).
+					Msg("harvested fees of autohedge position")
+			}
+		}
 
-		autohedge.Profit0.Add(autohedge.Profit0, profitAuto0)
-		autohedge.Profit0.Add(autohedge.Profit0, util.Quote(profitUni1, reserve1, reserve0))
+		held0 := big.NewInt(0).Mul(autohedge.Liquidity, sqrtReserve0)
+		position0 := token0.Amount(held0.Div(held0, sqrtReserve1))
+		position1 := checked(util.Quote(position0, reserve0, reserve1))
 
-		log.Debug().
-			Float64("amount0", b.ToFloat(auto0, 6)).
-			Float64("amount1", b.ToFloat(auto1, 18)).
-			Float64("profit0", b.ToFloat(profitAuto0, 6)).
-			Float64("profit1", b.ToFloat(profitAuto1, 18)).
-			Float64("liquidity", b.ToFloat(autohedge.Liquidity, 12)).
-			Msg("added profit to autohedge position")
+		debt1 := checked(autohedge.Debt1.Add(autohedge.Interest1))
 
-		position0 := big.NewInt(0).Mul(autohedge.Liquidity, sqrtReserve0)
-		position0.Div(position0, sqrtReserve1)
-		position1 := util.Quote(position0, reserve0, reserve1)
+		estimator.Observe(timestamp, checked(util.Quote(unit1, reserve1, reserve0)).Float())
+		volatility := estimator.Volatility(timestamp)
 
-		debt1 := big.NewInt(0).Add(autohedge.Debt1, autohedge.Interest1)
-		diff1 := big.NewInt(0).Mul(debt1, rehedgeRatio)
-		diff1.Div(diff1, b.E3)
+		// Decreasing and increasing the debt use different transactions, so
+		// the gas cost of the rehedge depends on its direction.
+		autoGas := big.NewInt(0).Add(increaseGas, swapGas)
+		autoGas.Add(autoGas, addGas)
+		if checked(position1.Sub(debt1)).Sign() < 0 {
+			autoGas = big.NewInt(0).Add(removeGas, swapGas)
+			autoGas.Add(autoGas, decreaseGas)
+		}
+		autoGas.Mul(autoGas, gasPrice1)
 
-		bigger1 := big.NewInt(0).Add(debt1, diff1)
-		smaller1 := big.NewInt(0).Sub(debt1, diff1)
+		autoConditions := position.Conditions{
+			Reserve0:   reserve0,
+			Reserve1:   reserve1,
+			Volatility: volatility,
+			Cost0:      native0(autoGas, reserve0, reserve1),
+		}
+		close1 := checked(autoTrigger.Check(timestamp, position1, debt1, autoConditions))
+
+		// The keeper executes the rehedge once it is due and the delay has
+		// passed, as long as it is still due, closing the gap as it is at the
+		// time of execution.
+		if autoKeeper.Ready(timestamp, close1.Sign() != 0) {
+			close1 = checked(autoTrigger.Gap(position1, debt1))
+		} else {
+			close1 = zero1
+		}
 
-		switch {
+		switch close1.Sign() {
 
-		case position1.Cmp(smaller1) < 0:
+		case 1:
 
-			delta1 := big.NewInt(0).Sub(debt1, position1)
+			delta1 := close1
 
-			swapMul := big.NewInt(0).Add(b.E3, swapRate)
-			out1 := big.NewInt(0).Mul(delta1, swapMul)
-			out1.Div(out1, b.E3)
-			position1.Sub(position1, out1)
+			fee1 := checked(delta1.MulBps(swapRate, b.RoundDown))
+			out1 := checked(delta1.Add(fee1))
+			position1 = checked(position1.Sub(out1))
 
-			out0 := util.Quote(out1, reserve1, reserve0)
-			position0.Sub(position0, out0)
+			out0 := checked(util.Quote(out1, reserve1, reserve0))
+			position0 = checked(position0.Sub(out0))
 
-			fee1 := big.NewInt(0).Sub(out1, delta1)
-			fee0 := util.Quote(fee1, reserve1, reserve0)
-			autohedge.Fees0.Add(autohedge.Fees0, fee0)
+			fee0 := checked(util.Quote(fee1, reserve1, reserve0))
+			autohedge.Fees0 = checked(autohedge.Fees0.Add(fee0))
 
-			autohedge.Debt1.Sub(autohedge.Debt1, out1)
-			autohedge.Debt1.Sub(autohedge.Debt1, out1)
-			autohedge.Debt1.Add(autohedge.Debt1, fee1)
+			autohedge.Debt1 = checked(autohedge.Debt1.Sub(out1))
+			autohedge.Debt1 = checked(autohedge.Debt1.Sub(out1))
+			autohedge.Debt1 = checked(autohedge.Debt1.Add(fee1))
 
 			cost1 := big.NewInt(0).Add(removeGas, swapGas)
 			cost1.Add(cost1, decreaseGas)
 			cost1.Mul(cost1, gasPrice1)
-			cost0 := util.Quote(cost1, reserve1, reserve0)
-			autohedge.Cost0.Add(autohedge.Cost0, cost0)
+			cost0 := native0(cost1, reserve0, reserve1)
+			autohedge.Cost0 = checked(autohedge.Cost0.Add(cost0))
+
+			keeper0 := token0.Amount(autoKeeper.Fee(cost0.Int(), checked(util.Quote(delta1, reserve1, reserve0)).Int()))
+			autohedge.Cost0 = checked(autohedge.Cost0.Add(keeper0))
+
+			mev0 := extract(timestamp, "autohedge", "rehedge", autohedge.Size, out0, true, reserve0, reserve1)
+			autohedge.Cost0 = checked(autohedge.Cost0.Add(mev0))
 
-			autohedge.Liquidity = big.NewInt(0).Mul(position0, position1)
+			autohedge.Liquidity = big.NewInt(0).Mul(position0.Int(), position1.Int())
 			autohedge.Liquidity.Sqrt(autohedge.Liquidity)
 
 			autohedge.Count++
 
 			log.Debug().
-				Float64("position0", b.ToFloat(position0, 6)).
-				Float64("position1", b.ToFloat(position1, 18)).
-				Float64("delta1", b.ToFloat(delta1, 18)).
-				Float64("out1", b.ToFloat(out1, 18)).
-				Float64("out0", b.ToFloat(out0, 6)).
-				Float64("liquidity", b.ToFloat(autohedge.Liquidity, 12)).
-				Float64("debt1", b.ToFloat(autohedge.Debt1, 18)).
-				Float64("fees0", b.ToFloat(autohedge.Fees0, 6)).
-				Float64("cost0", b.ToFloat(autohedge.Cost0, 6)).
+				Float64("position0", position0.Float()).
+				Float64("position1", position1.Float()).
+				Float64("delta1", delta1.Float()).
+				Float64("out1", out1.Float()).
+				Float64("out0", out0.Float()).
+				Float64("liquidity", pair.Liquidity(autohedge.Liquidity)).
+				Float64("debt1", autohedge.Debt1.Float()).
+				Float64("fees0", autohedge.Fees0.Float()).
+				Float64("cost0", autohedge.Cost0.Float()).
 				Uint("count", autohedge.Count).
 				Msg("decreased debt to rehedge autoswap position")
 
-		case position1.Cmp(bigger1) > 0:
+		case -1:
 
-			delta1 := big.NewInt(0).Sub(position1, debt1)
+			delta1 := close1.Neg()
 
-			rateMulti := big.NewInt(0).Sub(b.E3, swapRate)
-			in1 := big.NewInt(0).Mul(delta1, rateMulti)
-			in1.Div(in1, b.E3)
-			position1.Add(position1, in1)
+			in1 := checked(delta1.MulBps(swapRate.Complement(), b.RoundDown))
+			position1 = checked(position1.Add(in1))
 
-			in0 := util.Quote(in1, reserve1, reserve0)
-			position0.Add(position0, in0)
+			in0 := checked(util.Quote(in1, reserve1, reserve0))
+			position0 = checked(position0.Add(in0))
 
-			fee1 := big.NewInt(0).Sub(delta1, in1)
-			fee0 := util.Quote(fee1, reserve1, reserve0)
-			autohedge.Fees0.Add(autohedge.Fees0, fee0)
+			fee1 := checked(delta1.Sub(in1))
+			fee0 := checked(util.Quote(fee1, reserve1, reserve0))
+			autohedge.Fees0 = checked(autohedge.Fees0.Add(fee0))
 
-			autohedge.Debt1.Add(autohedge.Debt1, in1)
-			autohedge.Debt1.Add(autohedge.Debt1, in1)
-			autohedge.Debt1.Add(autohedge.Debt1, fee1)
+			autohedge.Debt1 = checked(autohedge.Debt1.Add(in1))
+			autohedge.Debt1 = checked(autohedge.Debt1.Add(in1))
+			autohedge.Debt1 = checked(autohedge.Debt1.Add(fee1))
 
 			cost1 := big.NewInt(0).Add(increaseGas, swapGas)
 			cost1.Add(cost1, addGas)
 			cost1.Mul(cost1, gasPrice1)
-			cost0 := util.Quote(cost1, reserve1, reserve0)
-			autohedge.Cost0.Add(autohedge.Cost0, cost0)
+			cost0 := native0(cost1, reserve0, reserve1)
+			autohedge.Cost0 = checked(autohedge.Cost0.Add(cost0))
 
-			autohedge.Liquidity = big.NewInt(0).Mul(position0, position1)
+			keeper0 := token0.Amount(autoKeeper.Fee(cost0.Int(), checked(util.Quote(delta1, reserve1, reserve0)).Int()))
+			autohedge.Cost0 = checked(autohedge.Cost0.Add(keeper0))
+
+			mev0 := extract(timestamp, "autohedge", "rehedge", autohedge.Size, delta1, false, reserve0, reserve1)
+			autohedge.Cost0 = checked(autohedge.Cost0.Add(mev0))
+
+			autohedge.Liquidity = big.NewInt(0).Mul(position0.Int(), position1.Int())
 			autohedge.Liquidity.Sqrt(autohedge.Liquidity)
 
 			autohedge.Count++
 
 			log.Debug().
-				Float64("position0", b.ToFloat(position0, 6)).
-				Float64("position1", b.ToFloat(position1, 18)).
-				Float64("delta1", b.ToFloat(delta1, 18)).
-				Float64("in1", b.ToFloat(in1, 18)).
-				Float64("in0", b.ToFloat(in0, 6)).
-				Float64("liquidity", b.ToFloat(autohedge.Liquidity, 12)).
-				Float64("debt1", b.ToFloat(autohedge.Debt1, 18)).
-				Float64("fees0", b.ToFloat(autohedge.Fees0, 6)).
-				Float64("cost0", b.ToFloat(autohedge.Cost0, 6)).
+				Float64("position0", position0.Float()).
+				Float64("position1", position1.Float()).
+				Float64("delta1", delta1.Float()).
+				Float64("in1", in1.Float()).
+				Float64("in0", in0.Float()).
+				Float64("liquidity", pair.Liquidity(autohedge.Liquidity)).
+				Float64("debt1", autohedge.Debt1.Float()).
+				Float64("fees0", autohedge.Fees0.Float()).
+				Float64("cost0", autohedge.Cost0.Float()).
 				Uint("count", autohedge.Count).
 				Msg("increased debt to rehedge autoswap position")
 		}
 
+		if perpHedge != nil {
+
+			profitPerp0, profitPerp1, err := position.Fees(volume0, volume1, swapRate, liquidity, perpHedge.Liquidity)
+			if err != nil {
+				log.Fatal().Err(err).Msg("could not compute perp hedge fees")
+			}
+			perpHedge.Pending0 = checked(perpHedge.Pending0.Add(profitPerp0))
+			perpHedge.Pending1 = checked(perpHedge.Pending1.Add(profitPerp1))
+
+			perpHedge.Profit0 = checked(perpHedge.Profit0.Add(profitPerp0))
+			perpHedge.Profit0 = checked(perpHedge.Profit0.Add(checked(util.Quote(profitPerp1, reserve1, reserve0))))
+
+			pendingPerp0 := checked(perpHedge.Unharvested0(reserve0, reserve1))
+			harvestPerp, err := perpCompounder.Harvest(timestamp, pendingPerp0)
+			if err != nil {
+				log.Fatal().Err(err).Msg("could not check perp hedge harvest")
+			}
+			if harvestPerp {
+
+				err = perpHedge.Compound(reserve0, reserve1)
+				if err != nil {
+					log.Fatal().Err(err).Msg("could not compound perp hedge fees")
+				}
+
+				if perpCompounder.Costly() {
+					cost1 := big.NewInt(0).Mul(compoundGas, gasPrice1)
+					cost0 := native0(cost1, reserve0, reserve1)
+					perpHedge.Cost0 = checked(perpHedge.Cost0.Add(cost0))
+					perpHedge.Harvests++
+				}
+			}
+
+			fundingRate := fund.Rate(last)
+			funding0 := checked(perpHedge.Fund(fundingRate, elapsed, reserve0, reserve1))
+
+			log.Debug().
+				Float64("profit0", profitPerp0.Float()).
+				Float64("profit1", profitPerp1.Float()).
+				Float64("funding0", funding0.Float()).
+				Float64("margin0", perpHedge.Margin0.Float()).
+				Float64("equity0", checked(perpHedge.Equity0(reserve0, reserve1)).Float()).
+				Msg("added profit and funding to perp hedge position")
+
+			liquidated, err := perpHedge.Liquidate(perpMaintenance, reserve0, reserve1)
+			if err != nil {
+				log.Fatal().Err(err).Msg("could not check perp hedge liquidation")
+			}
+			if liquidated {
+				log.Warn().
+					Uint("liquidations", perpHedge.Liquidations).
+					Msg("liquidated short of perp hedge position")
+			}
+
+			held0 := big.NewInt(0).Mul(perpHedge.Liquidity, sqrtReserve0)
+			perp0 := token0.Amount(held0.Div(held0, sqrtReserve1))
+			perp1 := checked(util.Quote(perp0, reserve0, reserve1))
+
+			perpGas1 := big.NewInt(0).Mul(perpGas, gasPrice1)
+			perpConditions := position.Conditions{
+				Reserve0:   reserve0,
+				Reserve1:   reserve1,
+				Volatility: volatility,
+				Cost0:      native0(perpGas1, reserve0, reserve1),
+			}
+
+			// Once liquidated, the position stays unhedged, as there is no
+			// margin left to open a new short position with.
+			close1 := zero1
+			if perpHedge.Liquidations == 0 {
+				close1 = checked(perpTrigger.Check(timestamp, perp1, perpHedge.Short1, perpConditions))
+				if perpKeeper.Ready(timestamp, close1.Sign() != 0) {
+					close1 = checked(perpTrigger.Gap(perp1, perpHedge.Short1))
+				} else {
+					close1 = zero1
+				}
+			}
+
+			if close1.Sign() != 0 {
+
+				target1 := checked(perpHedge.Short1.Sub(close1))
+				delta1 := checked(perpHedge.Adjust(target1, perpFeeRate, reserve0, reserve1))
+
+				cost1 := big.NewInt(0).Mul(perpGas, gasPrice1)
+				cost0 := native0(cost1, reserve0, reserve1)
+				perpHedge.Cost0 = checked(perpHedge.Cost0.Add(cost0))
+
+				keeper0 := token0.Amount(perpKeeper.Fee(cost0.Int(), checked(util.Quote(delta1.Abs(), reserve1, reserve0)).Int()))
+				perpHedge.Cost0 = checked(perpHedge.Cost0.Add(keeper0))
+
+				perpHedge.Count++
+
+				log.Debug().
+					Float64("position1", perp1.Float()).
+					Float64("delta1", delta1.Float()).
+					Float64("short1", perpHedge.Short1.Float()).
+					Float64("margin0", perpHedge.Margin0.Float()).
+					Float64("fees0", perpHedge.Fees0.Float()).
+					Float64("cost0", perpHedge.Cost0.Float()).
+					Uint("count", perpHedge.Count).
+					Msg("adjusted short to rehedge perp hedge position")
+			}
+		}
+
+		if options != nil {
+
+			profitOptions0, profitOptions1, err := position.Fees(volume0, volume1, swapRate, liquidity, options.Liquidity)
+			if err != nil {
+				log.Fatal().Err(err).Msg("could not compute options hedge fees")
+			}
+			options.Pending0 = checked(options.Pending0.Add(profitOptions0))
+			options.Pending1 = checked(options.Pending1.Add(profitOptions1))
+
+			options.Profit0 = checked(options.Profit0.Add(profitOptions0))
+			options.Profit0 = checked(options.Profit0.Add(checked(util.Quote(profitOptions1, reserve1, reserve0))))
+
+			pendingOptions0 := checked(options.Unharvested0(reserve0, reserve1))
+			harvestOptions, err := optionsCompounder.Harvest(timestamp, pendingOptions0)
+			if err != nil {
+				log.Fatal().Err(err).Msg("could not check options hedge harvest")
+			}
+			if harvestOptions {
+
+				err = options.Compound(reserve0, reserve1)
+				if err != nil {
+					log.Fatal().Err(err).Msg("could not compound options hedge fees")
+				}
+
+				if optionsCompounder.Costly() {
+					cost1 := big.NewInt(0).Mul(compoundGas, gasPrice1)
+					cost0 := native0(cost1, reserve0, reserve1)
+					options.Cost0 = checked(options.Cost0.Add(cost0))
+					options.Harvests++
+				}
+			}
+
+			switch {
+
+			case !timestamp.Before(options.Expiry):
+
+				payoff0 := checked(options.Settle(reserve0, reserve1))
+
+				log.Debug().
+					Float64("payoff0", payoff0.Float()).
+					Float64("total0", options.Payoff0.Float()).
+					Msg("settled options of options hedge position")
+
+				rollOptions(timestamp, reserve0, reserve1)
+
+				// Settling the expired options and buying new ones are
+				// separate transactions.
+				cost1 := big.NewInt(0).Mul(optionGas, b.D2)
+				cost1.Mul(cost1, gasPrice1)
+				cost0 := native0(cost1, reserve0, reserve1)
+				options.Cost0 = checked(options.Cost0.Add(cost0))
+
+			default:
+
+				price0 := optionPrice(timestamp, options.Strike0, options.Expiry, reserve0, reserve1)
+				err = options.Mark(price0)
+				if err != nil {
+					log.Fatal().Err(err).Msg("could not mark options")
+				}
+			}
+		}
+
 		if writeResults {
-			write.HoldPoint(timestamp, reserve0, reserve1, hold, outbound)
-			write.UniswapPoint(timestamp, reserve0, reserve1, uniswap, outbound)
-			write.AutohedgePoint(timestamp, reserve0, reserve1, autohedge, outbound)
+			points(timestamp, reserve0, reserve1)
+		}
+
+		if options != nil {
+			log.Info().
+				Float64("options", checked(options.Value0(reserve0, reserve1)).Float()).
+				Uint("rolls", options.Rolls).
+				Msg("options hedge value updated")
+		}
+
+		if perpHedge != nil {
+			log.Info().
+				Float64("perphedge", checked(perpHedge.Value0(reserve0, reserve1)).Float()).
+				Uint("count", perpHedge.Count).
+				Uint("liquidations", perpHedge.Liquidations).
+				Uint("keeper_failures", perpKeeper.Failures).
+				Float64("keeper_fees", token0.Float(perpKeeper.Fees0)).
+				Msg("perp hedge value updated")
 		}
 
 		log.Info().
-			Float64("hold", b.ToFloat(hold.Value0(reserve0, reserve1), 6)).
-			Float64("uniswap", b.ToFloat(uniswap.Value0(reserve0, reserve1), 6)).
-			Float64("autohedge", b.ToFloat(autohedge.Value0(reserve0, reserve1), 6)).
+			Float64("hold", checked(hold.Value0(reserve0, reserve1)).Float()).
+			Float64("uniswap", checked(uniswap.Value0(reserve0, reserve1)).Float()).
+			Float64("autohedge", checked(autohedge.Value0(reserve0, reserve1)).Float()).
 			Uint("count", autohedge.Count).
+			Uint("harvests", autohedge.Harvests).
+			Uint("keeper_failures", autoKeeper.Failures).
+			Float64("keeper_fees", token0.Float(autoKeeper.Fees0)).
+			Float64("dca", checked(dca.Value0(reserve0, reserve1)).Float()).
+			Float64("rebalance", checked(rebalance.Value0(reserve0, reserve1)).Float()).
+			Float64("lend", checked(lend.Value0(reserve0, reserve1)).Float()).
 			Msg("position values updated")
+
+		observe(timestamp, reserve0, reserve1, lendRate)
+		attribute(timestamp, reserve0, reserve1)
+
+		for len(exitTimes) > 0 && !timestamp.Before(exitTimes[0]) {
+			exit(timestamp, reserve0, reserve1, exitPrice1)
+			exitTimes = exitTimes[1:]
+		}
 	}
 
-	err = result.Err()
+	err = data.Err()
 	if err != nil {
 		log.Fatal().Err(err).Msg("could not finish streaming records")
 	}
 
-	os.Exit(0)
+	log.Info().
+		Str("policy", quality.Policy).
+		Uint("skipped", quality.Skipped).
+		Uint("carried", quality.Carried).
+		AnErr("first", quality.First).
+		Msg("data quality of records")
+
+	if len(exitTimes) > 0 {
+		log.Warn().
+			Times("exit_times", exitTimes).
+			Time("last", timestamp).
+			Msg("exit times after the last record are covered by the final exit")
+	}
+
+	exits := exit(timestamp, reserve0, reserve1, exitPrice1)
+
+	summaries := make([]analytics.Summary, 0, len(strategies))
+	for _, s := range strategies {
+		summary := trackers[s.Name].Summarize(s.Fees0().Float(), s.Cost0().Float(), s.actions())
+		summaries = append(summaries, summary)
+		if writeResults {
+			write.SummaryPoint(timestamp, s.Size, summary, pair, outbound)
+		}
+	}
+
+	tabulate(summaries)
+
+	comparisons := make([]analytics.Comparison, 0, len(relatives))
+	for _, s := range strategies {
+		relative, ok := relatives[s.Name]
+		if ok {
+			comparisons = append(comparisons, relative.Summarize())
+		}
+	}
+	if len(comparisons) > 0 {
+		compare(comparisons)
+	}
+
+	breakdowns := make([]attribution.Attribution, 0, len(attributed))
+	for _, s := range attributed {
+		breakdowns = append(breakdowns, *attributions[s.Name])
+	}
+	decompose(breakdowns, token0)
+
+	if record != nil {
+		if skipped != nil {
+			record.Points = append(record.Points, *skipped)
+		}
+		record.Summaries = summaries
+		record.Comparisons = comparisons
+		err = record.Write(archiveFile)
+		if err != nil {
+			log.Fatal().Err(err).Str("archive_file", archiveFile).Msg("could not write archive")
+		}
+	}
+
+	if summaryJSON != "" || summaryMarkdown != "" {
+
+		// Flags that only choose where outputs go don't change the results,
+		// and would show up as noise when comparing summaries. The requested
+		// time range is replaced by the range of the records that were used.
+		settings := parameters(pflag.CommandLine)
+		for _, name := range []string{"log-level", "write-results", "archive-file", "archive-step", "summary-json", "summary-markdown", "start-time", "end-time"} {
+			delete(settings, name)
+		}
+
+		summary := artifact.Artifact{
+			Chain:      chainName,
+			Pair:       pairName,
+			Parameters: settings,
+			Data: artifact.Data{
+				Source:      sourceName,
+				Start:       first,
+				End:         timestamp,
+				Records:     data.Records,
+				Fingerprint: data.Sum(),
+			},
+			Strategies:  make([]artifact.Strategy, 0, len(summaries)),
+			Comparisons: comparisons,
+		}
+		for _, s := range summaries {
+			summary.Strategies = append(summary.Strategies, artifact.Strategy{
+				Summary:    s,
+				Realizable: checked(exits[s.Strategy].Realizable0()).Float(),
+			})
+		}
+		if summaryJSON != "" {
+			err = summary.WriteJSON(summaryJSON)
+			if err != nil {
+				log.Fatal().Err(err).Str("summary_json", summaryJSON).Msg("could not write summary")
+			}
+		}
+		if summaryMarkdown != "" {
+			err = summary.WriteMarkdown(summaryMarkdown)
+			if err != nil {
+				log.Fatal().Err(err).Str("summary_markdown", summaryMarkdown).Msg("could not write summary")
+			}
+		}
+	}
+
+	outbound.Flush()
+	client.Close()
+
+	r := result{
+		Input0:      input0,
+		Exits:       exits,
+		Summaries:   summaries,
+		Comparisons: comparisons,
+	}
+
+	return r
+}
+
+// parameters returns the values of all flags of the run, except for secrets,
+// so that they can be stored with its outputs.
+func parameters(flags *pflag.FlagSet) map[string]string {
+
+	values := make(map[string]string)
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if flag.Name == "influx-token" {
+			return
+		}
+		values[flag.Name] = flag.Value.String()
+	})
+
+	return values
 }
@@ -0,0 +1,157 @@
+package mev
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/optakt/wilhelmus/b"
+	"github.com/optakt/wilhelmus/util"
+)
+
+// Sandwich models the sandwich attacks run by searchers against swaps that are
+// sent through the public mempool. The attacker front-runs the swap with as
+// large a swap in the same direction as the slippage tolerance of the swap
+// allows, and back-runs it by swapping back, so that the swap gets the worst
+// price it still accepts.
+type Sandwich struct {
+	Tolerance b.Bps // slippage tolerance of swaps
+	SwapRate  b.Bps // swap fee of the pair
+}
+
+func NewSandwich(tolerance b.Bps, swapRate b.Bps) (*Sandwich, error) {
+
+	if !tolerance.Valid() {
+		return nil, fmt.Errorf("slippage tolerance must be at least zero and below one (tolerance: %f)", tolerance.Float())
+	}
+
+	s := Sandwich{
+		Tolerance: tolerance,
+		SwapRate:  swapRate,
+	}
+
+	return &s, nil
+}
+
+// Extract returns the size of the front-running swap and the amount of output
+// tokens that the given swap loses to the sandwich attack. It is an upper
+// bound, as it ignores the gas cost of the attacker; if the attack does not
+// pay for its own swap fees, it is not run and nothing is lost.
+func (s *Sandwich) Extract(amountIn b.Amount, reserveIn b.Amount, reserveOut b.Amount) (b.Amount, b.Amount, error) {
+
+	none := b.NewAmount(b.D0, amountIn.Decimals())
+	zero := b.NewAmount(b.D0, reserveOut.Decimals())
+
+	expected, err := util.CalculateAmountOut(amountIn, reserveIn, reserveOut, s.SwapRate)
+	if err != nil {
+		return b.Amount{}, b.Amount{}, fmt.Errorf("could not compute expected output: %w", err)
+	}
+
+	minimum, err := expected.MulBps(s.Tolerance.Complement(), b.RoundUp)
+	if err != nil {
+		return b.Amount{}, b.Amount{}, fmt.Errorf("could not compute minimum output: %w", err)
+	}
+	if minimum.Sign() == 0 {
+		return none, zero, nil
+	}
+
+	// The output of the swap decreases with the size of the front-run, so we
+	// double the size until the swap would revert, and then search for the
+	// largest front-run that still lets it through.
+	accepts := func(front *big.Int) (bool, error) {
+		out, err := s.victim(b.NewAmount(front, amountIn.Decimals()), amountIn, reserveIn, reserveOut)
+		if err != nil {
+			return false, err
+		}
+		cmp, err := out.Cmp(minimum)
+		if err != nil {
+			return false, err
+		}
+		return cmp >= 0, nil
+	}
+	low := big.NewInt(0)
+	high := reserveIn.Int()
+	for {
+		ok, err := accepts(high)
+		if err != nil {
+			return b.Amount{}, b.Amount{}, fmt.Errorf("could not bound front-run: %w", err)
+		}
+		if !ok {
+			break
+		}
+		low.Set(high)
+		high.Lsh(high, 1)
+	}
+	for big.NewInt(0).Sub(high, low).Cmp(b.D1) > 0 {
+		middle := big.NewInt(0).Add(low, high)
+		middle.Rsh(middle, 1)
+		ok, err := accepts(middle)
+		if err != nil {
+			return b.Amount{}, b.Amount{}, fmt.Errorf("could not search front-run: %w", err)
+		}
+		if ok {
+			low = middle
+		} else {
+			high = middle
+		}
+	}
+	front := b.NewAmount(low, amountIn.Decimals())
+
+	// The attacker buys with the front-run, the swap moves the price further,
+	// and the attacker sells everything back.
+	bought, err := util.CalculateAmountOut(front, reserveIn, reserveOut, s.SwapRate)
+	if err != nil {
+		return b.Amount{}, b.Amount{}, fmt.Errorf("could not compute front-run output: %w", err)
+	}
+	out, err := s.victim(front, amountIn, reserveIn, reserveOut)
+	if err != nil {
+		return b.Amount{}, b.Amount{}, fmt.Errorf("could not compute victim output: %w", err)
+	}
+
+	afterIn, err := b.Sum(reserveIn, front, amountIn)
+	if err != nil {
+		return b.Amount{}, b.Amount{}, fmt.Errorf("could not compute input reserve: %w", err)
+	}
+	afterOut, err := b.Sum(reserveOut, bought.Neg(), out.Neg())
+	if err != nil {
+		return b.Amount{}, b.Amount{}, fmt.Errorf("could not compute output reserve: %w", err)
+	}
+
+	back, err := util.CalculateAmountOut(bought, afterOut, afterIn, s.SwapRate)
+	if err != nil {
+		return b.Amount{}, b.Amount{}, fmt.Errorf("could not compute back-run output: %w", err)
+	}
+	cmp, err := back.Cmp(front)
+	if err != nil {
+		return b.Amount{}, b.Amount{}, err
+	}
+	if cmp <= 0 {
+		return none, zero, nil
+	}
+
+	loss, err := expected.Sub(out)
+	if err != nil {
+		return b.Amount{}, b.Amount{}, fmt.Errorf("could not compute loss: %w", err)
+	}
+
+	return front, loss, nil
+}
+
+// victim returns the output of the swap after it was front-run.
+func (s *Sandwich) victim(front b.Amount, amountIn b.Amount, reserveIn b.Amount, reserveOut b.Amount) (b.Amount, error) {
+
+	bought, err := util.CalculateAmountOut(front, reserveIn, reserveOut, s.SwapRate)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	afterIn, err := reserveIn.Add(front)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	afterOut, err := reserveOut.Sub(bought)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	return util.CalculateAmountOut(amountIn, afterIn, afterOut, s.SwapRate)
+}
@@ -0,0 +1,58 @@
+package option
+
+import (
+	"math"
+)
+
+// Kinds of options that can be bought to hedge a liquidity position.
+const (
+	Put      = "put"
+	Straddle = "straddle"
+)
+
+// Premium returns the Black-Scholes price of one option of the given kind on
+// one unit of the underlying, given the spot and strike prices, the time to
+// expiry in years, the annualized volatility and the risk-free rate.
+func Premium(kind string, spot float64, strike float64, years float64, volatility float64, rate float64) float64 {
+
+	// At or after expiry, or without any volatility, the option is only
+	// worth its discounted intrinsic value.
+	if years <= 0 || volatility <= 0 {
+		discount := math.Exp(-rate * math.Max(years, 0))
+		return Payoff(kind, spot, strike*discount)
+	}
+
+	deviation := volatility * math.Sqrt(years)
+	d1 := (math.Log(spot/strike) + (rate+volatility*volatility/2)*years) / deviation
+	d2 := d1 - deviation
+
+	discounted := strike * math.Exp(-rate*years)
+
+	put := discounted*normal(-d2) - spot*normal(-d1)
+	if kind == Put {
+		return put
+	}
+
+	call := spot*normal(d1) - discounted*normal(d2)
+
+	return put + call
+}
+
+// Payoff returns the value of one option of the given kind on one unit of the
+// underlying at expiry.
+func Payoff(kind string, spot float64, strike float64) float64 {
+
+	put := math.Max(strike-spot, 0)
+	if kind == Put {
+		return put
+	}
+
+	call := math.Max(spot-strike, 0)
+
+	return put + call
+}
+
+// normal is the cumulative distribution function of the standard normal distribution.
+func normal(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
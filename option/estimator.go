@@ -0,0 +1,11 @@
+package option
+
+import (
+	"time"
+)
+
+// Estimator provides the annualized volatility used to price options.
+type Estimator interface {
+	Observe(timestamp time.Time, spot float64)
+	Volatility(timestamp time.Time) float64
+}
@@ -0,0 +1,80 @@
+package option
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Implied provides volatility from a historical series of implied volatility,
+// given as annualized decimal values.
+type Implied struct {
+	starts     []time.Time
+	volatility []float64
+}
+
+func NewImplied(file string) (*Implied, error) {
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read implied volatility file: %w", err)
+	}
+
+	csvr := csv.NewReader(bytes.NewReader(data))
+	csvr.FieldsPerRecord = 2
+	records, err := csvr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("could not read implied volatility records: %w", err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("implied volatility has no entries")
+	}
+
+	entries := records[1:]
+	sort.Slice(entries, func(i int, j int) bool {
+		return entries[i][0] < entries[j][0]
+	})
+
+	i := Implied{
+		starts:     make([]time.Time, 0, len(entries)),
+		volatility: make([]float64, 0, len(entries)),
+	}
+	for _, entry := range entries {
+
+		start, err := time.Parse(time.RFC3339, entry[0])
+		if err != nil {
+			return nil, fmt.Errorf("could not parse implied volatility time: %w", err)
+		}
+
+		volatility, err := strconv.ParseFloat(entry[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse implied volatility value: %w", err)
+		}
+
+		i.starts = append(i.starts, start)
+		i.volatility = append(i.volatility, volatility)
+	}
+
+	return &i, nil
+}
+
+// Observe is a no-op, as implied volatility does not depend on the spot price.
+func (i *Implied) Observe(timestamp time.Time, spot float64) {}
+
+// Volatility returns the implied volatility that applies at the given
+// timestamp, which is the first value of the series before its start.
+func (i *Implied) Volatility(timestamp time.Time) float64 {
+
+	index := sort.Search(len(i.starts), func(j int) bool {
+		return i.starts[j].After(timestamp)
+	})
+	if index == 0 {
+		return i.volatility[0]
+	}
+
+	return i.volatility[index-1]
+}
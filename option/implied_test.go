@@ -0,0 +1,35 @@
+package option
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewImpliedMalformed(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "single column", content: "time\n2022-01-01T00:00:00Z\n"},
+		{name: "missing volatility", content: "time,volatility\n2022-01-01T00:00:00Z,0.8\n2022-01-02T00:00:00Z\n"},
+		{name: "extra column", content: "time,volatility\n2022-01-01T00:00:00Z,0.8,0.9\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			file := filepath.Join(t.TempDir(), "implied.csv")
+			err := os.WriteFile(file, []byte(test.content), 0644)
+			if err != nil {
+				t.Fatalf("could not write implied volatility file: %s", err)
+			}
+
+			_, err = NewImplied(file)
+			if err == nil {
+				t.Errorf("malformed implied volatility file was accepted")
+			}
+		})
+	}
+}
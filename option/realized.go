@@ -0,0 +1,69 @@
+package option
+
+import (
+	"math"
+	"time"
+)
+
+const year = 365 * 24 * time.Hour
+
+type observation struct {
+	timestamp time.Time
+	spot      float64
+}
+
+// Realized estimates volatility from the log returns of the spot price, as
+// implied by the reserve ratio of the pair, over a rolling window. Until there
+// are enough observations, it returns the given fallback volatility.
+type Realized struct {
+	window       time.Duration
+	fallback     float64
+	observations []observation
+}
+
+func NewRealized(window time.Duration, fallback float64) *Realized {
+
+	r := Realized{
+		window:       window,
+		fallback:     fallback,
+		observations: nil,
+	}
+
+	return &r
+}
+
+func (r *Realized) Observe(timestamp time.Time, spot float64) {
+
+	r.observations = append(r.observations, observation{timestamp: timestamp, spot: spot})
+
+	cutoff := timestamp.Add(-r.window)
+	index := 0
+	for index < len(r.observations)-1 && r.observations[index].timestamp.Before(cutoff) {
+		index++
+	}
+	r.observations = r.observations[index:]
+}
+
+func (r *Realized) Volatility(timestamp time.Time) float64 {
+
+	if len(r.observations) < 2 {
+		return r.fallback
+	}
+
+	// We annualize the sum of squared log returns using the time actually
+	// covered by the observations, so irregular intervals are accounted for.
+	variance := 0.0
+	for i := 1; i < len(r.observations); i++ {
+		ret := math.Log(r.observations[i].spot / r.observations[i-1].spot)
+		variance += ret * ret
+	}
+
+	first := r.observations[0].timestamp
+	last := r.observations[len(r.observations)-1].timestamp
+	years := float64(last.Sub(first)) / float64(year)
+	if years <= 0 {
+		return r.fallback
+	}
+
+	return math.Sqrt(variance / years)
+}
@@ -9,36 +9,135 @@ import (
 
 type Autohedge struct {
 	Size       uint64
-	Rehedge    *big.Int
+	Rehedge    b.Bps
 	Liquidity  *big.Int
-	Principal0 *big.Int
-	Debt1      *big.Int
-	Yield0     *big.Int
-	Interest1  *big.Int
-	Fees0      *big.Int
-	Cost0      *big.Int
-	Profit0    *big.Int
+	Pending0   b.Amount
+	Pending1   b.Amount
+	Principal0 b.Amount
+	Debt1      b.Amount
+	Yield0     b.Amount
+	Interest1  b.Amount
+	Fees0      b.Amount
+	Cost0      b.Amount
+	Profit0    b.Amount
+	Rewards    Rewards
 	Count      uint
+	Harvests   uint
 }
 
-func (a *Autohedge) Value0(reserve0 *big.Int, reserve1 *big.Int) *big.Int {
+func (a *Autohedge) Value0(reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) {
 
-	sqrtReserve0 := big.NewInt(0).Sqrt(reserve0)
-	sqrtReserve1 := big.NewInt(0).Sqrt(reserve1)
+	pooled0, err := pooled(a.Liquidity, a.Pending0, a.Pending1, a.Rewards, reserve0, reserve1)
+	if err != nil {
+		return b.Amount{}, err
+	}
 
-	value0 := big.NewInt(0).Mul(a.Liquidity, sqrtReserve0)
-	value0.Div(value0, sqrtReserve1)
-	value0.Mul(value0, b.D2)
+	debt0, err := util.Quote(a.Debt1, reserve1, reserve0)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	interest0, err := util.Quote(a.Interest1, reserve1, reserve0)
+	if err != nil {
+		return b.Amount{}, err
+	}
 
-	debt0 := util.Quote(a.Debt1, reserve1, reserve0)
-	interest0 := util.Quote(a.Interest1, reserve1, reserve0)
+	return b.Sum(pooled0, debt0.Neg(), interest0.Neg(), a.Yield0, a.Fees0.Neg(), a.Cost0.Neg())
+}
+
+func (a *Autohedge) Exit(reserve0 b.Amount, reserve1 b.Amount, swapRate b.Bps, cost0 b.Amount) (Exit, error) {
+
+	amount0, amount1, err := underlying(a.Liquidity, reserve0, reserve1)
+	if err != nil {
+		return Exit{}, err
+	}
+
+	// Removing our liquidity makes the pair shallower for the swap back.
+	remaining0, err := reserve0.Sub(amount0)
+	if err != nil {
+		return Exit{}, err
+	}
+	remaining1, err := reserve1.Sub(amount1)
+	if err != nil {
+		return Exit{}, err
+	}
+
+	// Pending fees in token1 can be used to repay the debt as well.
+	amount1, err = amount1.Add(a.Pending1)
+	if err != nil {
+		return Exit{}, err
+	}
+
+	debt1, err := a.Debt1.Add(a.Interest1)
+	if err != nil {
+		return Exit{}, err
+	}
+
+	// We only have to swap the difference between the withdrawn liquidity and
+	// the debt; if we are short, we buy the missing part of the debt with the
+	// stable token, otherwise we sell the surplus for the stable token.
+	surplus1, err := amount1.Sub(debt1)
+	if err != nil {
+		return Exit{}, err
+	}
+
+	var fees0 b.Amount
+	switch {
+
+	case surplus1.Sign() < 0:
+
+		short1 := surplus1.Neg()
+		quote0, err := util.Quote(short1, remaining1, remaining0)
+		if err != nil {
+			return Exit{}, err
+		}
+		in0, err := util.CalculateAmountIn(short1, remaining0, remaining1, swapRate)
+		if err != nil {
+			return Exit{}, err
+		}
+		fees0, err = in0.Sub(quote0)
+		if err != nil {
+			return Exit{}, err
+		}
+
+	default:
+
+		fees0, err = swapFees(surplus1, remaining1, remaining0, swapRate)
+		if err != nil {
+			return Exit{}, err
+		}
+	}
+
+	value0, err := a.Value0(reserve0, reserve1)
+	if err != nil {
+		return Exit{}, err
+	}
+
+	exit := Exit{
+		Value0: value0,
+		Fees0:  fees0,
+		Cost0:  cost0,
+	}
+
+	return exit, nil
+}
+
+// Unharvested0 returns the value of the fees that were not yet added to the
+// liquidity of the position.
+func (a Autohedge) Unharvested0(reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) {
+	return unharvested(a.Pending0, a.Pending1, reserve0, reserve1)
+}
+
+// Compound adds the pending fees to the liquidity of the position.
+func (a *Autohedge) Compound(reserve0 b.Amount, reserve1 b.Amount) error {
 
-	value0.Sub(value0, debt0)
-	value0.Sub(value0, interest0)
-	value0.Add(value0, a.Yield0)
+	liquidity, err := compound(a.Liquidity, a.Pending0, a.Pending1, reserve0, reserve1)
+	if err != nil {
+		return err
+	}
 
-	value0.Sub(value0, a.Fees0)
-	value0.Sub(value0, a.Cost0)
+	a.Liquidity = liquidity
+	a.Pending0 = b.NewAmount(b.D0, reserve0.Decimals())
+	a.Pending1 = b.NewAmount(b.D0, reserve1.Decimals())
 
-	return value0
+	return nil
 }
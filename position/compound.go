@@ -0,0 +1,110 @@
+package position
+
+import (
+	"math/big"
+
+	"github.com/optakt/wilhelmus/b"
+	"github.com/optakt/wilhelmus/util"
+)
+
+// underlying returns the amounts of token0 and token1 backing the given
+// liquidity at the given reserves.
+func underlying(liquidity *big.Int, reserve0 b.Amount, reserve1 b.Amount) (b.Amount, b.Amount, error) {
+
+	sqrtReserve0 := big.NewInt(0).Sqrt(reserve0.Int())
+	sqrtReserve1 := big.NewInt(0).Sqrt(reserve1.Int())
+
+	value := big.NewInt(0).Mul(liquidity, sqrtReserve0)
+	value.Div(value, sqrtReserve1)
+	amount0 := b.NewAmount(value, reserve0.Decimals())
+
+	amount1, err := util.Quote(amount0, reserve0, reserve1)
+	if err != nil {
+		return b.Amount{}, b.Amount{}, err
+	}
+
+	return amount0, amount1, nil
+}
+
+// compound adds the given amounts of token0 and token1 to the amounts backing
+// the given liquidity and returns the resulting liquidity.
+func compound(liquidity *big.Int, add0 b.Amount, add1 b.Amount, reserve0 b.Amount, reserve1 b.Amount) (*big.Int, error) {
+
+	amount0, amount1, err := underlying(liquidity, reserve0, reserve1)
+	if err != nil {
+		return nil, err
+	}
+
+	amount0, err = amount0.Add(add0)
+	if err != nil {
+		return nil, err
+	}
+	amount1, err = amount1.Add(add1)
+	if err != nil {
+		return nil, err
+	}
+
+	compounded := big.NewInt(0).Mul(amount0.Int(), amount1.Int())
+	compounded.Sqrt(compounded)
+
+	return compounded, nil
+}
+
+// unharvested returns the value of the given pending fees in token0.
+func unharvested(pending0 b.Amount, pending1 b.Amount, reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) {
+
+	value0, err := util.Quote(pending1, reserve1, reserve0)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	return value0.Add(pending0)
+}
+
+// pooled returns the value in token0 of the given liquidity, its pending fees
+// and its rewards, which all liquidity positions hold in the same way.
+func pooled(liquidity *big.Int, pending0 b.Amount, pending1 b.Amount, rewards Rewards, reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) {
+
+	amount0, _, err := underlying(liquidity, reserve0, reserve1)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	unharvested0, err := unharvested(pending0, pending1, reserve0, reserve1)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	rewards0, err := rewards.Net0()
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	return b.Sum(amount0, amount0, unharvested0, rewards0)
+}
+
+// withdraw returns the swap fees and price impact of removing the given
+// liquidity from the pair and swapping its token1, together with the given
+// extra token1, back to token0.
+func withdraw(liquidity *big.Int, extra1 b.Amount, reserve0 b.Amount, reserve1 b.Amount, swapRate b.Bps) (b.Amount, error) {
+
+	amount0, amount1, err := underlying(liquidity, reserve0, reserve1)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	// Removing our liquidity makes the pair shallower for the swap back.
+	remaining0, err := reserve0.Sub(amount0)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	remaining1, err := reserve1.Sub(amount1)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	amount1, err = amount1.Add(extra1)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	return swapFees(amount1, remaining1, remaining0, swapRate)
+}
@@ -0,0 +1,93 @@
+package position
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/optakt/wilhelmus/b"
+)
+
+// Compounding policies for the fees accrued by liquidity positions.
+const (
+	CompoundContinuous = "continuous" // fees are part of the liquidity immediately, like on Uniswap v2
+	CompoundPeriodic   = "periodic"   // fees are harvested and added as liquidity at a fixed interval
+	CompoundThreshold  = "threshold"  // fees are harvested and added as liquidity once they reach a value
+	CompoundNever      = "never"      // fees are held on the side and never added as liquidity
+)
+
+// Compounder decides when the pending fees of a liquidity position are
+// harvested and added back to its liquidity.
+type Compounder struct {
+	Policy     string
+	Interval   time.Duration
+	Threshold0 b.Amount
+	Last       time.Time
+}
+
+func NewCompounder(policy string, interval time.Duration, threshold0 b.Amount, start time.Time) (*Compounder, error) {
+
+	switch policy {
+	case CompoundContinuous, CompoundNever:
+	case CompoundPeriodic:
+		if interval <= 0 {
+			return nil, fmt.Errorf("harvest interval must be positive for periodic compounding (interval: %s)", interval)
+		}
+	case CompoundThreshold:
+		if threshold0.Sign() <= 0 {
+			return nil, fmt.Errorf("harvest threshold must be positive for threshold compounding (threshold: %s)", threshold0)
+		}
+	default:
+		return nil, fmt.Errorf("unknown compounding policy (policy: %s)", policy)
+	}
+
+	c := Compounder{
+		Policy:     policy,
+		Interval:   interval,
+		Threshold0: threshold0,
+		Last:       start,
+	}
+
+	return &c, nil
+}
+
+// Harvest returns whether the pending fees, valued in token0, should be
+// harvested at the given timestamp.
+func (c *Compounder) Harvest(timestamp time.Time, pending0 b.Amount) (bool, error) {
+
+	if pending0.Sign() == 0 {
+		return false, nil
+	}
+
+	switch c.Policy {
+
+	case CompoundContinuous:
+		return true, nil
+
+	case CompoundPeriodic:
+		if timestamp.Sub(c.Last) < c.Interval {
+			return false, nil
+		}
+		c.Last = timestamp
+		return true, nil
+
+	case CompoundThreshold:
+		cmp, err := pending0.Cmp(c.Threshold0)
+		if err != nil {
+			return false, err
+		}
+		if cmp < 0 {
+			return false, nil
+		}
+		c.Last = timestamp
+		return true, nil
+
+	default:
+		return false, nil
+	}
+}
+
+// Costly returns whether harvesting requires a separate transaction that
+// costs gas, which is the case for every policy but continuous compounding.
+func (c *Compounder) Costly() bool {
+	return c.Policy != CompoundContinuous
+}
@@ -0,0 +1,113 @@
+package position
+
+import (
+	"github.com/optakt/wilhelmus/b"
+	"github.com/optakt/wilhelmus/util"
+)
+
+// DCA is a baseline position that keeps its input in the stable token and
+// buys token1 with it in equal tranches on a schedule.
+type DCA struct {
+	Size     uint64
+	Tranche0 b.Amount // amount of stable token spent on each buy
+	Amount0  b.Amount
+	Amount1  b.Amount
+	Fees0    b.Amount
+	Cost0    b.Amount
+	Buys     uint
+}
+
+func (d *DCA) Value0(reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) {
+
+	amount0, err := util.Quote(d.Amount1, reserve1, reserve0)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	return b.Sum(d.Amount0, amount0, d.Fees0.Neg(), d.Cost0.Neg())
+}
+
+// Next0 returns the amount of the stable token spent on the next buy. The
+// last tranche absorbs what is left after rounding the tranches down, so
+// that the dust isn't spent on a buy of its own.
+func (d *DCA) Next0() (b.Amount, error) {
+
+	last, err := d.Tranche0.Add(d.Tranche0)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	cmp, err := d.Amount0.Cmp(last)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	if cmp < 0 {
+		return d.Amount0, nil
+	}
+
+	return d.Tranche0, nil
+}
+
+// Buy swaps the next tranche of the stable token for token1 on the pair and
+// returns the amount of token1 received.
+func (d *DCA) Buy(reserve0 b.Amount, reserve1 b.Amount, swapRate b.Bps, cost0 b.Amount) (b.Amount, error) {
+
+	in0, err := d.Next0()
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	out1, err := util.CalculateAmountOut(in0, reserve0, reserve1, swapRate)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	quote0, err := util.Quote(out1, reserve1, reserve0)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	amount0, err := d.Amount0.Sub(in0)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	amount1, err := d.Amount1.Add(out1)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	fees0, err := b.Sum(d.Fees0, in0, quote0.Neg())
+	if err != nil {
+		return b.Amount{}, err
+	}
+	cost0, err = d.Cost0.Add(cost0)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	d.Amount0 = amount0
+	d.Amount1 = amount1
+	d.Fees0 = fees0
+	d.Cost0 = cost0
+	d.Buys++
+
+	return out1, nil
+}
+
+func (d *DCA) Exit(reserve0 b.Amount, reserve1 b.Amount, swapRate b.Bps, cost0 b.Amount) (Exit, error) {
+
+	fees0, err := swapFees(d.Amount1, reserve1, reserve0, swapRate)
+	if err != nil {
+		return Exit{}, err
+	}
+
+	value0, err := d.Value0(reserve0, reserve1)
+	if err != nil {
+		return Exit{}, err
+	}
+
+	exit := Exit{
+		Value0: value0,
+		Fees0:  fees0,
+		Cost0:  cost0,
+	}
+
+	return exit, nil
+}
@@ -0,0 +1,55 @@
+package position
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/optakt/wilhelmus/b"
+)
+
+func TestDCABuys(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		input0   int64
+		tranches int64
+	}{
+		{name: "remainder", input0: 1_000_000_000_000, tranches: 52},
+		{name: "even", input0: 1_000_000_000_000, tranches: 50},
+		{name: "single tranche", input0: 1_000_000_000_000, tranches: 1},
+		{name: "remainder of almost a tranche", input0: 1_000_000_000_051, tranches: 52},
+	}
+
+	value0, _ := big.NewInt(0).SetString("54963236190374", 10)
+	value1, _ := big.NewInt(0).SetString("30437815372104693858232", 10)
+	reserve0 := b.NewAmount(value0, 6)
+	reserve1 := b.NewAmount(value1, 18)
+	zero0 := b.NewAmount(b.D0, 6)
+	rate := b.NewBps(big.NewInt(30))
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			d := DCA{
+				Tranche0: b.NewAmount(big.NewInt(test.input0/test.tranches), 6),
+				Amount0:  b.NewAmount(big.NewInt(test.input0), 6),
+				Amount1:  b.NewAmount(b.D0, 18),
+				Fees0:    zero0,
+				Cost0:    zero0,
+			}
+			for d.Amount0.Sign() > 0 && d.Buys <= uint(test.tranches) {
+				_, err := d.Buy(reserve0, reserve1, rate, zero0)
+				if err != nil {
+					t.Fatalf("could not buy tranche: %s", err)
+				}
+			}
+
+			if d.Buys != uint(test.tranches) {
+				t.Errorf("wrong number of buys (have: %d, want: %d)", d.Buys, test.tranches)
+			}
+			if d.Amount0.Sign() != 0 {
+				t.Errorf("input left after the last buy (amount0: %s)", d.Amount0)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package position
+
+import (
+	"github.com/optakt/wilhelmus/b"
+)
+
+// Deviate checks whether the given amount left the band around the target
+// that is defined by the given ratio. It returns -1 if the amount is below
+// the band, 1 if it is above the band and 0 otherwise.
+func Deviate(amount b.Amount, target b.Amount, ratio b.Bps) (int, error) {
+
+	diff, err := target.MulBps(ratio, b.RoundDown)
+	if err != nil {
+		return 0, err
+	}
+
+	smaller, err := target.Sub(diff)
+	if err != nil {
+		return 0, err
+	}
+	bigger, err := target.Add(diff)
+	if err != nil {
+		return 0, err
+	}
+
+	below, err := amount.Cmp(smaller)
+	if err != nil {
+		return 0, err
+	}
+	above, err := amount.Cmp(bigger)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case below < 0:
+		return -1, nil
+	case above > 0:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
@@ -0,0 +1,19 @@
+package position
+
+import (
+	"github.com/optakt/wilhelmus/b"
+)
+
+// Exit describes what it costs to unwind a position back into the stable
+// token at a given point in time.
+type Exit struct {
+	Value0 b.Amount // mark-to-market value before unwinding
+	Fees0  b.Amount // swap fees and price impact of unwinding
+	Cost0  b.Amount // gas costs of unwinding
+}
+
+// Realizable0 returns the amount of the stable token we would actually be able
+// to withdraw after unwinding the position.
+func (e Exit) Realizable0() (b.Amount, error) {
+	return b.Sum(e.Value0, e.Fees0.Neg(), e.Cost0.Neg())
+}
@@ -0,0 +1,45 @@
+package position
+
+import (
+	"math/big"
+
+	"github.com/optakt/wilhelmus/b"
+)
+
+// Fees returns the swap fees earned by a liquidity position on each leg of the
+// pair, given the swap volume on each leg, the swap rate, the
+// total liquidity of the pair and the liquidity of the position. Fees are split
+// pro rata and rounded down, so that the fees of all positions never add up to
+// more than the fees paid on the swap volume.
+func Fees(volume0 b.Amount, volume1 b.Amount, swapRate b.Bps, poolLiquidity *big.Int, liquidity *big.Int) (b.Amount, b.Amount, error) {
+
+	share := b.NewAmount(big.NewInt(0).Mul(b.E4, poolLiquidity), 0)
+	rate := b.NewAmount(swapRate.Int(), 0)
+	held := b.NewAmount(liquidity, 0)
+
+	fee0, err := prorata(volume0, rate, held, share)
+	if err != nil {
+		return b.Amount{}, b.Amount{}, err
+	}
+	fee1, err := prorata(volume1, rate, held, share)
+	if err != nil {
+		return b.Amount{}, b.Amount{}, err
+	}
+
+	return fee0, fee1, nil
+}
+
+// prorata returns the pro rata share of the fees paid on the given volume.
+func prorata(volume b.Amount, rate b.Amount, held b.Amount, share b.Amount) (b.Amount, error) {
+
+	fee, err := volume.Mul(rate)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	fee, err = fee.Mul(held)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	return fee.Div(share, b.RoundDown)
+}
@@ -0,0 +1,134 @@
+package position
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/optakt/wilhelmus/b"
+)
+
+func TestFees(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		volume0   int64
+		volume1   int64
+		rate      int64
+		pool      int64
+		liquidity int64
+		want0     int64
+		want1     int64
+	}{
+		{name: "whole pool", volume0: 1_000_000, volume1: 2_000_000, rate: 30, pool: 1_000, liquidity: 1_000, want0: 3_000, want1: 6_000},
+		{name: "half of pool", volume0: 1_000_000, volume1: 2_000_000, rate: 30, pool: 1_000, liquidity: 500, want0: 1_500, want1: 3_000},
+		{name: "lower fee tier", volume0: 1_000_000, volume1: 2_000_000, rate: 5, pool: 1_000, liquidity: 500, want0: 250, want1: 500},
+		{name: "rounded down", volume0: 1_000_001, volume1: 1_000_999, rate: 30, pool: 3, liquidity: 1, want0: 1_000, want1: 1_000},
+		{name: "dust rounded to zero", volume0: 100, volume1: 100, rate: 30, pool: 1_000, liquidity: 1, want0: 0, want1: 0},
+		{name: "no volume", volume0: 0, volume1: 0, rate: 30, pool: 1_000, liquidity: 500, want0: 0, want1: 0},
+		{name: "no liquidity", volume0: 1_000_000, volume1: 1_000_000, rate: 30, pool: 1_000, liquidity: 0, want0: 0, want1: 0},
+		{name: "no fee", volume0: 1_000_000, volume1: 1_000_000, rate: 0, pool: 1_000, liquidity: 500, want0: 0, want1: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			fee0, fee1, err := Fees(
+				b.NewAmount(big.NewInt(test.volume0), 6),
+				b.NewAmount(big.NewInt(test.volume1), 18),
+				b.NewBps(big.NewInt(test.rate)),
+				big.NewInt(test.pool),
+				big.NewInt(test.liquidity),
+			)
+			if err != nil {
+				t.Fatalf("could not compute fees: %s", err)
+			}
+
+			if fee0.Int().Cmp(big.NewInt(test.want0)) != 0 {
+				t.Errorf("wrong fee0 (have: %s, want: %d)", fee0.Int(), test.want0)
+			}
+			if fee1.Int().Cmp(big.NewInt(test.want1)) != 0 {
+				t.Errorf("wrong fee1 (have: %s, want: %d)", fee1.Int(), test.want1)
+			}
+			if fee0.Decimals() != 6 || fee1.Decimals() != 18 {
+				t.Errorf("wrong fee decimals (fee0: %d, fee1: %d)", fee0.Decimals(), fee1.Decimals())
+			}
+		})
+	}
+}
+
+func TestFeesConservation(t *testing.T) {
+
+	volume0, _ := big.NewInt(0).SetString("123456789012345678901", 10)
+	volume1, _ := big.NewInt(0).SetString("987654321098765", 10)
+	rate := b.NewBps(big.NewInt(30))
+
+	pool0 := rate.Scale(volume0, b.RoundDown)
+	pool1 := rate.Scale(volume1, b.RoundDown)
+
+	tests := []struct {
+		name      string
+		providers []int64
+	}{
+		{name: "single provider", providers: []int64{1_000_000}},
+		{name: "even split", providers: []int64{250_000, 250_000, 250_000, 250_000}},
+		{name: "uneven split", providers: []int64{1, 7, 333_333, 666_659}},
+		{name: "many dust providers", providers: []int64{3, 3, 3, 3, 3, 3, 3, 999_979}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			liquidity := big.NewInt(0)
+			for _, provider := range test.providers {
+				liquidity.Add(liquidity, big.NewInt(provider))
+			}
+
+			sum0 := big.NewInt(0)
+			sum1 := big.NewInt(0)
+			for _, provider := range test.providers {
+				fee0, fee1, err := Fees(b.NewAmount(volume0, 6), b.NewAmount(volume1, 18), rate, liquidity, big.NewInt(provider))
+				if err != nil {
+					t.Fatalf("could not compute fees: %s", err)
+				}
+				sum0.Add(sum0, fee0.Int())
+				sum1.Add(sum1, fee1.Int())
+			}
+
+			// Each provider rounds down by less than one unit, so the sum can
+			// fall short of the pool fees by less than one unit per provider,
+			// but never exceed them.
+			count := big.NewInt(int64(len(test.providers)))
+			for _, leg := range []struct {
+				name string
+				sum  *big.Int
+				pool *big.Int
+			}{
+				{name: "token0", sum: sum0, pool: pool0},
+				{name: "token1", sum: sum1, pool: pool1},
+			} {
+				short := big.NewInt(0).Sub(leg.pool, leg.sum)
+				if short.Sign() < 0 || short.Cmp(count) >= 0 {
+					t.Errorf("fees of providers do not add up to pool fees (leg: %s, sum: %s, pool: %s)", leg.name, leg.sum, leg.pool)
+				}
+			}
+		})
+	}
+
+	// With volumes that divide evenly, nothing is lost to rounding.
+	even0 := big.NewInt(40_000_000)
+	even1 := big.NewInt(80_000_000)
+	providers := []int64{100, 300, 600}
+	sum0 := big.NewInt(0)
+	sum1 := big.NewInt(0)
+	for _, provider := range providers {
+		fee0, fee1, err := Fees(b.NewAmount(even0, 6), b.NewAmount(even1, 18), rate, big.NewInt(1_000), big.NewInt(provider))
+		if err != nil {
+			t.Fatalf("could not compute fees: %s", err)
+		}
+		sum0.Add(sum0, fee0.Int())
+		sum1.Add(sum1, fee1.Int())
+	}
+	if sum0.Cmp(rate.Scale(even0, b.RoundDown)) != 0 || sum1.Cmp(rate.Scale(even1, b.RoundDown)) != 0 {
+		t.Errorf("fees of providers differ from pool fees (sum0: %s, sum1: %s)", sum0, sum1)
+	}
+}
@@ -1,26 +1,45 @@
 package position
 
 import (
-	"math/big"
-
+	"github.com/optakt/wilhelmus/b"
 	"github.com/optakt/wilhelmus/util"
 )
 
 type Hold struct {
 	Size    uint64
-	Amount0 *big.Int
-	Amount1 *big.Int
-	Fees0   *big.Int
-	Cost0   *big.Int
+	Amount0 b.Amount
+	Amount1 b.Amount
+	Fees0   b.Amount
+	Cost0   b.Amount
+}
+
+func (h Hold) Value0(reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) {
+
+	amount0, err := util.Quote(h.Amount1, reserve1, reserve0)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	return b.Sum(h.Amount0, amount0, h.Fees0.Neg(), h.Cost0.Neg())
 }
 
-func (h Hold) Value0(reserve0 *big.Int, reserve1 *big.Int) *big.Int {
+func (h Hold) Exit(reserve0 b.Amount, reserve1 b.Amount, swapRate b.Bps, cost0 b.Amount) (Exit, error) {
+
+	fees0, err := swapFees(h.Amount1, reserve1, reserve0, swapRate)
+	if err != nil {
+		return Exit{}, err
+	}
 
-	amount0 := util.Quote(h.Amount1, reserve1, reserve0)
+	value0, err := h.Value0(reserve0, reserve1)
+	if err != nil {
+		return Exit{}, err
+	}
 
-	value0 := big.NewInt(0).Add(h.Amount0, amount0)
-	value0.Sub(value0, h.Fees0)
-	value0.Sub(value0, h.Cost0)
+	exit := Exit{
+		Value0: value0,
+		Fees0:  fees0,
+		Cost0:  cost0,
+	}
 
-	return value0
+	return exit, nil
 }
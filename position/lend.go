@@ -0,0 +1,64 @@
+package position
+
+import (
+	"math/big"
+
+	"github.com/optakt/wilhelmus/b"
+	"github.com/optakt/wilhelmus/util"
+)
+
+// Lend is a baseline position that lends all of its stable token input on
+// Aave and compounds the supply yield.
+type Lend struct {
+	Size       uint64
+	Rate       b.Ray // current supply rate
+	Principal0 b.Amount
+	Yield0     b.Amount
+	Cost0      b.Amount
+}
+
+func (l *Lend) Value0(reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) {
+	return b.Sum(l.Principal0, l.Yield0, l.Cost0.Neg())
+}
+
+// Accrue compounds the supply yield at the given rate over the elapsed seconds
+// and returns the yield earned.
+func (l *Lend) Accrue(rate b.Ray, elapsed *big.Int) (b.Amount, error) {
+
+	l.Rate = rate
+
+	realRate := util.CalculateCompoundedInterest(rate, elapsed)
+
+	supplied0, err := l.Principal0.Add(l.Yield0)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	yieldDelta0, err := supplied0.MulRay(realRate)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	yield0, err := l.Yield0.Add(yieldDelta0)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	l.Yield0 = yield0
+
+	return yieldDelta0, nil
+}
+
+func (l *Lend) Exit(reserve0 b.Amount, reserve1 b.Amount, cost0 b.Amount) (Exit, error) {
+
+	value0, err := l.Value0(reserve0, reserve1)
+	if err != nil {
+		return Exit{}, err
+	}
+
+	exit := Exit{
+		Value0: value0,
+		Fees0:  b.NewAmount(b.D0, value0.Decimals()),
+		Cost0:  cost0,
+	}
+
+	return exit, nil
+}
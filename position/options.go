@@ -0,0 +1,206 @@
+package position
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/optakt/wilhelmus/b"
+	"github.com/optakt/wilhelmus/option"
+	"github.com/optakt/wilhelmus/util"
+)
+
+// Options is a liquidity position that is protected by buying options on
+// token1, which are rolled into new options at each expiry.
+type Options struct {
+	Size       uint64
+	Kind       string
+	Unit1      b.Amount // one whole token1, which option prices refer to
+	Liquidity  *big.Int
+	Pending0   b.Amount
+	Pending1   b.Amount
+	Contracts1 b.Amount  // amount of token1 covered by the open options
+	Strike0    b.Amount  // strike price of one whole token1
+	Expiry     time.Time // expiry of the open options
+	Mark0      b.Amount  // current value of the open options
+	Premium0   b.Amount  // cumulative premium paid for options
+	Payoff0    b.Amount  // cumulative payoff received at expiry
+	Fees0      b.Amount
+	Cost0      b.Amount
+	Profit0    b.Amount
+	Rewards    Rewards
+	Rolls      uint
+	Harvests   uint
+}
+
+func (o *Options) Value0(reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) {
+
+	pooled0, err := pooled(o.Liquidity, o.Pending0, o.Pending1, o.Rewards, reserve0, reserve1)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	return b.Sum(pooled0, o.Mark0, o.Payoff0, o.Premium0.Neg(), o.Fees0.Neg(), o.Cost0.Neg())
+}
+
+// Buy opens new options on the given amount of token1, paying the given price
+// per whole token1 as premium, and the given fee rate on the notional value of
+// the covered token1.
+func (o *Options) Buy(contracts1 b.Amount, strike0 b.Amount, expiry time.Time, price0 b.Amount, feeRate b.Bps, reserve0 b.Amount, reserve1 b.Amount) error {
+
+	premium0, err := o.value0(contracts1, price0)
+	if err != nil {
+		return err
+	}
+
+	notional0, err := util.Quote(contracts1, reserve1, reserve0)
+	if err != nil {
+		return err
+	}
+	fees0, err := notional0.MulBps(feeRate, b.RoundUp)
+	if err != nil {
+		return err
+	}
+
+	total0, err := o.Premium0.Add(premium0)
+	if err != nil {
+		return err
+	}
+	fees0, err = o.Fees0.Add(fees0)
+	if err != nil {
+		return err
+	}
+
+	o.Contracts1 = contracts1
+	o.Strike0 = strike0
+	o.Expiry = expiry
+	o.Mark0 = premium0
+	o.Premium0 = total0
+	o.Fees0 = fees0
+	o.Rolls++
+
+	return nil
+}
+
+// Mark updates the value of the open options, given their current price per
+// whole token1.
+func (o *Options) Mark(price0 b.Amount) error {
+
+	mark0, err := o.value0(o.Contracts1, price0)
+	if err != nil {
+		return err
+	}
+
+	o.Mark0 = mark0
+
+	return nil
+}
+
+// Settle settles the open options at expiry against the pair's price and
+// returns the received payoff.
+func (o *Options) Settle(reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) {
+
+	spot0, err := util.Quote(o.Unit1, reserve1, reserve0)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	// The put leg pays out below the strike, and the call leg of a straddle
+	// above it.
+	intrinsic0, err := o.Strike0.Sub(spot0)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	if intrinsic0.Sign() < 0 {
+		intrinsic0 = b.NewAmount(b.D0, spot0.Decimals())
+		if o.Kind == option.Straddle {
+			intrinsic0, err = spot0.Sub(o.Strike0)
+			if err != nil {
+				return b.Amount{}, err
+			}
+		}
+	}
+
+	payoff0, err := o.value0(o.Contracts1, intrinsic0)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	total0, err := o.Payoff0.Add(payoff0)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	o.Payoff0 = total0
+	o.Contracts1 = b.NewAmount(b.D0, o.Contracts1.Decimals())
+	o.Mark0 = b.NewAmount(b.D0, spot0.Decimals())
+
+	return payoff0, nil
+}
+
+// value0 returns the value of the given amount of token1 at the given price
+// per whole token1.
+func (o *Options) value0(amount1 b.Amount, price0 b.Amount) (b.Amount, error) {
+
+	value0, err := amount1.Mul(price0)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	return value0.Div(o.Unit1, b.RoundDown)
+}
+
+// Unharvested0 returns the value of the fees that were not yet added to the
+// liquidity of the position.
+func (o Options) Unharvested0(reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) {
+	return unharvested(o.Pending0, o.Pending1, reserve0, reserve1)
+}
+
+// Compound adds the pending fees to the liquidity of the position.
+func (o *Options) Compound(reserve0 b.Amount, reserve1 b.Amount) error {
+
+	liquidity, err := compound(o.Liquidity, o.Pending0, o.Pending1, reserve0, reserve1)
+	if err != nil {
+		return err
+	}
+
+	o.Liquidity = liquidity
+	o.Pending0 = b.NewAmount(b.D0, reserve0.Decimals())
+	o.Pending1 = b.NewAmount(b.D0, reserve1.Decimals())
+
+	return nil
+}
+
+func (o *Options) Exit(reserve0 b.Amount, reserve1 b.Amount, swapRate b.Bps, feeRate b.Bps, cost0 b.Amount) (Exit, error) {
+
+	// Pending fees in token1 have to be swapped back as well.
+	fees0, err := withdraw(o.Liquidity, o.Pending1, reserve0, reserve1, swapRate)
+	if err != nil {
+		return Exit{}, err
+	}
+
+	// Selling the open options pays the trading fee on their notional.
+	notional0, err := util.Quote(o.Contracts1, reserve1, reserve0)
+	if err != nil {
+		return Exit{}, err
+	}
+	sale0, err := notional0.MulBps(feeRate, b.RoundUp)
+	if err != nil {
+		return Exit{}, err
+	}
+	fees0, err = fees0.Add(sale0)
+	if err != nil {
+		return Exit{}, err
+	}
+
+	value0, err := o.Value0(reserve0, reserve1)
+	if err != nil {
+		return Exit{}, err
+	}
+
+	exit := Exit{
+		Value0: value0,
+		Fees0:  fees0,
+		Cost0:  cost0,
+	}
+
+	return exit, nil
+}
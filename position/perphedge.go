@@ -0,0 +1,256 @@
+package position
+
+import (
+	"math/big"
+
+	"github.com/optakt/wilhelmus/b"
+	"github.com/optakt/wilhelmus/util"
+)
+
+// PerpHedge is a liquidity position whose token1 leg is hedged by shorting a
+// perpetual future, instead of borrowing token1 like the autohedge.
+type PerpHedge struct {
+	Size         uint64
+	Rehedge      b.Bps
+	Liquidity    *big.Int
+	Pending0     b.Amount
+	Pending1     b.Amount
+	Short1       b.Amount // size of the short perpetual position
+	Entry0       b.Amount // entry notional of the short perpetual position
+	Margin0      b.Amount // margin balance, including realized PnL and funding
+	Funding0     b.Amount // cumulative funding received (negative if paid)
+	Fees0        b.Amount
+	Cost0        b.Amount
+	Profit0      b.Amount
+	Rewards      Rewards
+	Count        uint
+	Harvests     uint
+	Liquidations uint
+}
+
+// Unrealized0 returns the unrealized PnL of the short perpetual position,
+// marked at the pair's price.
+func (p *PerpHedge) Unrealized0(reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) {
+
+	notional0, err := util.Quote(p.Short1, reserve1, reserve0)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	return p.Entry0.Sub(notional0)
+}
+
+// Equity0 returns the margin balance plus the unrealized PnL.
+func (p *PerpHedge) Equity0(reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) {
+
+	unrealized0, err := p.Unrealized0(reserve0, reserve1)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	return p.Margin0.Add(unrealized0)
+}
+
+func (p *PerpHedge) Value0(reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) {
+
+	pooled0, err := pooled(p.Liquidity, p.Pending0, p.Pending1, p.Rewards, reserve0, reserve1)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	equity0, err := p.Equity0(reserve0, reserve1)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	return b.Sum(pooled0, equity0, p.Fees0.Neg(), p.Cost0.Neg())
+}
+
+// Fund accrues the funding for the elapsed seconds at the given hourly rate
+// on the current notional of the short position.
+func (p *PerpHedge) Fund(rate b.Ray, elapsed *big.Int, reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) {
+
+	notional0, err := util.Quote(p.Short1, reserve1, reserve0)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	funding0, err := notional0.Mul(b.NewAmount(elapsed, 0))
+	if err != nil {
+		return b.Amount{}, err
+	}
+	funding0, err = funding0.MulRay(rate)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	funding0, err = funding0.Div(b.NewAmount(b.D3600, 0), b.RoundDown)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	margin0, err := p.Margin0.Add(funding0)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	total0, err := p.Funding0.Add(funding0)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	p.Margin0 = margin0
+	p.Funding0 = total0
+
+	return funding0, nil
+}
+
+// Liquidate checks whether the equity of the short position fell below the
+// given maintenance margin ratio of its notional. If so, the short position
+// is liquidated and its remaining equity is lost.
+func (p *PerpHedge) Liquidate(maintenance b.Bps, reserve0 b.Amount, reserve1 b.Amount) (bool, error) {
+
+	if p.Short1.Sign() == 0 {
+		return false, nil
+	}
+
+	notional0, err := util.Quote(p.Short1, reserve1, reserve0)
+	if err != nil {
+		return false, err
+	}
+	required0, err := notional0.MulBps(maintenance, b.RoundUp)
+	if err != nil {
+		return false, err
+	}
+	equity0, err := p.Equity0(reserve0, reserve1)
+	if err != nil {
+		return false, err
+	}
+	cmp, err := equity0.Cmp(required0)
+	if err != nil {
+		return false, err
+	}
+	if cmp >= 0 {
+		return false, nil
+	}
+
+	p.Short1 = b.NewAmount(b.D0, p.Short1.Decimals())
+	p.Entry0 = b.NewAmount(b.D0, p.Entry0.Decimals())
+	p.Margin0 = b.NewAmount(b.D0, p.Margin0.Decimals())
+	p.Liquidations++
+
+	return true, nil
+}
+
+// Adjust resizes the short position to the given target, paying the given
+// perpetual trading fee rate on the traded notional. When the short is
+// reduced, the corresponding PnL is realized into the margin.
+func (p *PerpHedge) Adjust(target1 b.Amount, feeRate b.Bps, reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) {
+
+	delta1, err := target1.Sub(p.Short1)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	traded0, err := util.Quote(delta1.Abs(), reserve1, reserve0)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	entry0 := p.Entry0
+	margin0 := p.Margin0
+	switch delta1.Sign() {
+
+	case 1:
+		entry0, err = entry0.Add(traded0)
+		if err != nil {
+			return b.Amount{}, err
+		}
+
+	case -1:
+		closed0, err := delta1.Neg().Mul(p.Entry0)
+		if err != nil {
+			return b.Amount{}, err
+		}
+		closed0, err = closed0.Div(p.Short1, b.RoundDown)
+		if err != nil {
+			return b.Amount{}, err
+		}
+		entry0, err = entry0.Sub(closed0)
+		if err != nil {
+			return b.Amount{}, err
+		}
+		margin0, err = b.Sum(margin0, closed0, traded0.Neg())
+		if err != nil {
+			return b.Amount{}, err
+		}
+	}
+
+	fees0, err := traded0.MulBps(feeRate, b.RoundUp)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	fees0, err = p.Fees0.Add(fees0)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	p.Short1 = target1
+	p.Entry0 = entry0
+	p.Margin0 = margin0
+	p.Fees0 = fees0
+
+	return delta1, nil
+}
+
+// Unharvested0 returns the value of the fees that were not yet added to the
+// liquidity of the position.
+func (p PerpHedge) Unharvested0(reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) {
+	return unharvested(p.Pending0, p.Pending1, reserve0, reserve1)
+}
+
+// Compound adds the pending fees to the liquidity of the position.
+func (p *PerpHedge) Compound(reserve0 b.Amount, reserve1 b.Amount) error {
+
+	liquidity, err := compound(p.Liquidity, p.Pending0, p.Pending1, reserve0, reserve1)
+	if err != nil {
+		return err
+	}
+
+	p.Liquidity = liquidity
+	p.Pending0 = b.NewAmount(b.D0, reserve0.Decimals())
+	p.Pending1 = b.NewAmount(b.D0, reserve1.Decimals())
+
+	return nil
+}
+
+func (p *PerpHedge) Exit(reserve0 b.Amount, reserve1 b.Amount, swapRate b.Bps, feeRate b.Bps, cost0 b.Amount) (Exit, error) {
+
+	// Pending fees in token1 have to be swapped back as well.
+	fees0, err := withdraw(p.Liquidity, p.Pending1, reserve0, reserve1, swapRate)
+	if err != nil {
+		return Exit{}, err
+	}
+
+	// Closing the short position pays the trading fee on its notional.
+	notional0, err := util.Quote(p.Short1, reserve1, reserve0)
+	if err != nil {
+		return Exit{}, err
+	}
+	close0, err := notional0.MulBps(feeRate, b.RoundUp)
+	if err != nil {
+		return Exit{}, err
+	}
+	fees0, err = fees0.Add(close0)
+	if err != nil {
+		return Exit{}, err
+	}
+
+	value0, err := p.Value0(reserve0, reserve1)
+	if err != nil {
+		return Exit{}, err
+	}
+
+	exit := Exit{
+		Value0: value0,
+		Fees0:  fees0,
+		Cost0:  cost0,
+	}
+
+	return exit, nil
+}
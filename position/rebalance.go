@@ -0,0 +1,168 @@
+package position
+
+import (
+	"github.com/optakt/wilhelmus/b"
+	"github.com/optakt/wilhelmus/util"
+)
+
+// Rebalance is a baseline position that holds a constant 50/50 mix of the
+// stable token and token1, and swaps between them to restore the mix.
+type Rebalance struct {
+	Size    uint64
+	Drift   b.Bps
+	Amount0 b.Amount
+	Amount1 b.Amount
+	Fees0   b.Amount
+	Cost0   b.Amount
+	Count   uint
+}
+
+func (r *Rebalance) Value0(reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) {
+
+	amount0, err := util.Quote(r.Amount1, reserve1, reserve0)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	return b.Sum(r.Amount0, amount0, r.Fees0.Neg(), r.Cost0.Neg())
+}
+
+// Drifted returns whether the value of the token1 leg left the band around
+// the stable token leg defined by the drift ratio, in basis points.
+func (r *Rebalance) Drifted(reserve0 b.Amount, reserve1 b.Amount) (bool, error) {
+
+	if r.Drift.Sign() == 0 {
+		return false, nil
+	}
+
+	value1, err := util.Quote(r.Amount1, reserve1, reserve0)
+	if err != nil {
+		return false, err
+	}
+
+	deviation, err := Deviate(value1, r.Amount0, r.Drift)
+	if err != nil {
+		return false, err
+	}
+
+	return deviation != 0, nil
+}
+
+// Delta0 returns the value of the swap that restores the mix, which is half
+// of the difference between both legs; it is negative if token1 is sold.
+func (r *Rebalance) Delta0(reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) {
+
+	value1, err := util.Quote(r.Amount1, reserve1, reserve0)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	delta0, err := r.Amount0.Sub(value1)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	return delta0.Div(b.NewAmount(b.D2, 0), b.RoundDown)
+}
+
+// Rebalance swaps half of the difference between both legs on the pair, so
+// that both legs are of equal value again, and returns the swapped amount
+// of token1, which is negative if token1 was sold.
+func (r *Rebalance) Rebalance(reserve0 b.Amount, reserve1 b.Amount, swapRate b.Bps, cost0 b.Amount) (b.Amount, error) {
+
+	delta0, err := r.Delta0(reserve0, reserve1)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	var amount0, amount1, fees0, swapped1 b.Amount
+	switch delta0.Sign() {
+
+	case 1:
+		out1, err := util.CalculateAmountOut(delta0, reserve0, reserve1, swapRate)
+		if err != nil {
+			return b.Amount{}, err
+		}
+		quote0, err := util.Quote(out1, reserve1, reserve0)
+		if err != nil {
+			return b.Amount{}, err
+		}
+		amount0, err = r.Amount0.Sub(delta0)
+		if err != nil {
+			return b.Amount{}, err
+		}
+		amount1, err = r.Amount1.Add(out1)
+		if err != nil {
+			return b.Amount{}, err
+		}
+		fees0, err = b.Sum(r.Fees0, delta0, quote0.Neg())
+		if err != nil {
+			return b.Amount{}, err
+		}
+		swapped1 = out1
+
+	case -1:
+		in1, err := util.Quote(delta0.Neg(), reserve0, reserve1)
+		if err != nil {
+			return b.Amount{}, err
+		}
+		out0, err := util.CalculateAmountOut(in1, reserve1, reserve0, swapRate)
+		if err != nil {
+			return b.Amount{}, err
+		}
+		quote0, err := util.Quote(in1, reserve1, reserve0)
+		if err != nil {
+			return b.Amount{}, err
+		}
+		amount1, err = r.Amount1.Sub(in1)
+		if err != nil {
+			return b.Amount{}, err
+		}
+		amount0, err = r.Amount0.Add(out0)
+		if err != nil {
+			return b.Amount{}, err
+		}
+		fees0, err = b.Sum(r.Fees0, quote0, out0.Neg())
+		if err != nil {
+			return b.Amount{}, err
+		}
+		swapped1 = in1.Neg()
+
+	default:
+		return b.NewAmount(b.D0, r.Amount1.Decimals()), nil
+	}
+
+	cost0, err = r.Cost0.Add(cost0)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	r.Amount0 = amount0
+	r.Amount1 = amount1
+	r.Fees0 = fees0
+	r.Cost0 = cost0
+	r.Count++
+
+	return swapped1, nil
+}
+
+func (r *Rebalance) Exit(reserve0 b.Amount, reserve1 b.Amount, swapRate b.Bps, cost0 b.Amount) (Exit, error) {
+
+	fees0, err := swapFees(r.Amount1, reserve1, reserve0, swapRate)
+	if err != nil {
+		return Exit{}, err
+	}
+
+	value0, err := r.Value0(reserve0, reserve1)
+	if err != nil {
+		return Exit{}, err
+	}
+
+	exit := Exit{
+		Value0: value0,
+		Fees0:  fees0,
+		Cost0:  cost0,
+	}
+
+	return exit, nil
+}
@@ -0,0 +1,104 @@
+package position
+
+import (
+	"math/big"
+
+	"github.com/optakt/wilhelmus/b"
+)
+
+// Rewards tracks the liquidity mining rewards earned by a liquidity position,
+// separately from the swap fees it earns.
+type Rewards struct {
+	Amount b.Amount // reward tokens held
+	Value0 b.Amount // current value of the reward tokens held
+	Sold0  b.Amount // value of reward tokens sold at the time of sale
+	Fees0  b.Amount // swap fees paid when selling reward tokens
+	Cost0  b.Amount // gas costs paid for claiming and selling reward tokens
+	Sales  uint
+}
+
+// NewRewards returns empty rewards for reward tokens with the given decimals,
+// valued in token0 with the given decimals.
+func NewRewards(decimals0 uint, decimals uint) Rewards {
+
+	r := Rewards{
+		Amount: b.NewAmount(b.D0, decimals),
+		Value0: b.NewAmount(b.D0, decimals0),
+		Sold0:  b.NewAmount(b.D0, decimals0),
+		Fees0:  b.NewAmount(b.D0, decimals0),
+		Cost0:  b.NewAmount(b.D0, decimals0),
+		Sales:  0,
+	}
+
+	return r
+}
+
+// Net0 returns the net contribution of the rewards to the value of the position.
+func (r Rewards) Net0() (b.Amount, error) {
+	return b.Sum(r.Value0, r.Sold0, r.Fees0.Neg(), r.Cost0.Neg())
+}
+
+// Accrue adds the rewards emitted at the given rate over the elapsed seconds,
+// pro-rata to the share of the position in the pool's liquidity. The rate is
+// given in the smallest unit of the reward token per second.
+func (r *Rewards) Accrue(rate *big.Int, elapsed *big.Int, liquidity *big.Int, poolLiquidity *big.Int) (b.Amount, error) {
+
+	earned := big.NewInt(0).Mul(rate, elapsed)
+	earned.Mul(earned, liquidity)
+	earned.Div(earned, poolLiquidity)
+
+	amount, err := r.Amount.Add(b.NewAmount(earned, r.Amount.Decimals()))
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	r.Amount = amount
+
+	return b.NewAmount(earned, amount.Decimals()), nil
+}
+
+// Mark updates the value of the held reward tokens, given the price of one
+// whole reward token in token0.
+func (r *Rewards) Mark(price0 b.Amount) error {
+
+	value0, err := r.Amount.Mul(price0)
+	if err != nil {
+		return err
+	}
+
+	r.Value0 = value0.Rescale(price0.Decimals(), b.RoundDown)
+
+	return nil
+}
+
+// Sell sells all held reward tokens at their current value, paying the given
+// swap rate and gas costs.
+func (r *Rewards) Sell(swapRate b.Bps, cost0 b.Amount) error {
+
+	fees0, err := r.Value0.MulBps(swapRate, b.RoundDown)
+	if err != nil {
+		return err
+	}
+
+	sold0, err := r.Sold0.Add(r.Value0)
+	if err != nil {
+		return err
+	}
+	fees0, err = r.Fees0.Add(fees0)
+	if err != nil {
+		return err
+	}
+	cost0, err = r.Cost0.Add(cost0)
+	if err != nil {
+		return err
+	}
+
+	r.Sold0 = sold0
+	r.Fees0 = fees0
+	r.Cost0 = cost0
+	r.Amount = b.NewAmount(b.D0, r.Amount.Decimals())
+	r.Value0 = b.NewAmount(b.D0, r.Value0.Decimals())
+	r.Sales++
+
+	return nil
+}
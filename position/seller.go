@@ -0,0 +1,66 @@
+package position
+
+import (
+	"fmt"
+	"time"
+)
+
+// Sale policies for the reward tokens earned by liquidity positions.
+const (
+	SellImmediate = "immediate" // reward tokens are claimed and sold on every update
+	SellPeriodic  = "periodic"  // reward tokens are claimed and sold at a fixed interval
+	SellHold      = "hold"      // reward tokens are held and marked to market
+)
+
+// Seller decides when the reward tokens of a liquidity position are claimed
+// and sold for the stable token.
+type Seller struct {
+	Policy   string
+	Interval time.Duration
+	Last     time.Time
+}
+
+func NewSeller(policy string, interval time.Duration, start time.Time) (*Seller, error) {
+
+	switch policy {
+	case SellImmediate, SellHold:
+	case SellPeriodic:
+		if interval <= 0 {
+			return nil, fmt.Errorf("sale interval must be positive for periodic sales (interval: %s)", interval)
+		}
+	default:
+		return nil, fmt.Errorf("unknown reward sale policy (policy: %s)", policy)
+	}
+
+	s := Seller{
+		Policy:   policy,
+		Interval: interval,
+		Last:     start,
+	}
+
+	return &s, nil
+}
+
+// Sell returns whether the held rewards should be sold at the given timestamp.
+func (s *Seller) Sell(timestamp time.Time, rewards Rewards) bool {
+
+	if rewards.Amount.Sign() == 0 {
+		return false
+	}
+
+	switch s.Policy {
+
+	case SellImmediate:
+		return true
+
+	case SellPeriodic:
+		if timestamp.Sub(s.Last) < s.Interval {
+			return false
+		}
+		s.Last = timestamp
+		return true
+
+	default:
+		return false
+	}
+}
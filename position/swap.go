@@ -0,0 +1,23 @@
+package position
+
+import (
+	"github.com/optakt/wilhelmus/b"
+	"github.com/optakt/wilhelmus/util"
+)
+
+// swapFees returns the difference between the quote for the given amount and
+// what swapping it on the pair yields, which is lost to swap fees and price
+// impact.
+func swapFees(amountIn b.Amount, reserveIn b.Amount, reserveOut b.Amount, swapRate b.Bps) (b.Amount, error) {
+
+	quote, err := util.Quote(amountIn, reserveIn, reserveOut)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	out, err := util.CalculateAmountOut(amountIn, reserveIn, reserveOut, swapRate)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	return quote.Sub(out)
+}
@@ -0,0 +1,206 @@
+package position
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/optakt/wilhelmus/b"
+	"github.com/optakt/wilhelmus/util"
+)
+
+// Trigger policies for rehedging hedged liquidity positions.
+const (
+	TriggerSymmetric  = "symmetric"  // rehedge when leaving a symmetric band around the target
+	TriggerAsymmetric = "asymmetric" // rehedge when leaving a band with different bounds below and above the target
+	TriggerTime       = "time"       // rehedge at a fixed interval, regardless of the deviation
+	TriggerHysteresis = "hysteresis" // rehedge when leaving the band, but only back to a tighter inner band
+	TriggerGas        = "gas"        // rehedge when leaving the band, if the expected loss avoided exceeds the gas cost
+	TriggerVolatility = "volatility" // rehedge when leaving a band that is scaled with volatility
+)
+
+// Conditions holds the market conditions that some trigger policies take into account.
+type Conditions struct {
+	Reserve0   b.Amount
+	Reserve1   b.Amount
+	Volatility float64  // annualized volatility of the pair's price
+	Cost0      b.Amount // gas cost of rehedging
+}
+
+// Trigger decides when a hedged position is rehedged, and by how much. All
+// ratios are relative to the target.
+type Trigger struct {
+	Policy    string
+	Lower     b.Bps         // band below the target
+	Upper     b.Bps         // band above the target
+	Inner     b.Bps         // band to rehedge back to for hysteresis
+	Interval  time.Duration // interval for time-based rehedging and horizon for gas-aware rehedging
+	Reference float64       // volatility at which volatility-scaled bands are unscaled
+	Last      time.Time
+}
+
+func NewTrigger(policy string, lower b.Bps, upper b.Bps, inner b.Bps, interval time.Duration, reference float64, start time.Time) (*Trigger, error) {
+
+	switch policy {
+	case TriggerSymmetric, TriggerAsymmetric:
+	case TriggerTime, TriggerGas:
+		if interval <= 0 {
+			return nil, fmt.Errorf("rehedge interval must be positive for %s trigger (interval: %s)", policy, interval)
+		}
+	case TriggerHysteresis:
+		if inner.Cmp(lower) >= 0 {
+			return nil, fmt.Errorf("inner band must be tighter than rehedge band for hysteresis trigger (inner: %f, band: %f)", inner.Float(), lower.Float())
+		}
+	case TriggerVolatility:
+		if reference <= 0 {
+			return nil, fmt.Errorf("reference volatility must be positive for volatility trigger (reference: %f)", reference)
+		}
+	default:
+		return nil, fmt.Errorf("unknown rehedge trigger policy (policy: %s)", policy)
+	}
+
+	t := Trigger{
+		Policy:    policy,
+		Lower:     lower,
+		Upper:     upper,
+		Inner:     inner,
+		Interval:  interval,
+		Reference: reference,
+		Last:      start,
+	}
+
+	return &t, nil
+}
+
+// Check returns the part of the gap between the given amount and its target
+// that should be closed by rehedging, which is positive if the amount is
+// below the target and negative if it is above. It returns zero if no rehedge
+// should take place.
+func (t *Trigger) Check(timestamp time.Time, amount b.Amount, target b.Amount, conditions Conditions) (b.Amount, error) {
+
+	gap, err := target.Sub(amount)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	none := b.NewAmount(b.D0, target.Decimals())
+
+	switch t.Policy {
+
+	case TriggerSymmetric, TriggerHysteresis:
+		deviation, err := Deviate(amount, target, t.Lower)
+		if err != nil {
+			return b.Amount{}, err
+		}
+		if deviation == 0 {
+			return none, nil
+		}
+
+	case TriggerAsymmetric:
+		below, err := target.MulBps(t.Lower, b.RoundDown)
+		if err != nil {
+			return b.Amount{}, err
+		}
+		above, err := target.MulBps(t.Upper, b.RoundDown)
+		if err != nil {
+			return b.Amount{}, err
+		}
+		lower, err := target.Sub(below)
+		if err != nil {
+			return b.Amount{}, err
+		}
+		upper, err := target.Add(above)
+		if err != nil {
+			return b.Amount{}, err
+		}
+		cmpLower, err := amount.Cmp(lower)
+		if err != nil {
+			return b.Amount{}, err
+		}
+		cmpUpper, err := amount.Cmp(upper)
+		if err != nil {
+			return b.Amount{}, err
+		}
+		if cmpLower >= 0 && cmpUpper <= 0 {
+			return none, nil
+		}
+
+	case TriggerTime:
+		if timestamp.Sub(t.Last) < t.Interval {
+			return none, nil
+		}
+
+	case TriggerGas:
+		deviation, err := Deviate(amount, target, t.Lower)
+		if err != nil {
+			return b.Amount{}, err
+		}
+		if deviation == 0 {
+			return none, nil
+		}
+		// We estimate the loss avoided by rehedging as the expected absolute
+		// price move over the horizon on the unhedged part of the position.
+		gap0, err := util.Quote(gap.Abs(), conditions.Reserve1, conditions.Reserve0)
+		if err != nil {
+			return b.Amount{}, err
+		}
+		years := t.Interval.Hours() / float64(b.HPY.Int64())
+		move := conditions.Volatility * math.Sqrt(years)
+		value, _ := big.NewFloat(0).Mul(big.NewFloat(0).SetInt(gap0.Int()), big.NewFloat(move)).Int(nil)
+		expected0 := b.NewAmount(value, gap0.Decimals())
+		cmp, err := expected0.Cmp(conditions.Cost0)
+		if err != nil {
+			return b.Amount{}, err
+		}
+		if cmp < 0 {
+			return none, nil
+		}
+
+	case TriggerVolatility:
+		scale := big.NewFloat(conditions.Volatility / t.Reference)
+		scaled, _ := big.NewFloat(0).Mul(big.NewFloat(0).SetInt(t.Lower.Int()), scale).Int(nil)
+		deviation, err := Deviate(amount, target, b.NewBps(scaled))
+		if err != nil {
+			return b.Amount{}, err
+		}
+		if deviation == 0 {
+			return none, nil
+		}
+	}
+
+	if gap.Sign() == 0 {
+		return none, nil
+	}
+
+	t.Last = timestamp
+
+	return t.Gap(amount, target)
+}
+
+// Gap returns the part of the gap between the given amount and its target
+// that is closed when rehedging, regardless of whether a rehedge is due. This
+// is the whole gap, except for hysteresis, where we leave the inner band.
+func (t *Trigger) Gap(amount b.Amount, target b.Amount) (b.Amount, error) {
+
+	gap, err := target.Sub(amount)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	if t.Policy != TriggerHysteresis {
+		return gap, nil
+	}
+
+	residual, err := target.MulBps(t.Inner, b.RoundDown)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	if gap.Int().CmpAbs(residual.Int()) <= 0 {
+		return b.NewAmount(b.D0, gap.Decimals()), nil
+	}
+
+	if gap.Sign() > 0 {
+		return gap.Sub(residual)
+	}
+
+	return gap.Add(residual)
+}
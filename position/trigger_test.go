@@ -0,0 +1,127 @@
+package position
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/optakt/wilhelmus/b"
+)
+
+func TestTriggerCheck(t *testing.T) {
+
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	target := b.NewAmount(big.NewInt(1_000_000), 6)
+
+	// Both reserves are equal, so the gap is worth as much in token0.
+	conditions := Conditions{
+		Reserve0:   b.NewAmount(big.NewInt(1_000_000_000), 6),
+		Reserve1:   b.NewAmount(big.NewInt(1_000_000_000), 6),
+		Volatility: 0.8,
+		Cost0:      b.NewAmount(big.NewInt(1_000), 6),
+	}
+
+	tests := []struct {
+		name     string
+		policy   string
+		lower    int64
+		upper    int64
+		inner    int64
+		interval time.Duration
+		elapsed  time.Duration
+		amount   int64
+		cost0    int64
+		want     int64
+	}{
+		{name: "symmetric inside band", policy: TriggerSymmetric, lower: 100, upper: 100, amount: 990_000, want: 0},
+		{name: "symmetric below band", policy: TriggerSymmetric, lower: 100, upper: 100, amount: 989_999, want: 10_001},
+		{name: "symmetric above band", policy: TriggerSymmetric, lower: 100, upper: 100, amount: 1_010_001, want: -10_001},
+		{name: "asymmetric inside wide upper band", policy: TriggerAsymmetric, lower: 100, upper: 500, amount: 1_040_000, want: 0},
+		{name: "asymmetric below narrow lower band", policy: TriggerAsymmetric, lower: 100, upper: 500, amount: 989_000, want: 11_000},
+		{name: "time before interval", policy: TriggerTime, lower: 100, upper: 100, interval: time.Hour, elapsed: 30 * time.Minute, amount: 900_000, want: 0},
+		{name: "time after interval", policy: TriggerTime, lower: 100, upper: 100, interval: time.Hour, elapsed: time.Hour, amount: 999_000, want: 1_000},
+		{name: "hysteresis inside band", policy: TriggerHysteresis, lower: 100, upper: 100, inner: 25, amount: 995_000, want: 0},
+		{name: "hysteresis back to inner band below", policy: TriggerHysteresis, lower: 100, upper: 100, inner: 25, amount: 980_000, want: 17_500},
+		{name: "hysteresis back to inner band above", policy: TriggerHysteresis, lower: 100, upper: 100, inner: 25, amount: 1_020_000, want: -17_500},
+		{name: "gas below cost", policy: TriggerGas, lower: 100, upper: 100, interval: time.Hour, amount: 980_000, cost0: 1_000, want: 0},
+		{name: "gas above cost", policy: TriggerGas, lower: 100, upper: 100, interval: time.Hour, amount: 980_000, cost0: 100, want: 20_000},
+		{name: "volatility scaled band", policy: TriggerVolatility, lower: 100, upper: 100, amount: 985_000, want: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			trigger, err := NewTrigger(test.policy,
+				b.NewBps(big.NewInt(test.lower)),
+				b.NewBps(big.NewInt(test.upper)),
+				b.NewBps(big.NewInt(test.inner)),
+				test.interval,
+				0.4, // the volatility of the conditions doubles the band
+				start,
+			)
+			if err != nil {
+				t.Fatalf("could not create trigger: %s", err)
+			}
+
+			c := conditions
+			c.Cost0 = b.NewAmount(big.NewInt(test.cost0), 6)
+			got, err := trigger.Check(start.Add(test.elapsed), b.NewAmount(big.NewInt(test.amount), 6), target, c)
+			if err != nil {
+				t.Fatalf("could not check trigger: %s", err)
+			}
+			if got.Int().Cmp(big.NewInt(test.want)) != 0 {
+				t.Errorf("gap: want %d, got %s", test.want, got.Int())
+			}
+		})
+	}
+}
+
+// Hysteresis leaves the inner band open, so a gap that is already within the
+// inner band, for example after a delayed execution, is not closed at all.
+func TestTriggerGapHysteresis(t *testing.T) {
+
+	trigger, err := NewTrigger(TriggerHysteresis, b.NewBps(big.NewInt(100)), b.NewBps(big.NewInt(100)), b.NewBps(big.NewInt(25)), 0, 0, time.Time{})
+	if err != nil {
+		t.Fatalf("could not create trigger: %s", err)
+	}
+
+	target := b.NewAmount(big.NewInt(1_000_000), 6)
+
+	tests := []struct {
+		amount int64
+		want   int64
+	}{
+		{amount: 1_000_000, want: 0},
+		{amount: 997_500, want: 0},
+		{amount: 1_002_500, want: 0},
+		{amount: 997_000, want: 500},
+		{amount: 1_003_000, want: -500},
+	}
+
+	for _, test := range tests {
+		got, err := trigger.Gap(b.NewAmount(big.NewInt(test.amount), 6), target)
+		if err != nil {
+			t.Fatalf("could not compute gap: %s", err)
+		}
+		if got.Int().Cmp(big.NewInt(test.want)) != 0 {
+			t.Errorf("gap for %d: want %d, got %s", test.amount, test.want, got.Int())
+		}
+	}
+}
+
+// All other policies close the whole gap.
+func TestTriggerGapFull(t *testing.T) {
+
+	trigger, err := NewTrigger(TriggerSymmetric, b.NewBps(big.NewInt(100)), b.NewBps(big.NewInt(100)), b.NewBps(big.NewInt(25)), 0, 0, time.Time{})
+	if err != nil {
+		t.Fatalf("could not create trigger: %s", err)
+	}
+
+	got, err := trigger.Gap(b.NewAmount(big.NewInt(999_000), 6), b.NewAmount(big.NewInt(1_000_000), 6))
+	if err != nil {
+		t.Fatalf("could not compute gap: %s", err)
+	}
+	if got.Int().Cmp(big.NewInt(1_000)) != 0 {
+		t.Errorf("gap: want 1000, got %s", got.Int())
+	}
+}
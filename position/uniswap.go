@@ -9,22 +9,64 @@ import (
 type Uniswap struct {
 	Size      uint64
 	Liquidity *big.Int
-	Fees0     *big.Int
-	Cost0     *big.Int
-	Profit0   *big.Int
+	Pending0  b.Amount
+	Pending1  b.Amount
+	Fees0     b.Amount
+	Cost0     b.Amount
+	Profit0   b.Amount
+	Rewards   Rewards
+	Harvests  uint
 }
 
-func (u Uniswap) Value0(reserve0 *big.Int, reserve1 *big.Int) *big.Int {
+func (u Uniswap) Value0(reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) {
 
-	sqrtReserve0 := big.NewInt(0).Sqrt(reserve0)
-	sqrtReserve1 := big.NewInt(0).Sqrt(reserve1)
+	pooled0, err := pooled(u.Liquidity, u.Pending0, u.Pending1, u.Rewards, reserve0, reserve1)
+	if err != nil {
+		return b.Amount{}, err
+	}
 
-	value0 := big.NewInt(0).Mul(u.Liquidity, sqrtReserve0)
-	value0.Div(value0, sqrtReserve1)
-	value0.Mul(value0, b.D2)
+	return b.Sum(pooled0, u.Fees0.Neg(), u.Cost0.Neg())
+}
+
+func (u Uniswap) Exit(reserve0 b.Amount, reserve1 b.Amount, swapRate b.Bps, cost0 b.Amount) (Exit, error) {
+
+	// Pending fees in token1 have to be swapped back as well.
+	fees0, err := withdraw(u.Liquidity, u.Pending1, reserve0, reserve1, swapRate)
+	if err != nil {
+		return Exit{}, err
+	}
+
+	value0, err := u.Value0(reserve0, reserve1)
+	if err != nil {
+		return Exit{}, err
+	}
+
+	exit := Exit{
+		Value0: value0,
+		Fees0:  fees0,
+		Cost0:  cost0,
+	}
+
+	return exit, nil
+}
+
+// Unharvested0 returns the value of the fees that were not yet added to the
+// liquidity of the position.
+func (u Uniswap) Unharvested0(reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) {
+	return unharvested(u.Pending0, u.Pending1, reserve0, reserve1)
+}
+
+// Compound adds the pending fees to the liquidity of the position.
+func (u *Uniswap) Compound(reserve0 b.Amount, reserve1 b.Amount) error {
+
+	liquidity, err := compound(u.Liquidity, u.Pending0, u.Pending1, reserve0, reserve1)
+	if err != nil {
+		return err
+	}
 
-	value0.Sub(value0, u.Fees0)
-	value0.Sub(value0, u.Cost0)
+	u.Liquidity = liquidity
+	u.Pending0 = b.NewAmount(b.D0, reserve0.Decimals())
+	u.Pending1 = b.NewAmount(b.D0, reserve1.Decimals())
 
-	return value0
+	return nil
 }
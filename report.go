@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/pflag"
+
+	"github.com/optakt/wilhelmus/archive"
+	"github.com/optakt/wilhelmus/report"
+)
+
+// reports renders the archive of a backtest run as a self-contained HTML
+// report with embedded charts.
+func reports(args []string) {
+
+	var (
+		logLevel    string
+		archiveFile string
+		output      string
+	)
+
+	flags := pflag.NewFlagSet("report", pflag.ExitOnError)
+
+	flags.StringVarP(&logLevel, "log-level", "l", "info", "Zerolog logger logging message severity")
+	flags.StringVarP(&archiveFile, "archive-file", "a", "archive.json", "JSON file with the archive of the backtest run")
+	flags.StringVar(&output, "output", "report.html", "HTML file for the report")
+
+	_ = flags.Parse(args)
+
+	log := zerolog.New(os.Stderr)
+	level, err := zerolog.ParseLevel(logLevel)
+	if err != nil {
+		log.Fatal().Err(err).Str("log_level", logLevel).Msg("invalid log level")
+	}
+	log = log.Level(level)
+
+	a, err := archive.Read(archiveFile)
+	if err != nil {
+		log.Fatal().Err(err).Str("archive_file", archiveFile).Msg("could not read archive")
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		log.Fatal().Err(err).Str("output", output).Msg("could not create report file")
+	}
+	defer file.Close()
+
+	err = report.Render(file, a)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not render report")
+	}
+
+	log.Info().
+		Str("archive_file", archiveFile).
+		Str("output", output).
+		Int("points", len(a.Points)).
+		Int("events", len(a.Events)).
+		Msg("report written")
+}
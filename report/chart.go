@@ -0,0 +1,155 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"math"
+	"strings"
+	"time"
+)
+
+// Dimensions of the charts in pixels, with the margins holding the axes.
+const (
+	width  = 960
+	height = 300
+	left   = 80
+	right  = 20
+	top    = 20
+	bottom = 40
+	ticks  = 5
+	limit  = 1200 // maximum number of points drawn per line
+)
+
+// palette holds the colors of the strategies, in the order of the run.
+var palette = []string{
+	"#4e79a7",
+	"#f28e2b",
+	"#e15759",
+	"#76b7b2",
+	"#59a14f",
+	"#edc948",
+	"#b07aa1",
+	"#ff9da7",
+	"#9c755f",
+	"#bab0ac",
+}
+
+// Line is a series of values drawn as one line on a chart.
+type Line struct {
+	Name   string
+	Color  string
+	Times  []time.Time
+	Values []float64
+}
+
+// Marker is a single point highlighted on a chart.
+type Marker struct {
+	Color string
+	Label string
+	Time  time.Time
+	Value float64
+}
+
+// Chart is a time series chart rendered as inline SVG.
+type Chart struct {
+	Title   string
+	Unit    string
+	Lines   []Line
+	Markers []Marker
+}
+
+// SVG renders the chart, with the time on the horizontal axis and the values
+// on the vertical axis, both scaled to the extent of the data.
+func (c Chart) SVG() template.HTML {
+
+	var start, end time.Time
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, line := range c.Lines {
+		for i, timestamp := range line.Times {
+			if start.IsZero() || timestamp.Before(start) {
+				start = timestamp
+			}
+			if timestamp.After(end) {
+				end = timestamp
+			}
+			low = math.Min(low, line.Values[i])
+			high = math.Max(high, line.Values[i])
+		}
+	}
+	for _, marker := range c.Markers {
+		low = math.Min(low, marker.Value)
+		high = math.Max(high, marker.Value)
+	}
+
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d">`, width, height, width, height)
+	if math.IsInf(low, 0) || !end.After(start) {
+		fmt.Fprintf(&svg, `<text x="%d" y="%d" text-anchor="middle">no data</text></svg>`, width/2, height/2)
+		return template.HTML(svg.String())
+	}
+
+	// Flat series get some room, so that they are drawn in the middle.
+	if high-low < 1e-9 {
+		low, high = low-1, high+1
+	}
+	padding := (high - low) * 0.05
+	low, high = low-padding, high+padding
+
+	x := func(timestamp time.Time) float64 {
+		return left + float64(timestamp.Sub(start))/float64(end.Sub(start))*(width-left-right)
+	}
+	y := func(value float64) float64 {
+		return top + (high-value)/(high-low)*(height-top-bottom)
+	}
+
+	// The grid has evenly spaced ticks on both axes, with the dates and the
+	// values as labels.
+	for i := 0; i <= ticks; i++ {
+		value := low + (high-low)*float64(i)/ticks
+		fmt.Fprintf(&svg, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#e0e0e0"/>`, left, y(value), width-right, y(value))
+		fmt.Fprintf(&svg, `<text x="%d" y="%.1f" text-anchor="end" font-size="11">%s</text>`, left-6, y(value)+4, format(value, c.Unit))
+
+		timestamp := start.Add(time.Duration(float64(end.Sub(start)) * float64(i) / ticks))
+		fmt.Fprintf(&svg, `<line x1="%.1f" y1="%d" x2="%.1f" y2="%d" stroke="#e0e0e0"/>`, x(timestamp), top, x(timestamp), height-bottom)
+		fmt.Fprintf(&svg, `<text x="%.1f" y="%d" text-anchor="middle" font-size="11">%s</text>`, x(timestamp), height-bottom+16, timestamp.Format("2006-01-02"))
+	}
+	fmt.Fprintf(&svg, `<rect x="%d" y="%d" width="%d" height="%d" fill="none" stroke="#999"/>`, left, top, width-left-right, height-top-bottom)
+
+	for _, line := range c.Lines {
+		stride := len(line.Times)/limit + 1
+		var points strings.Builder
+		for i := 0; i < len(line.Times); i += stride {
+			fmt.Fprintf(&points, "%.1f,%.1f ", x(line.Times[i]), y(line.Values[i]))
+		}
+		last := len(line.Times) - 1
+		if last >= 0 && last%stride != 0 {
+			fmt.Fprintf(&points, "%.1f,%.1f", x(line.Times[last]), y(line.Values[last]))
+		}
+		fmt.Fprintf(&svg, `<polyline fill="none" stroke="%s" stroke-width="1.5" points="%s"><title>%s</title></polyline>`,
+			line.Color, strings.TrimSpace(points.String()), html.EscapeString(line.Name))
+	}
+
+	for _, marker := range c.Markers {
+		fmt.Fprintf(&svg, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s" fill-opacity="0.7"><title>%s</title></circle>`,
+			x(marker.Time), y(marker.Value), marker.Color, html.EscapeString(marker.Label))
+	}
+
+	svg.WriteString(`</svg>`)
+
+	return template.HTML(svg.String())
+}
+
+// format prints a value for the axis labels, with the magnitude abbreviated.
+func format(value float64, unit string) string {
+	switch {
+	case unit == "%":
+		return fmt.Sprintf("%.1f%%", value*100)
+	case math.Abs(value) >= 1e6:
+		return fmt.Sprintf("%.2fM", value/1e6)
+	case math.Abs(value) >= 1e3:
+		return fmt.Sprintf("%.1fk", value/1e3)
+	default:
+		return fmt.Sprintf("%.2f", value)
+	}
+}
@@ -0,0 +1,76 @@
+package report
+
+import (
+	"html/template"
+)
+
+// functions are the helpers available to the layout.
+var functions = template.FuncMap{
+	"percent": func(value float64) string {
+		return format(value, "%")
+	},
+}
+
+// layout is the HTML page of the report; it uses no external assets.
+var layout = template.Must(template.New("report").Funcs(functions).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Backtest {{.Pair}} on {{.Chain}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em auto; max-width: 1000px; color: #222; }
+h1 { font-size: 1.5em; }
+h2 { font-size: 1.15em; margin-top: 2em; }
+table { border-collapse: collapse; font-size: 0.85em; }
+th, td { padding: 0.25em 0.75em; border-bottom: 1px solid #ddd; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+.legend span { display: inline-block; margin-right: 1.5em; }
+.legend i { display: inline-block; width: 1em; height: 0.6em; margin-right: 0.4em; }
+</style>
+</head>
+<body>
+<h1>Backtest {{.Pair}} on {{.Chain}}</h1>
+<p>From {{.Start.Format "2006-01-02 15:04"}} to {{.End.Format "2006-01-02 15:04"}} (UTC).</p>
+<p class="legend">{{range .Legend}}<span><i style="background: {{.Color}}"></i>{{.Name}}</span>{{end}}</p>
+
+{{with .Values}}<h2>{{.Title}}</h2>
+{{.SVG}}{{end}}
+
+{{with .Drawdowns}}<h2>{{.Title}}</h2>
+{{.SVG}}{{end}}
+
+{{with .Price}}<h2>{{.Title}}</h2>
+<p>Markers show the rehedges, rebalances, buys and option rolls of the strategies.</p>
+{{.SVG}}{{end}}
+<table>
+<tr><th>Strategy</th><th>Actions</th></tr>
+{{range $strategy, $count := .Events}}<tr><td>{{$strategy}}</td><td>{{$count}}</td></tr>
+{{end}}</table>
+
+{{with .Costs}}<h2>{{.Title}}</h2>
+{{.SVG}}{{end}}
+<table>
+<tr><th>Strategy</th><th>Fees</th><th>Costs</th><th>Total</th></tr>
+{{range .Breakdowns}}<tr><td>{{.Strategy}}</td><td>{{printf "%.2f" .Fees}}</td><td>{{printf "%.2f" .Costs}}</td><td>{{printf "%.2f" .Total}}</td></tr>
+{{end}}</table>
+
+{{if .Summaries}}<h2>Performance</h2>
+<table>
+<tr><th>Strategy</th><th>Value</th><th>Return</th><th>APR</th><th>APY</th><th>Volatility</th><th>Sharpe</th><th>Sortino</th><th>Max Drawdown</th><th>Duration</th><th>Calmar</th><th>Actions</th></tr>
+{{range .Summaries}}<tr><td>{{.Strategy}}</td><td>{{printf "%.2f" .Value}}</td><td>{{percent .Return}}</td><td>{{percent .APR}}</td><td>{{percent .APY}}</td><td>{{percent .Volatility}}</td><td>{{printf "%.2f" .Sharpe}}</td><td>{{printf "%.2f" .Sortino}}</td><td>{{percent .MaxDrawdown}}</td><td>{{.DrawdownDuration}}</td><td>{{printf "%.2f" .Calmar}}</td><td>{{.Actions}}</td></tr>
+{{end}}</table>{{end}}
+
+{{if .Comparisons}}<h2>Benchmark</h2>
+<table>
+<tr><th>Strategy</th><th>Benchmark</th><th>Excess</th><th>Tracking Error</th><th>Information Ratio</th><th>Underperforming Periods</th></tr>
+{{range .Comparisons}}<tr><td>{{.Strategy}}</td><td>{{.Benchmark}}</td><td>{{percent .Excess}}</td><td>{{percent .TrackingError}}</td><td>{{printf "%.2f" .InformationRatio}}</td><td>{{len .Periods}}</td></tr>
+{{end}}</table>{{end}}
+
+<h2>Parameters</h2>
+<table>
+<tr><th>Flag</th><th>Value</th></tr>
+{{range .Parameters}}<tr><td>{{.Name}}</td><td>{{.Value}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
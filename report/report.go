@@ -0,0 +1,146 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/optakt/wilhelmus/analytics"
+	"github.com/optakt/wilhelmus/archive"
+)
+
+// Legend entry of a strategy, with the color of its lines and markers.
+type Legend struct {
+	Name  string
+	Color string
+}
+
+// Parameter is a flag of the run, as it was set.
+type Parameter struct {
+	Name  string
+	Value string
+}
+
+// Breakdown holds the cumulative costs paid by a strategy until the end of
+// the run.
+type Breakdown struct {
+	Strategy string
+	Fees     float64
+	Costs    float64
+	Total    float64
+}
+
+// Page holds everything shown on the report.
+type Page struct {
+	Chain       string
+	Pair        string
+	Start       time.Time
+	End         time.Time
+	Legend      []Legend
+	Values      Chart
+	Drawdowns   Chart
+	Price       Chart
+	Costs       Chart
+	Breakdowns  []Breakdown
+	Events      map[string]int
+	Summaries   []analytics.Summary
+	Comparisons []analytics.Comparison
+	Parameters  []Parameter
+}
+
+// Render writes a self-contained HTML report of the given archive, with the
+// charts embedded as SVG, so that it can be viewed and shared without any
+// network access.
+func Render(w io.Writer, a *archive.Archive) error {
+
+	if len(a.Points) == 0 {
+		return fmt.Errorf("archive has no points")
+	}
+
+	page := Page{
+		Chain:       a.Chain,
+		Pair:        a.Pair,
+		Start:       a.Points[0].Timestamp,
+		End:         a.Points[len(a.Points)-1].Timestamp,
+		Values:      Chart{Title: "Value", Unit: "value"},
+		Drawdowns:   Chart{Title: "Drawdown", Unit: "%"},
+		Price:       Chart{Title: "Price", Unit: "price"},
+		Costs:       Chart{Title: "Cumulative fees and costs", Unit: "value"},
+		Events:      make(map[string]int),
+		Summaries:   a.Summaries,
+		Comparisons: a.Comparisons,
+	}
+
+	colors := make(map[string]string)
+	for i, strategy := range a.Strategies {
+		colors[strategy] = palette[i%len(palette)]
+		page.Legend = append(page.Legend, Legend{Name: strategy, Color: colors[strategy]})
+	}
+
+	times := make([]time.Time, 0, len(a.Points))
+	prices := make([]float64, 0, len(a.Points))
+	for _, point := range a.Points {
+		times = append(times, point.Timestamp)
+		prices = append(prices, point.Price)
+	}
+	page.Price.Lines = append(page.Price.Lines, Line{Name: "price", Color: "#333333", Times: times, Values: prices})
+
+	for _, strategy := range a.Strategies {
+
+		values := make([]float64, 0, len(a.Points))
+		drawdowns := make([]float64, 0, len(a.Points))
+		costs := make([]float64, 0, len(a.Points))
+		peak := 0.0
+		for _, point := range a.Points {
+			value := point.Values[strategy]
+			if value > peak {
+				peak = value
+			}
+			drawdown := 0.0
+			if peak > 0 {
+				drawdown = value/peak - 1
+			}
+			values = append(values, value)
+			drawdowns = append(drawdowns, drawdown)
+			costs = append(costs, point.Fees[strategy]+point.Costs[strategy])
+		}
+
+		color := colors[strategy]
+		page.Values.Lines = append(page.Values.Lines, Line{Name: strategy, Color: color, Times: times, Values: values})
+		page.Drawdowns.Lines = append(page.Drawdowns.Lines, Line{Name: strategy, Color: color, Times: times, Values: drawdowns})
+		page.Costs.Lines = append(page.Costs.Lines, Line{Name: strategy, Color: color, Times: times, Values: costs})
+
+		last := a.Points[len(a.Points)-1]
+		page.Breakdowns = append(page.Breakdowns, Breakdown{
+			Strategy: strategy,
+			Fees:     last.Fees[strategy],
+			Costs:    last.Costs[strategy],
+			Total:    last.Fees[strategy] + last.Costs[strategy],
+		})
+	}
+
+	for _, event := range a.Events {
+		page.Price.Markers = append(page.Price.Markers, Marker{
+			Color: colors[event.Strategy],
+			Label: fmt.Sprintf("%s %s at %.2f (%s)", event.Strategy, event.Kind, event.Price, event.Timestamp.Format(time.RFC3339)),
+			Time:  event.Timestamp,
+			Value: event.Price,
+		})
+		page.Events[event.Strategy]++
+	}
+
+	for name, value := range a.Parameters {
+		page.Parameters = append(page.Parameters, Parameter{Name: name, Value: value})
+	}
+	sort.Slice(page.Parameters, func(i int, j int) bool {
+		return page.Parameters[i].Name < page.Parameters[j].Name
+	})
+
+	err := layout.Execute(w, page)
+	if err != nil {
+		return fmt.Errorf("could not render report: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package reward
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+)
+
+// Feed holds the daily prices of the reward token, expressed as the amount of
+// token0, in its smallest unit, that one whole reward token is worth.
+type Feed struct {
+	prices map[time.Time]*big.Int
+}
+
+func NewFeed(file string, decimals0 uint) (*Feed, error) {
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read reward prices file: %w", err)
+	}
+
+	csvr := csv.NewReader(bytes.NewReader(data))
+	csvr.FieldsPerRecord = 2
+	records, err := csvr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("could not read reward price records: %w", err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("reward prices have no entries")
+	}
+
+	unit := big.NewInt(0).Exp(big.NewInt(10), big.NewInt(int64(decimals0)), nil)
+
+	prices := make(map[time.Time]*big.Int, len(records))
+	for _, record := range records[1:] {
+
+		date, err := time.Parse("1/2/2006", record[0])
+		if err != nil {
+			return nil, fmt.Errorf("could not parse reward price date: %w", err)
+		}
+
+		value, ok := big.NewRat(0, 1).SetString(record[1])
+		if !ok {
+			return nil, fmt.Errorf("could not parse reward price value (value: %s)", record[1])
+		}
+		value.Mul(value, big.NewRat(0, 1).SetInt(unit))
+
+		price := big.NewInt(0).Quo(value.Num(), value.Denom())
+
+		prices[date] = price
+	}
+
+	f := Feed{
+		prices: prices,
+	}
+
+	return &f, nil
+}
+
+func (f *Feed) Price(timestamp time.Time) (*big.Int, error) {
+
+	year, month, day := timestamp.Date()
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	price, ok := f.prices[date]
+	if !ok {
+		return nil, fmt.Errorf("reward price not found for date (timestamp: %s)", timestamp.Format(time.RFC3339))
+	}
+
+	return big.NewInt(0).Set(price), nil
+}
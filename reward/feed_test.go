@@ -0,0 +1,35 @@
+package reward
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewFeedMalformed(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "single column", content: "date\n1/2/2022\n"},
+		{name: "missing price", content: "date,price\n1/2/2022,1.5\n1/3/2022\n"},
+		{name: "extra column", content: "date,price\n1/2/2022,1.5,2\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			file := filepath.Join(t.TempDir(), "prices.csv")
+			err := os.WriteFile(file, []byte(test.content), 0644)
+			if err != nil {
+				t.Fatalf("could not write prices file: %s", err)
+			}
+
+			_, err = NewFeed(file, 6)
+			if err == nil {
+				t.Errorf("malformed prices file was accepted")
+			}
+		})
+	}
+}
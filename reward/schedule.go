@@ -0,0 +1,80 @@
+package reward
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"time"
+)
+
+// Schedule holds the emission rates of a liquidity mining program over time.
+// Each rate is the amount of reward tokens, in their smallest unit, that is
+// emitted per second to the whole pool and shared pro-rata by liquidity.
+type Schedule struct {
+	starts []time.Time
+	rates  []*big.Int
+}
+
+func NewSchedule(file string) (*Schedule, error) {
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read reward schedule file: %w", err)
+	}
+
+	csvr := csv.NewReader(bytes.NewReader(data))
+	csvr.FieldsPerRecord = 2
+	records, err := csvr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("could not read reward schedule records: %w", err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("reward schedule has no entries")
+	}
+
+	entries := records[1:]
+	sort.Slice(entries, func(i int, j int) bool {
+		return entries[i][0] < entries[j][0]
+	})
+
+	s := Schedule{
+		starts: make([]time.Time, 0, len(entries)),
+		rates:  make([]*big.Int, 0, len(entries)),
+	}
+	for _, entry := range entries {
+
+		start, err := time.Parse(time.RFC3339, entry[0])
+		if err != nil {
+			return nil, fmt.Errorf("could not parse reward schedule start: %w", err)
+		}
+
+		rate, ok := big.NewInt(0).SetString(entry[1], 10)
+		if !ok {
+			return nil, fmt.Errorf("could not parse reward schedule rate (rate: %s)", entry[1])
+		}
+
+		s.starts = append(s.starts, start)
+		s.rates = append(s.rates, rate)
+	}
+
+	return &s, nil
+}
+
+// Rate returns the emission rate that applies at the given timestamp, which is
+// zero before the start of the program.
+func (s *Schedule) Rate(timestamp time.Time) *big.Int {
+
+	index := sort.Search(len(s.starts), func(i int) bool {
+		return s.starts[i].After(timestamp)
+	})
+	if index == 0 {
+		return big.NewInt(0)
+	}
+
+	rate := big.NewInt(0).Set(s.rates[index-1])
+
+	return rate
+}
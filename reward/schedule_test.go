@@ -0,0 +1,35 @@
+package reward
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewScheduleMalformed(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "single column", content: "start\n2022-01-01T00:00:00Z\n"},
+		{name: "missing rate", content: "start,rate\n2022-01-01T00:00:00Z,100\n2022-02-01T00:00:00Z\n"},
+		{name: "extra column", content: "start,rate\n2022-01-01T00:00:00Z,100,200\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			file := filepath.Join(t.TempDir(), "schedule.csv")
+			err := os.WriteFile(file, []byte(test.content), 0644)
+			if err != nil {
+				t.Fatalf("could not write schedule file: %s", err)
+			}
+
+			_, err = NewSchedule(file)
+			if err == nil {
+				t.Errorf("malformed schedule file was accepted")
+			}
+		})
+	}
+}
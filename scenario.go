@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/optakt/wilhelmus/scenario"
+)
+
+// scenarios lists the built-in stress scenarios, or runs the backtest over
+// one of them and summarizes the main strategies side by side.
+func scenarios(args []string) {
+
+	log := zerolog.New(os.Stderr)
+
+	if len(args) == 0 {
+		log.Fatal().Msg("missing scenario command (list, run)")
+	}
+
+	switch args[0] {
+
+	case "list":
+		table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, "NAME\tCHAIN\tPAIR\tSTART\tEND\tDESCRIPTION")
+		for _, s := range scenario.All() {
+			fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\n", s.Name, s.Chain, s.Pair, s.Start.Format(time.RFC3339), s.End.Format(time.RFC3339), s.Description)
+		}
+		_ = table.Flush()
+
+	case "run":
+		if len(args) < 2 {
+			log.Fatal().Msg("missing scenario name")
+		}
+		s, err := scenario.Lookup(args[1])
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not find scenario")
+		}
+
+		// The scenario sets the chain, pair and time range, and any other flag
+		// is passed on to the backtest, so that the scenario can be run with
+		// any source and parameters.
+		run := []string{
+			"--chain-name", s.Chain,
+			"--pair-name", s.Pair,
+			"--start-time", s.Start.Format(time.RFC3339),
+			"--end-time", s.End.Format(time.RFC3339),
+		}
+		run = append(run, args[2:]...)
+
+		// The backtest logs to standard error, so that its tables and the
+		// summary of the scenario can be read on standard output.
+		r := backtest(run, os.Stderr)
+
+		err = summarize(s, r)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not summarize scenario")
+		}
+
+	default:
+		log.Fatal().Str("command", args[0]).Msg("unknown scenario command (list, run)")
+	}
+}
+
+// summarize prints the realized values of hold, uniswap and autohedge at the
+// end of the scenario, with their returns on the input and against hold.
+func summarize(s scenario.Scenario, r result) error {
+
+	input := r.Input0.Float()
+	hold0, err := r.Exits["hold"].Realizable0()
+	if err != nil {
+		return fmt.Errorf("could not compute hold realizable: %w", err)
+	}
+	hold := hold0.Float()
+
+	fmt.Printf("\nscenario %s: %s\n\n", s.Name, s.Description)
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(table, "STRATEGY\tVALUE\tREALIZABLE\tRETURN\tVS HOLD\n")
+	for _, strategy := range []string{"hold", "uniswap", "autohedge"} {
+
+		exit := r.Exits[strategy]
+		realizable0, err := exit.Realizable0()
+		if err != nil {
+			return fmt.Errorf("could not compute %s realizable: %w", strategy, err)
+		}
+		value := exit.Value0.Float()
+		realizable := realizable0.Float()
+
+		fmt.Fprintf(table, "%s\t%.2f\t%.2f\t%.2f%%\t%.2f%%\n",
+			strategy,
+			value,
+			realizable,
+			(realizable/input-1)*100,
+			(realizable/hold-1)*100,
+		)
+	}
+	_ = table.Flush()
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package scenario
+
+import (
+	"time"
+)
+
+// builtin holds the stress scenarios that ship with the tool; the windows
+// include a few days before and after each episode, so that strategies enter
+// in calm markets and the recovery is visible.
+var builtin = []Scenario{
+	{
+		Name:        "black-thursday",
+		Description: "March 2020 COVID crash, with ETH down about 40% in a day and congested gas markets",
+		Chain:       "Ethereum Mainnet",
+		Pair:        "USDC/WETH",
+		Start:       time.Date(2020, time.March, 9, 0, 0, 0, 0, time.UTC),
+		End:         time.Date(2020, time.March, 20, 0, 0, 0, 0, time.UTC),
+	},
+	{
+		Name:        "ust-collapse",
+		Description: "May 2022 depeg of UST and collapse of LUNA, with contagion across DeFi",
+		Chain:       "Ethereum Mainnet",
+		Pair:        "USDC/WETH",
+		Start:       time.Date(2022, time.May, 5, 0, 0, 0, 0, time.UTC),
+		End:         time.Date(2022, time.May, 20, 0, 0, 0, 0, time.UTC),
+	},
+	{
+		Name:        "3ac-deleveraging",
+		Description: "June 2022 deleveraging after the Celsius withdrawal freeze and the insolvency of Three Arrows Capital",
+		Chain:       "Ethereum Mainnet",
+		Pair:        "USDC/WETH",
+		Start:       time.Date(2022, time.June, 8, 0, 0, 0, 0, time.UTC),
+		End:         time.Date(2022, time.June, 25, 0, 0, 0, 0, time.UTC),
+	},
+	{
+		Name:        "ftx-collapse",
+		Description: "November 2022 collapse of the FTX exchange and the following market selloff",
+		Chain:       "Ethereum Mainnet",
+		Pair:        "USDC/WETH",
+		Start:       time.Date(2022, time.November, 4, 0, 0, 0, 0, time.UTC),
+		End:         time.Date(2022, time.November, 18, 0, 0, 0, 0, time.UTC),
+	},
+}
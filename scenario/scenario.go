@@ -0,0 +1,34 @@
+package scenario
+
+import (
+	"fmt"
+	"time"
+)
+
+// Scenario is a named market episode that strategies are tested against,
+// given as a time range on a chain and pair.
+type Scenario struct {
+	Name        string
+	Description string
+	Chain       string
+	Pair        string
+	Start       time.Time
+	End         time.Time
+}
+
+// Lookup returns the built-in scenario with the given name.
+func Lookup(name string) (Scenario, error) {
+	for _, scenario := range builtin {
+		if scenario.Name == name {
+			return scenario, nil
+		}
+	}
+	return Scenario{}, fmt.Errorf("unknown scenario (name: %s)", name)
+}
+
+// All returns the built-in scenarios in chronological order.
+func All() []Scenario {
+	all := make([]Scenario, len(builtin))
+	copy(all, builtin)
+	return all
+}
@@ -0,0 +1,66 @@
+package source
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+)
+
+// Fingerprint hashes the snapshots of a source as they are read, so that runs
+// can be checked to have used the same data. Snapshots that could not be
+// decoded are passed on without being hashed.
+type Fingerprint struct {
+	Records uint
+
+	source  Source
+	hash    hash.Hash
+	current Snapshot
+	err     error
+}
+
+func NewFingerprint(source Source) *Fingerprint {
+
+	f := Fingerprint{
+		source: source,
+		hash:   sha256.New(),
+	}
+
+	return &f
+}
+
+func (f *Fingerprint) Next() bool {
+
+	if !f.source.Next() {
+		return false
+	}
+
+	f.current, f.err = f.source.Snapshot()
+	if f.err != nil {
+		return true
+	}
+
+	_, _ = fmt.Fprintf(f.hash, "%d,%s,%s,%s,%s\n",
+		f.current.Timestamp.UnixNano(),
+		f.current.Reserve0,
+		f.current.Reserve1,
+		f.current.Volume0,
+		f.current.Volume1,
+	)
+	f.Records++
+
+	return true
+}
+
+func (f *Fingerprint) Snapshot() (Snapshot, error) {
+	return f.current, f.err
+}
+
+func (f *Fingerprint) Err() error {
+	return f.source.Err()
+}
+
+// Sum returns the hash of the snapshots read so far, in hex.
+func (f *Fingerprint) Sum() string {
+	return hex.EncodeToString(f.hash.Sum(nil))
+}
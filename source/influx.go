@@ -0,0 +1,80 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api"
+
+	"github.com/optakt/wilhelmus/b"
+)
+
+const statement = `from(bucket: "%s")
+	|> range(start: %s, stop: %s)
+	|> filter(fn: (r) => r["_measurement"] == "Uniswap v2")
+	|> filter(fn: (r) => r["chain"] == "%s")
+	|> filter(fn: (r) => r["pair"] == "%s")
+	|> filter(fn: (r) => r["_field"] == "volume0" or r["_field"] == "reserve1" or r["_field"] == "reserve0" or r["_field"] == "volume1")
+	|> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")`
+
+// Influx reads the snapshots of a pair from the metrics collected into an
+// InfluxDB bucket.
+type Influx struct {
+	result *api.QueryTableResult
+}
+
+func NewInflux(inbound api.QueryAPI, bucket string, start string, end string, chain string, pair string) (*Influx, error) {
+
+	query := fmt.Sprintf(statement, bucket, start, end, chain, pair)
+	result, err := inbound.Query(context.Background(), query)
+	if err != nil {
+		return nil, fmt.Errorf("could not execute query: %w", err)
+	}
+
+	i := Influx{
+		result: result,
+	}
+
+	return &i, nil
+}
+
+func (i *Influx) Next() bool {
+	return i.result.Next()
+}
+
+// Snapshot decodes the current record; on failure, the returned snapshot only
+// holds the timestamp of the record. The values from InfluxDB come as
+// hex-encoded strings, as InfluxDB doesn't support numbers above 64 bits and
+// `float64` is too imprecise for the reserves read from the contracts.
+func (i *Influx) Snapshot() (Snapshot, error) {
+
+	record := i.result.Record()
+	values := record.Values()
+	timestamp := record.Time()
+
+	fields := []string{"reserve0", "reserve1", "volume0", "volume1"}
+	decoded := make([]*big.Int, 0, len(fields))
+	for _, field := range fields {
+		value, err := b.FromHex(values[field])
+		if err != nil {
+			return Snapshot{Timestamp: timestamp}, fmt.Errorf("could not decode %s (timestamp: %s): %w", field, timestamp.Format(time.RFC3339), err)
+		}
+		decoded = append(decoded, value)
+	}
+
+	snapshot := Snapshot{
+		Timestamp: timestamp,
+		Reserve0:  decoded[0],
+		Reserve1:  decoded[1],
+		Volume0:   decoded[2],
+		Volume1:   decoded[3],
+	}
+
+	return snapshot, nil
+}
+
+func (i *Influx) Err() error {
+	return i.result.Err()
+}
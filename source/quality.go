@@ -0,0 +1,102 @@
+package source
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Policies for snapshots that can not be decoded or fail the checks.
+const (
+	PolicySkip  = "skip"  // drop the snapshot and continue with the next one
+	PolicyCarry = "carry" // repeat the previous reserves with zero volumes
+	PolicyAbort = "abort" // stop the source with an error
+)
+
+// Quality wraps a source and applies a data-quality policy to its invalid
+// snapshots, so that the strategies only ever see usable reserves.
+type Quality struct {
+	Policy  string
+	Skipped uint  // invalid snapshots that were dropped
+	Carried uint  // invalid snapshots replaced by the previous one
+	First   error // first issue found in the source
+
+	source   Source
+	current  Snapshot
+	previous *Snapshot
+	err      error
+}
+
+func NewQuality(source Source, policy string) (*Quality, error) {
+
+	switch policy {
+	case PolicySkip, PolicyCarry, PolicyAbort:
+	default:
+		return nil, fmt.Errorf("unknown data policy (policy: %s)", policy)
+	}
+
+	q := Quality{
+		Policy: policy,
+		source: source,
+	}
+
+	return &q, nil
+}
+
+func (q *Quality) Next() bool {
+
+	if q.err != nil {
+		return false
+	}
+
+	for q.source.Next() {
+
+		snapshot, err := q.source.Snapshot()
+		if err == nil {
+			err = Check(snapshot)
+		}
+		if err == nil {
+			q.current = snapshot
+			q.previous = &snapshot
+			return true
+		}
+
+		if q.First == nil {
+			q.First = err
+		}
+
+		switch {
+		case q.Policy == PolicyAbort:
+			q.err = err
+			return false
+
+		// Without a previous snapshot, there is nothing to carry forward, so
+		// we fall back to skipping.
+		case q.Policy == PolicyCarry && q.previous != nil:
+			q.current = Snapshot{
+				Timestamp: snapshot.Timestamp,
+				Reserve0:  q.previous.Reserve0,
+				Reserve1:  q.previous.Reserve1,
+				Volume0:   big.NewInt(0),
+				Volume1:   big.NewInt(0),
+			}
+			q.Carried++
+			return true
+
+		default:
+			q.Skipped++
+		}
+	}
+
+	return false
+}
+
+func (q *Quality) Snapshot() (Snapshot, error) {
+	return q.current, nil
+}
+
+func (q *Quality) Err() error {
+	if q.err != nil {
+		return q.err
+	}
+	return q.source.Err()
+}
@@ -0,0 +1,133 @@
+package source
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestQuality(t *testing.T) {
+
+	broken := errors.New("could not decode snapshot")
+
+	// The fixture has a valid snapshot, one that fails to decode, one with an
+	// empty reserve, and another valid one.
+	valid := []entry{
+		{snapshot: snap(0, 1_000, 2_000, 10, 20)},
+		{snapshot: Snapshot{Timestamp: snap(1, 0, 0, 0, 0).Timestamp}, err: broken},
+		{snapshot: snap(2, 0, 2_000, 10, 20)},
+		{snapshot: snap(3, 1_100, 1_900, 30, 40)},
+	}
+
+	// The same issues without a valid snapshot before them.
+	leading := []entry{
+		{snapshot: Snapshot{Timestamp: snap(0, 0, 0, 0, 0).Timestamp}, err: broken},
+		{snapshot: snap(1, 1_000, -1, 10, 20)},
+		{snapshot: snap(2, 1_100, 1_900, 30, 40)},
+	}
+
+	tests := []struct {
+		name    string
+		entries []entry
+		policy  string
+		want    []Snapshot
+		skipped uint
+		carried uint
+		abort   bool
+	}{
+		{
+			name:    "skip drops invalid snapshots",
+			entries: valid,
+			policy:  PolicySkip,
+			want:    []Snapshot{snap(0, 1_000, 2_000, 10, 20), snap(3, 1_100, 1_900, 30, 40)},
+			skipped: 2,
+		},
+		{
+			name:    "carry repeats previous reserves without volume",
+			entries: valid,
+			policy:  PolicyCarry,
+			want: []Snapshot{
+				snap(0, 1_000, 2_000, 10, 20),
+				snap(1, 1_000, 2_000, 0, 0),
+				snap(2, 1_000, 2_000, 0, 0),
+				snap(3, 1_100, 1_900, 30, 40),
+			},
+			carried: 2,
+		},
+		{
+			name:    "carry without previous snapshot skips",
+			entries: leading,
+			policy:  PolicyCarry,
+			want:    []Snapshot{snap(2, 1_100, 1_900, 30, 40)},
+			skipped: 2,
+		},
+		{
+			name:    "abort stops at first issue",
+			entries: valid,
+			policy:  PolicyAbort,
+			want:    []Snapshot{snap(0, 1_000, 2_000, 10, 20)},
+			abort:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			quality, err := NewQuality(&stub{entries: test.entries}, test.policy)
+			if err != nil {
+				t.Fatalf("could not create quality source: %s", err)
+			}
+
+			snapshots := drain(t, quality)
+			if len(snapshots) != len(test.want) {
+				t.Fatalf("wrong number of snapshots (have: %d, want: %d)", len(snapshots), len(test.want))
+			}
+			for i := range snapshots {
+				assertSnapshot(t, snapshots[i], test.want[i])
+			}
+
+			if quality.Skipped != test.skipped {
+				t.Errorf("wrong number of skipped snapshots (have: %d, want: %d)", quality.Skipped, test.skipped)
+			}
+			if quality.Carried != test.carried {
+				t.Errorf("wrong number of carried snapshots (have: %d, want: %d)", quality.Carried, test.carried)
+			}
+			if quality.First == nil {
+				t.Errorf("first issue not recorded")
+			}
+
+			err = quality.Err()
+			if test.abort && !errors.Is(err, broken) {
+				t.Errorf("wrong error after abort (have: %v, want: %s)", err, broken)
+			}
+			if !test.abort && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestQualityPolicy(t *testing.T) {
+
+	_, err := NewQuality(&stub{}, "repair")
+	if err == nil {
+		t.Errorf("expected error for unknown policy")
+	}
+}
+
+func TestQualitySourceError(t *testing.T) {
+
+	failed := errors.New("connection lost")
+
+	quality, err := NewQuality(&stub{entries: []entry{{snapshot: snap(0, 1_000, 2_000, 0, 0)}}, err: failed}, PolicySkip)
+	if err != nil {
+		t.Fatalf("could not create quality source: %s", err)
+	}
+
+	snapshots := drain(t, quality)
+	if len(snapshots) != 1 {
+		t.Fatalf("wrong number of snapshots (have: %d, want: 1)", len(snapshots))
+	}
+	if !errors.Is(quality.Err(), failed) {
+		t.Errorf("source error not passed on (have: %v, want: %s)", quality.Err(), failed)
+	}
+}
@@ -0,0 +1,128 @@
+package source
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// Resample aggregates the snapshots of a source to a fixed step. Snapshots are
+// grouped into intervals that end on a multiple of the step; each interval
+// yields the reserves of its last snapshot and the sum of its volumes, with
+// the timestamp of its end. With fill enabled, intervals without snapshots
+// repeat the previous reserves with zero volumes.
+type Resample struct {
+	Step time.Duration
+	Fill bool
+
+	source  Source
+	current Snapshot
+	pending *Snapshot // first snapshot of the next interval, read ahead
+	err     error
+}
+
+func NewResample(source Source, step time.Duration, fill bool) (*Resample, error) {
+
+	if step <= 0 {
+		return nil, fmt.Errorf("resample step must be positive (step: %s)", step)
+	}
+
+	r := Resample{
+		Step:   step,
+		Fill:   fill,
+		source: source,
+	}
+
+	return &r, nil
+}
+
+func (r *Resample) Next() bool {
+
+	if r.err != nil {
+		return false
+	}
+
+	if r.pending == nil && !r.read() {
+		return false
+	}
+
+	end := r.end(r.pending.Timestamp)
+
+	// When upsampling, we emit the missing intervals one by one before we
+	// consume the next snapshot.
+	if r.Fill && !r.current.Timestamp.IsZero() && end.Sub(r.current.Timestamp) > r.Step {
+		r.current = Snapshot{
+			Timestamp: r.current.Timestamp.Add(r.Step),
+			Reserve0:  r.current.Reserve0,
+			Reserve1:  r.current.Reserve1,
+			Volume0:   big.NewInt(0),
+			Volume1:   big.NewInt(0),
+		}
+		return true
+	}
+
+	aggregate := Snapshot{
+		Timestamp: end,
+		Reserve0:  r.pending.Reserve0,
+		Reserve1:  r.pending.Reserve1,
+		Volume0:   big.NewInt(0).Set(r.pending.Volume0),
+		Volume1:   big.NewInt(0).Set(r.pending.Volume1),
+	}
+	r.pending = nil
+
+	for r.read() {
+		if !r.end(r.pending.Timestamp).Equal(end) {
+			break
+		}
+		aggregate.Reserve0 = r.pending.Reserve0
+		aggregate.Reserve1 = r.pending.Reserve1
+		aggregate.Volume0.Add(aggregate.Volume0, r.pending.Volume0)
+		aggregate.Volume1.Add(aggregate.Volume1, r.pending.Volume1)
+		r.pending = nil
+	}
+	if r.err != nil {
+		return false
+	}
+
+	r.current = aggregate
+
+	return true
+}
+
+func (r *Resample) Snapshot() (Snapshot, error) {
+	return r.current, nil
+}
+
+func (r *Resample) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.source.Err()
+}
+
+// read reads the next snapshot of the source into pending.
+func (r *Resample) read() bool {
+
+	if !r.source.Next() {
+		return false
+	}
+
+	snapshot, err := r.source.Snapshot()
+	if err != nil {
+		r.err = fmt.Errorf("could not read snapshot to resample: %w", err)
+		return false
+	}
+	r.pending = &snapshot
+
+	return true
+}
+
+// end returns the end of the interval that contains the given timestamp;
+// timestamps on a multiple of the step end their own interval.
+func (r *Resample) end(timestamp time.Time) time.Time {
+	start := timestamp.Truncate(r.Step)
+	if start.Equal(timestamp) {
+		return timestamp
+	}
+	return start.Add(r.Step)
+}
@@ -0,0 +1,179 @@
+package source
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestResample(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		entries []entry
+		step    time.Duration
+		fill    bool
+		want    []Snapshot
+	}{
+		{
+			name: "downsampling sums volumes",
+			entries: []entry{
+				{snapshot: snap(1, 1_000, 2_000, 1, 2)},
+				{snapshot: snap(2, 1_010, 1_990, 3, 4)},
+				{snapshot: snap(4, 1_020, 1_980, 5, 6)},
+				{snapshot: snap(6, 1_030, 1_970, 7, 8)},
+			},
+			step: 5 * time.Minute,
+			want: []Snapshot{
+				snap(5, 1_020, 1_980, 9, 12),
+				snap(10, 1_030, 1_970, 7, 8),
+			},
+		},
+		{
+			name: "snapshot on step boundary ends its own interval",
+			entries: []entry{
+				{snapshot: snap(3, 1_000, 2_000, 1, 2)},
+				{snapshot: snap(5, 1_010, 1_990, 3, 4)},
+				{snapshot: snap(6, 1_020, 1_980, 5, 6)},
+				{snapshot: snap(10, 1_030, 1_970, 7, 8)},
+			},
+			step: 5 * time.Minute,
+			want: []Snapshot{
+				snap(5, 1_010, 1_990, 4, 6),
+				snap(10, 1_030, 1_970, 12, 14),
+			},
+		},
+		{
+			name: "gaps are skipped without fill",
+			entries: []entry{
+				{snapshot: snap(4, 1_000, 2_000, 1, 2)},
+				{snapshot: snap(19, 1_010, 1_990, 3, 4)},
+			},
+			step: 5 * time.Minute,
+			want: []Snapshot{
+				snap(5, 1_000, 2_000, 1, 2),
+				snap(20, 1_010, 1_990, 3, 4),
+			},
+		},
+		{
+			name: "gaps are forward filled",
+			entries: []entry{
+				{snapshot: snap(4, 1_000, 2_000, 1, 2)},
+				{snapshot: snap(19, 1_010, 1_990, 3, 4)},
+			},
+			step: 5 * time.Minute,
+			fill: true,
+			want: []Snapshot{
+				snap(5, 1_000, 2_000, 1, 2),
+				snap(10, 1_000, 2_000, 0, 0),
+				snap(15, 1_000, 2_000, 0, 0),
+				snap(20, 1_010, 1_990, 3, 4),
+			},
+		},
+		{
+			name: "upsampling forward fills every step",
+			entries: []entry{
+				{snapshot: snap(0, 1_000, 2_000, 1, 2)},
+				{snapshot: snap(3, 1_010, 1_990, 3, 4)},
+			},
+			step: time.Minute,
+			fill: true,
+			want: []Snapshot{
+				snap(0, 1_000, 2_000, 1, 2),
+				snap(1, 1_000, 2_000, 0, 0),
+				snap(2, 1_000, 2_000, 0, 0),
+				snap(3, 1_010, 1_990, 3, 4),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			resample, err := NewResample(&stub{entries: test.entries}, test.step, test.fill)
+			if err != nil {
+				t.Fatalf("could not create resample source: %s", err)
+			}
+
+			snapshots := drain(t, resample)
+			if len(snapshots) != len(test.want) {
+				t.Fatalf("wrong number of snapshots (have: %d, want: %d)", len(snapshots), len(test.want))
+			}
+			for i := range snapshots {
+				assertSnapshot(t, snapshots[i], test.want[i])
+			}
+
+			err = resample.Err()
+			if err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestResampleStep(t *testing.T) {
+
+	_, err := NewResample(&stub{}, 0, false)
+	if err == nil {
+		t.Errorf("expected error for zero step")
+	}
+}
+
+func TestResampleSnapshotError(t *testing.T) {
+
+	broken := errors.New("could not decode snapshot")
+
+	// The second interval fails on its second snapshot, so it is never
+	// emitted as a partial aggregate.
+	entries := []entry{
+		{snapshot: snap(1, 1_000, 2_000, 1, 2)},
+		{snapshot: snap(6, 1_010, 1_990, 3, 4)},
+		{snapshot: Snapshot{Timestamp: snap(7, 0, 0, 0, 0).Timestamp}, err: broken},
+		{snapshot: snap(8, 1_020, 1_980, 5, 6)},
+	}
+
+	resample, err := NewResample(&stub{entries: entries}, 5*time.Minute, false)
+	if err != nil {
+		t.Fatalf("could not create resample source: %s", err)
+	}
+
+	snapshots := drain(t, resample)
+	if len(snapshots) != 1 {
+		t.Fatalf("wrong number of snapshots (have: %d, want: 1)", len(snapshots))
+	}
+	assertSnapshot(t, snapshots[0], snap(5, 1_000, 2_000, 1, 2))
+
+	if !errors.Is(resample.Err(), broken) {
+		t.Errorf("wrong error (have: %v, want: %s)", resample.Err(), broken)
+	}
+	if resample.Next() {
+		t.Errorf("source continued after error")
+	}
+}
+
+func TestResampleSourceError(t *testing.T) {
+
+	failed := errors.New("connection lost")
+
+	// The source stops in the middle of an interval, which still yields what
+	// was read so far before the error is reported.
+	entries := []entry{
+		{snapshot: snap(1, 1_000, 2_000, 1, 2)},
+		{snapshot: snap(6, 1_010, 1_990, 3, 4)},
+	}
+
+	resample, err := NewResample(&stub{entries: entries, err: failed}, 5*time.Minute, false)
+	if err != nil {
+		t.Fatalf("could not create resample source: %s", err)
+	}
+
+	snapshots := drain(t, resample)
+	if len(snapshots) != 2 {
+		t.Fatalf("wrong number of snapshots (have: %d, want: 2)", len(snapshots))
+	}
+	assertSnapshot(t, snapshots[1], snap(10, 1_010, 1_990, 3, 4))
+
+	if !errors.Is(resample.Err(), failed) {
+		t.Errorf("wrong error (have: %v, want: %s)", resample.Err(), failed)
+	}
+}
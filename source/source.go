@@ -0,0 +1,48 @@
+package source
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// Snapshot is the state of a pair at one point in time, with the reserves as
+// read from the contract and the volumes swapped since the previous snapshot.
+// Values are in the token order of the pair contract.
+type Snapshot struct {
+	Timestamp time.Time
+	Reserve0  *big.Int
+	Reserve1  *big.Int
+	Volume0   *big.Int
+	Volume1   *big.Int
+}
+
+// Source provides the snapshots of a pair in chronological order.
+type Source interface {
+	// Next advances to the next snapshot and returns false once the source is
+	// exhausted or has failed.
+	Next() bool
+	// Snapshot returns the current snapshot, or an error if it could not be
+	// decoded, in which case only its timestamp is set.
+	Snapshot() (Snapshot, error)
+	// Err returns the error that stopped the source, if any.
+	Err() error
+}
+
+// Check verifies that the snapshot can be used by the strategies, which
+// divide by the reserves of the pair.
+func Check(snapshot Snapshot) error {
+
+	switch {
+	case snapshot.Reserve0.Sign() <= 0:
+		return fmt.Errorf("reserve0 is not positive (timestamp: %s)", snapshot.Timestamp.Format(time.RFC3339))
+	case snapshot.Reserve1.Sign() <= 0:
+		return fmt.Errorf("reserve1 is not positive (timestamp: %s)", snapshot.Timestamp.Format(time.RFC3339))
+	case snapshot.Volume0.Sign() < 0:
+		return fmt.Errorf("volume0 is negative (timestamp: %s)", snapshot.Timestamp.Format(time.RFC3339))
+	case snapshot.Volume1.Sign() < 0:
+		return fmt.Errorf("volume1 is negative (timestamp: %s)", snapshot.Timestamp.Format(time.RFC3339))
+	}
+
+	return nil
+}
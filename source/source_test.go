@@ -0,0 +1,86 @@
+package source
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+// stub is a source that replays fixed snapshots, some of which can fail to
+// decode, and then stops with the given error.
+type stub struct {
+	entries []entry
+	err     error
+	index   int
+}
+
+type entry struct {
+	snapshot Snapshot
+	err      error
+}
+
+func (s *stub) Next() bool {
+	if s.index >= len(s.entries) {
+		return false
+	}
+	s.index++
+	return true
+}
+
+func (s *stub) Snapshot() (Snapshot, error) {
+	current := s.entries[s.index-1]
+	return current.snapshot, current.err
+}
+
+func (s *stub) Err() error {
+	if s.index < len(s.entries) {
+		return nil
+	}
+	return s.err
+}
+
+// start is the timestamp of the first snapshot of the stubs.
+var start = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// snap returns a valid snapshot the given number of minutes after the start.
+func snap(minutes int, reserve0 int64, reserve1 int64, volume0 int64, volume1 int64) Snapshot {
+	return Snapshot{
+		Timestamp: start.Add(time.Duration(minutes) * time.Minute),
+		Reserve0:  big.NewInt(reserve0),
+		Reserve1:  big.NewInt(reserve1),
+		Volume0:   big.NewInt(volume0),
+		Volume1:   big.NewInt(volume1),
+	}
+}
+
+// drain reads all snapshots of the given source.
+func drain(t *testing.T, source Source) []Snapshot {
+	t.Helper()
+
+	var snapshots []Snapshot
+	for source.Next() {
+		snapshot, err := source.Snapshot()
+		if err != nil {
+			t.Fatalf("unexpected snapshot error: %s", err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots
+}
+
+// assertSnapshot fails the test if the snapshot differs from the expected one.
+func assertSnapshot(t *testing.T, have Snapshot, want Snapshot) {
+	t.Helper()
+
+	if !have.Timestamp.Equal(want.Timestamp) ||
+		have.Reserve0.Cmp(want.Reserve0) != 0 ||
+		have.Reserve1.Cmp(want.Reserve1) != 0 ||
+		have.Volume0.Cmp(want.Volume0) != 0 ||
+		have.Volume1.Cmp(want.Volume1) != 0 {
+		t.Errorf("wrong snapshot (have: %s %s/%s %s/%s, want: %s %s/%s %s/%s)",
+			have.Timestamp.Format(time.RFC3339), have.Reserve0, have.Reserve1, have.Volume0, have.Volume1,
+			want.Timestamp.Format(time.RFC3339), want.Reserve0, want.Reserve1, want.Volume0, want.Volume1,
+		)
+	}
+}
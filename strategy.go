@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/optakt/wilhelmus/attribution"
+	"github.com/optakt/wilhelmus/b"
+	"github.com/optakt/wilhelmus/position"
+)
+
+// strategy is one of the positions run by the backtest, with what the
+// analytics, the archive and the attribution read from it. Positions change
+// over the run, so their values are read through closures.
+type strategy struct {
+	Name    string
+	Size    uint64
+	Value0  func(reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error)
+	Fees0   func() b.Amount
+	Cost0   func() b.Amount
+	Action  string      // kind of the actions the strategy takes, if any
+	Actions func() uint // number of actions taken so far, nil if it takes none
+
+	// State returns the state of the strategy for the attribution against
+	// hold; it is nil for strategies that are not attributed.
+	State func(reserve0 b.Amount, reserve1 b.Amount) (attribution.State, error)
+}
+
+// actions returns the number of actions the strategy took so far.
+func (s strategy) actions() uint {
+	if s.Actions == nil {
+		return 0
+	}
+	return s.Actions()
+}
+
+// register lists the strategies of the run, in the order in which they are
+// reported; the perpetual and option hedges are only included when enabled.
+func register(
+	hold *position.Hold,
+	uniswap *position.Uniswap,
+	autohedge *position.Autohedge,
+	dca *position.DCA,
+	rebalance *position.Rebalance,
+	lend *position.Lend,
+	perpHedge *position.PerpHedge,
+	options *position.Options,
+) []strategy {
+
+	strategies := []strategy{
+		{
+			Name:   "hold",
+			Size:   hold.Size,
+			Value0: func(reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) { return hold.Value0(reserve0, reserve1) },
+			Fees0:  func() b.Amount { return hold.Fees0 },
+			Cost0:  func() b.Amount { return hold.Cost0 },
+		},
+		{
+			Name: "uniswap",
+			Size: uniswap.Size,
+			Value0: func(reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) {
+				return uniswap.Value0(reserve0, reserve1)
+			},
+			Fees0: func() b.Amount { return uniswap.Fees0 },
+			Cost0: func() b.Amount { return uniswap.Cost0 },
+			State: func(reserve0 b.Amount, reserve1 b.Amount) (attribution.State, error) {
+				value0, err := uniswap.Value0(reserve0, reserve1)
+				if err != nil {
+					return attribution.State{}, fmt.Errorf("could not compute value: %w", err)
+				}
+				rewards0, err := uniswap.Rewards.Net0()
+				if err != nil {
+					return attribution.State{}, fmt.Errorf("could not compute rewards: %w", err)
+				}
+				state := attribution.State{
+					Value0:    value0.Int(),
+					Liquidity: uniswap.Liquidity,
+					Extra1:    uniswap.Pending1.Int(),
+					Income0:   uniswap.Profit0.Int(),
+					Rewards0:  rewards0.Int(),
+					Yield0:    big.NewInt(0),
+					Interest1: big.NewInt(0),
+					Funding0:  big.NewInt(0),
+					Hedge0:    big.NewInt(0),
+					Fees0:     uniswap.Fees0.Int(),
+					Cost0:     uniswap.Cost0.Int(),
+				}
+				return state, nil
+			},
+		},
+		{
+			Name: "autohedge",
+			Size: autohedge.Size,
+			Value0: func(reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) {
+				return autohedge.Value0(reserve0, reserve1)
+			},
+			Fees0:   func() b.Amount { return autohedge.Fees0 },
+			Cost0:   func() b.Amount { return autohedge.Cost0 },
+			Action:  "rehedge",
+			Actions: func() uint { return autohedge.Count },
+			State: func(reserve0 b.Amount, reserve1 b.Amount) (attribution.State, error) {
+				value0, err := autohedge.Value0(reserve0, reserve1)
+				if err != nil {
+					return attribution.State{}, fmt.Errorf("could not compute value: %w", err)
+				}
+				rewards0, err := autohedge.Rewards.Net0()
+				if err != nil {
+					return attribution.State{}, fmt.Errorf("could not compute rewards: %w", err)
+				}
+				extra1, err := b.Sum(autohedge.Pending1, autohedge.Debt1.Neg(), autohedge.Interest1.Neg())
+				if err != nil {
+					return attribution.State{}, fmt.Errorf("could not compute extra amount: %w", err)
+				}
+				state := attribution.State{
+					Value0:    value0.Int(),
+					Liquidity: autohedge.Liquidity,
+					Extra1:    extra1.Int(),
+					Income0:   autohedge.Profit0.Int(),
+					Rewards0:  rewards0.Int(),
+					Yield0:    autohedge.Yield0.Int(),
+					Interest1: autohedge.Interest1.Int(),
+					Funding0:  big.NewInt(0),
+					Hedge0:    big.NewInt(0),
+					Fees0:     autohedge.Fees0.Int(),
+					Cost0:     autohedge.Cost0.Int(),
+				}
+				return state, nil
+			},
+		},
+		{
+			Name:    "dca",
+			Size:    dca.Size,
+			Value0:  func(reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) { return dca.Value0(reserve0, reserve1) },
+			Fees0:   func() b.Amount { return dca.Fees0 },
+			Cost0:   func() b.Amount { return dca.Cost0 },
+			Action:  "buy",
+			Actions: func() uint { return dca.Buys },
+		},
+		{
+			Name: "rebalance",
+			Size: rebalance.Size,
+			Value0: func(reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) {
+				return rebalance.Value0(reserve0, reserve1)
+			},
+			Fees0:   func() b.Amount { return rebalance.Fees0 },
+			Cost0:   func() b.Amount { return rebalance.Cost0 },
+			Action:  "rebalance",
+			Actions: func() uint { return rebalance.Count },
+		},
+		{
+			Name:   "lend",
+			Size:   lend.Size,
+			Value0: func(reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) { return lend.Value0(reserve0, reserve1) },
+			Fees0:  func() b.Amount { return b.NewAmount(big.NewInt(0), lend.Principal0.Decimals()) },
+			Cost0:  func() b.Amount { return lend.Cost0 },
+		},
+	}
+	if perpHedge != nil {
+		strategies = append(strategies, strategy{
+			Name: "perphedge",
+			Size: perpHedge.Size,
+			Value0: func(reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) {
+				return perpHedge.Value0(reserve0, reserve1)
+			},
+			Fees0:   func() b.Amount { return perpHedge.Fees0 },
+			Cost0:   func() b.Amount { return perpHedge.Cost0 },
+			Action:  "rehedge",
+			Actions: func() uint { return perpHedge.Count },
+			State: func(reserve0 b.Amount, reserve1 b.Amount) (attribution.State, error) {
+				value0, err := perpHedge.Value0(reserve0, reserve1)
+				if err != nil {
+					return attribution.State{}, fmt.Errorf("could not compute value: %w", err)
+				}
+				rewards0, err := perpHedge.Rewards.Net0()
+				if err != nil {
+					return attribution.State{}, fmt.Errorf("could not compute rewards: %w", err)
+				}
+				extra1, err := perpHedge.Pending1.Sub(perpHedge.Short1)
+				if err != nil {
+					return attribution.State{}, fmt.Errorf("could not compute extra amount: %w", err)
+				}
+				state := attribution.State{
+					Value0:    value0.Int(),
+					Liquidity: perpHedge.Liquidity,
+					Extra1:    extra1.Int(),
+					Income0:   perpHedge.Profit0.Int(),
+					Rewards0:  rewards0.Int(),
+					Yield0:    big.NewInt(0),
+					Interest1: big.NewInt(0),
+					Funding0:  perpHedge.Funding0.Int(),
+					Hedge0:    big.NewInt(0),
+					Fees0:     perpHedge.Fees0.Int(),
+					Cost0:     perpHedge.Cost0.Int(),
+				}
+				return state, nil
+			},
+		})
+	}
+	if options != nil {
+		strategies = append(strategies, strategy{
+			Name: "options",
+			Size: options.Size,
+			Value0: func(reserve0 b.Amount, reserve1 b.Amount) (b.Amount, error) {
+				return options.Value0(reserve0, reserve1)
+			},
+			Fees0:   func() b.Amount { return options.Fees0 },
+			Cost0:   func() b.Amount { return options.Cost0 },
+			Action:  "roll",
+			Actions: func() uint { return options.Rolls },
+			State: func(reserve0 b.Amount, reserve1 b.Amount) (attribution.State, error) {
+				value0, err := options.Value0(reserve0, reserve1)
+				if err != nil {
+					return attribution.State{}, fmt.Errorf("could not compute value: %w", err)
+				}
+				rewards0, err := options.Rewards.Net0()
+				if err != nil {
+					return attribution.State{}, fmt.Errorf("could not compute rewards: %w", err)
+				}
+				hedge0, err := b.Sum(options.Mark0, options.Payoff0, options.Premium0.Neg())
+				if err != nil {
+					return attribution.State{}, fmt.Errorf("could not compute hedge value: %w", err)
+				}
+				state := attribution.State{
+					Value0:    value0.Int(),
+					Liquidity: options.Liquidity,
+					Extra1:    options.Pending1.Int(),
+					Income0:   options.Profit0.Int(),
+					Rewards0:  rewards0.Int(),
+					Yield0:    big.NewInt(0),
+					Interest1: big.NewInt(0),
+					Funding0:  big.NewInt(0),
+					Hedge0:    hedge0.Int(),
+					Fees0:     options.Fees0.Int(),
+					Cost0:     options.Cost0.Int(),
+				}
+				return state, nil
+			},
+		})
+	}
+
+	return strategies
+}
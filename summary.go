@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/optakt/wilhelmus/analytics"
+	"github.com/optakt/wilhelmus/attribution"
+	"github.com/optakt/wilhelmus/token"
+)
+
+// tabulate prints the performance statistics of all strategies as a table,
+// with returns and ratios in percent.
+func tabulate(summaries []analytics.Summary) {
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "STRATEGY\t"+strings.ToUpper(strings.Join(analytics.Columns, "\t")))
+	for _, s := range summaries {
+		fmt.Fprintln(table, s.Strategy+"\t"+strings.Join(s.Cells(), "\t"))
+	}
+	_ = table.Flush()
+}
+
+// decompose prints the cumulative attribution of the liquidity positions
+// against hold as a table, in the stable coin.
+func decompose(attributions []attribution.Attribution, token0 token.Token) {
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "STRATEGY\tEXPOSURE\tIMPERMANENT\tINCOME\tREWARDS\tYIELD\tINTEREST\tFUNDING\tHEDGE\tFEES\tSLIPPAGE\tGAS\tRESIDUAL\tVS HOLD")
+	for _, a := range attributions {
+		total := a.Total
+		fmt.Fprintf(table, "%s\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\n",
+			a.Strategy,
+			token0.Float(total.Exposure0),
+			token0.Float(total.Impermanent0),
+			token0.Float(total.Income0),
+			token0.Float(total.Rewards0),
+			token0.Float(total.Yield0),
+			token0.Float(total.Interest0),
+			token0.Float(total.Funding0),
+			token0.Float(total.Hedge0),
+			token0.Float(total.Fees0),
+			token0.Float(total.Slippage0),
+			token0.Float(total.Gas0),
+			token0.Float(total.Residual0),
+			token0.Float(total.Sum()),
+		)
+	}
+	_ = table.Flush()
+}
+
+// compare prints the statistics of the strategies relative to the benchmark
+// as a table, with the longest and deepest underperformance periods.
+func compare(comparisons []analytics.Comparison) {
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "STRATEGY\tBENCHMARK\tEXCESS\tTRACKING ERROR\tINFORMATION RATIO\tUNDERPERFORMANCE\tLONGEST\tDEEPEST")
+	for _, c := range comparisons {
+
+		var (
+			longest time.Duration
+			deepest float64
+		)
+		for _, period := range c.Periods {
+			if period.End.Sub(period.Start) > longest {
+				longest = period.End.Sub(period.Start)
+			}
+			if period.Shortfall > deepest {
+				deepest = period.Shortfall
+			}
+		}
+
+		fmt.Fprintf(table, "%s\t%s\t%.2f%%\t%.2f%%\t%.2f\t%d\t%s\t%.2f%%\n",
+			c.Strategy,
+			c.Benchmark,
+			c.Excess*100,
+			c.TrackingError*100,
+			c.InformationRatio,
+			len(c.Periods),
+			longest,
+			deepest*100,
+		)
+	}
+	_ = table.Flush()
+}
@@ -0,0 +1,33 @@
+package synthetic
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// GBM models the price as a geometric Brownian motion.
+type GBM struct {
+	Drift      float64
+	Volatility float64
+}
+
+func NewGBM(drift float64, volatility float64) (*GBM, error) {
+
+	if volatility < 0 {
+		return nil, fmt.Errorf("volatility must not be negative (volatility: %f)", volatility)
+	}
+
+	g := GBM{
+		Drift:      drift,
+		Volatility: volatility,
+	}
+
+	return &g, nil
+}
+
+func (g *GBM) Return(rng *rand.Rand, interval time.Duration) float64 {
+	dt := fraction(interval)
+	return (g.Drift-g.Volatility*g.Volatility/2)*dt + g.Volatility*math.Sqrt(dt)*rng.NormFloat64()
+}
@@ -0,0 +1,230 @@
+package synthetic
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"time"
+
+	"github.com/optakt/wilhelmus/b"
+	"github.com/optakt/wilhelmus/source"
+	"github.com/optakt/wilhelmus/util"
+)
+
+// Generator is a source of synthetic snapshots for a constant-product pair.
+// At each step, the market price moves according to the price model, and
+// arbitrageurs swap against the pair until its price is back within the swap
+// fee of the market price. Noise traders then swap both ways according to the
+// turnover model. All swaps use the constant-product math of the pair, so the
+// reserves stay consistent and only grow by the swap fees.
+type Generator struct {
+	End      time.Time
+	Step     time.Duration
+	Model    Model
+	Turnover *Turnover
+	SwapRate b.Bps
+	Flipped  bool // whether the stable token is token1 of the pair
+
+	rng      *rand.Rand
+	price    float64 // market price of token1 in token0, in their smallest units
+	started  bool
+	current  source.Snapshot
+	reserve0 *big.Int
+	reserve1 *big.Int
+	err      error
+}
+
+// NewGenerator creates a generator from the given start to the given end, with
+// the given initial reserves in the token order of the pair contract.
+func NewGenerator(start time.Time, end time.Time, step time.Duration, reserve0 *big.Int, reserve1 *big.Int, model Model, turnover *Turnover, swapRate b.Bps, flipped bool, rng *rand.Rand) (*Generator, error) {
+
+	switch {
+	case !end.After(start):
+		return nil, fmt.Errorf("end must be after start (start: %s, end: %s)", start.Format(time.RFC3339), end.Format(time.RFC3339))
+	case step <= 0:
+		return nil, fmt.Errorf("step must be positive (step: %s)", step)
+	case reserve0.Sign() <= 0 || reserve1.Sign() <= 0:
+		return nil, fmt.Errorf("initial reserves must be positive")
+	case !swapRate.Valid():
+		return nil, fmt.Errorf("invalid swap rate (swap_rate: %f)", swapRate.Float())
+	}
+
+	price, _ := big.NewRat(0, 1).SetFrac(reserve0, reserve1).Float64()
+
+	g := Generator{
+		End:      end,
+		Step:     step,
+		Model:    model,
+		Turnover: turnover,
+		SwapRate: swapRate,
+		Flipped:  flipped,
+
+		rng:   rng,
+		price: price,
+		current: source.Snapshot{
+			Timestamp: start,
+			Reserve0:  reserve0,
+			Reserve1:  reserve1,
+			Volume0:   big.NewInt(0),
+			Volume1:   big.NewInt(0),
+		},
+		reserve0: big.NewInt(0).Set(reserve0),
+		reserve1: big.NewInt(0).Set(reserve1),
+	}
+
+	return &g, nil
+}
+
+func (g *Generator) Next() bool {
+
+	// The first snapshot is the initial state of the pair.
+	if !g.started {
+		g.started = true
+		return true
+	}
+
+	timestamp := g.current.Timestamp.Add(g.Step)
+	if timestamp.After(g.End) {
+		return false
+	}
+
+	// The models work on the price of the volatile token, so their returns
+	// are inverted when the volatile token is token0.
+	ret := g.Model.Return(g.rng, g.Step)
+	if g.Flipped {
+		ret = -ret
+	}
+	g.price *= math.Exp(ret)
+
+	volume0 := big.NewInt(0)
+	volume1 := big.NewInt(0)
+
+	err := g.arbitrage(volume0, volume1)
+	if err != nil {
+		g.err = fmt.Errorf("could not arbitrage pair: %w", err)
+		return false
+	}
+	err = g.noise(ret, volume0, volume1)
+	if err != nil {
+		g.err = fmt.Errorf("could not trade noise: %w", err)
+		return false
+	}
+
+	g.current = source.Snapshot{
+		Timestamp: timestamp,
+		Reserve0:  big.NewInt(0).Set(g.reserve0),
+		Reserve1:  big.NewInt(0).Set(g.reserve1),
+		Volume0:   volume0,
+		Volume1:   volume1,
+	}
+
+	return true
+}
+
+func (g *Generator) Snapshot() (source.Snapshot, error) {
+	return g.current, nil
+}
+
+func (g *Generator) Err() error {
+	return g.err
+}
+
+// arbitrage swaps against the pair until its price is at the edge of the
+// band around the market price in which arbitrage is no longer profitable.
+func (g *Generator) arbitrage(volume0 *big.Int, volume1 *big.Int) error {
+
+	keep := g.SwapRate.Complement().Float()
+	reserve0, _ := new(big.Float).SetInt(g.reserve0).Float64()
+	reserve1, _ := new(big.Float).SetInt(g.reserve1).Float64()
+	pool := reserve0 / reserve1
+
+	switch {
+
+	// Token1 is cheaper in the pair than on the market, so arbitrageurs buy
+	// it with token0 until the pair price is within the fee of the market.
+	case g.price*keep > pool:
+		amount0, _ := big.NewFloat(input(reserve0, reserve0*reserve1*g.price*keep, keep)).Int(nil)
+		if amount0.Sign() <= 0 {
+			return nil
+		}
+		return g.swap(amount0, true, volume0, volume1)
+
+	// Token1 is more expensive in the pair, so arbitrageurs sell it.
+	case g.price < pool*keep:
+		amount1, _ := big.NewFloat(input(reserve1, reserve0*reserve1/g.price*keep, keep)).Int(nil)
+		if amount1.Sign() <= 0 {
+			return nil
+		}
+		return g.swap(amount1, false, volume0, volume1)
+	}
+
+	return nil
+}
+
+// input returns the amount that has to be swapped into the given reserve so
+// that the product of the reserve after the swap and the reserve after the
+// swap without its fee reaches the given target. Since the fee stays in the
+// pair, this is what puts the price of the pair exactly at the edge of the
+// band; it solves keep*a^2 + (1+keep)*r*a + r^2 - target = 0 for a.
+func input(reserve float64, target float64, keep float64) float64 {
+	linear := (1 + keep) * reserve
+	constant := reserve*reserve - target
+	return (-linear + math.Sqrt(linear*linear-4*keep*constant)) / (2 * keep)
+}
+
+// noise swaps half of the traded value from token0 to token1 and the other
+// half back, which leaves the price mostly unchanged.
+func (g *Generator) noise(ret float64, volume0 *big.Int, volume1 *big.Int) error {
+
+	// The value locked is twice the reserve of token0 in token0.
+	traded := g.Turnover.Fraction(g.Step, ret)
+	amount0, _ := new(big.Float).Mul(new(big.Float).SetInt(g.reserve0), big.NewFloat(traded)).Int(nil)
+	if amount0.Sign() <= 0 {
+		return nil
+	}
+	amount1, err := util.Quote(units(amount0), units(g.reserve0), units(g.reserve1))
+	if err != nil {
+		return err
+	}
+
+	err = g.swap(amount0, true, volume0, volume1)
+	if err != nil {
+		return err
+	}
+
+	return g.swap(amount1.Int(), false, volume0, volume1)
+}
+
+// swap swaps the given input amount against the pair, in the direction from
+// token0 to token1 if zeroForOne is set, and adds it to the volumes.
+func (g *Generator) swap(amountIn *big.Int, zeroForOne bool, volume0 *big.Int, volume1 *big.Int) error {
+
+	if zeroForOne {
+		amountOut, err := util.CalculateAmountOut(units(amountIn), units(g.reserve0), units(g.reserve1), g.SwapRate)
+		if err != nil {
+			return err
+		}
+		g.reserve0.Add(g.reserve0, amountIn)
+		g.reserve1.Sub(g.reserve1, amountOut.Int())
+		volume0.Add(volume0, amountIn)
+		return nil
+	}
+
+	amountOut, err := util.CalculateAmountOut(units(amountIn), units(g.reserve1), units(g.reserve0), g.SwapRate)
+	if err != nil {
+		return err
+	}
+	g.reserve1.Add(g.reserve1, amountIn)
+	g.reserve0.Sub(g.reserve0, amountOut.Int())
+	volume1.Add(volume1, amountIn)
+
+	return nil
+}
+
+// units wraps the given value as an amount without decimals; the generator
+// works on the reserves of the pair contract in the smallest units of their
+// tokens and does not know their decimals.
+func units(value *big.Int) b.Amount {
+	return b.NewAmount(value, 0)
+}
@@ -0,0 +1,89 @@
+package synthetic
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/optakt/wilhelmus/b"
+)
+
+func TestGeneratorInvariant(t *testing.T) {
+
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(30 * 24 * time.Hour)
+
+	// 10M USDC against 5k WETH, at a price of 2000 USDC per WETH.
+	reserve0 := big.NewInt(10_000_000_000_000)
+	reserve1, _ := big.NewInt(0).SetString("5000000000000000000000", 10)
+
+	tests := []struct {
+		name      string
+		rate      int64
+		base      float64
+		flipped   bool
+		arbitrage bool // whether the price is only moved by arbitrage
+	}{
+		{name: "arbitrage only", rate: 30, flipped: false, arbitrage: true},
+		{name: "arbitrage only flipped", rate: 30, flipped: true, arbitrage: true},
+		{name: "arbitrage only lower fee", rate: 5, flipped: false, arbitrage: true},
+		{name: "with noise traders", rate: 30, base: 0.5, flipped: false},
+		{name: "with noise traders flipped", rate: 30, base: 0.5, flipped: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			model, err := NewGBM(0, 1.5)
+			if err != nil {
+				t.Fatalf("could not create model: %s", err)
+			}
+			turnover, err := NewTurnover(test.base, 0)
+			if err != nil {
+				t.Fatalf("could not create turnover: %s", err)
+			}
+			swapRate := b.NewBps(big.NewInt(test.rate))
+
+			generator, err := NewGenerator(start, end, time.Hour, reserve0, reserve1, model, turnover, swapRate, test.flipped, rand.New(rand.NewSource(1)))
+			if err != nil {
+				t.Fatalf("could not create generator: %s", err)
+			}
+
+			keep := swapRate.Complement().Float()
+			k := big.NewInt(0).Mul(reserve0, reserve1)
+			records := 0
+			for generator.Next() {
+
+				snapshot, err := generator.Snapshot()
+				if err != nil {
+					t.Fatalf("unexpected snapshot error: %s", err)
+				}
+				records++
+
+				// Swaps only ever add their fee to the reserves.
+				product := big.NewInt(0).Mul(snapshot.Reserve0, snapshot.Reserve1)
+				if product.Cmp(k) < 0 {
+					t.Fatalf("reserve product decreased (timestamp: %s, product: %s, previous: %s)", snapshot.Timestamp, product, k)
+				}
+				k = product
+
+				if !test.arbitrage {
+					continue
+				}
+
+				// After arbitrage, the pool price is within the fee of the
+				// market price, up to the precision of the floats.
+				pool, _ := big.NewRat(0, 1).SetFrac(snapshot.Reserve0, snapshot.Reserve1).Float64()
+				ratio := pool / generator.price
+				if ratio < keep*(1-1e-9) || ratio > 1/keep*(1+1e-9) {
+					t.Fatalf("pool price outside fee band of market (timestamp: %s, ratio: %f, band: %f-%f)", snapshot.Timestamp, ratio, keep, 1/keep)
+				}
+			}
+
+			if records != 30*24+1 {
+				t.Errorf("wrong number of records (have: %d, want: %d)", records, 30*24+1)
+			}
+		})
+	}
+}
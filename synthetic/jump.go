@@ -0,0 +1,59 @@
+package synthetic
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Jump models the price as a Merton jump-diffusion: a geometric Brownian
+// motion with jumps that arrive as a Poisson process and have normally
+// distributed log sizes. The drift is the one of the diffusion only, so that
+// crashes are not offset by a higher drift in between.
+type Jump struct {
+	GBM
+	Intensity      float64 // expected number of jumps per year
+	JumpMean       float64 // mean log size of a jump
+	JumpVolatility float64 // standard deviation of the log size of a jump
+}
+
+func NewJump(drift float64, volatility float64, intensity float64, jumpMean float64, jumpVolatility float64) (*Jump, error) {
+
+	switch {
+	case volatility < 0:
+		return nil, fmt.Errorf("volatility must not be negative (volatility: %f)", volatility)
+	case intensity < 0:
+		return nil, fmt.Errorf("jump intensity must not be negative (intensity: %f)", intensity)
+	case jumpVolatility < 0:
+		return nil, fmt.Errorf("jump volatility must not be negative (jump_volatility: %f)", jumpVolatility)
+	}
+
+	j := Jump{
+		GBM: GBM{
+			Drift:      drift,
+			Volatility: volatility,
+		},
+		Intensity:      intensity,
+		JumpMean:       jumpMean,
+		JumpVolatility: jumpVolatility,
+	}
+
+	return &j, nil
+}
+
+func (j *Jump) Return(rng *rand.Rand, interval time.Duration) float64 {
+
+	ret := j.GBM.Return(rng, interval)
+
+	// We draw the number of jumps with Knuth's algorithm, which is fine for
+	// the small expected counts per step.
+	limit := math.Exp(-j.Intensity * fraction(interval))
+	product := rng.Float64()
+	for product > limit {
+		ret += j.JumpMean + j.JumpVolatility*rng.NormFloat64()
+		product *= rng.Float64()
+	}
+
+	return ret
+}
@@ -0,0 +1,20 @@
+package synthetic
+
+import (
+	"math/rand"
+	"time"
+)
+
+const year = 365 * 24 * time.Hour
+
+// Model is a price model for the volatile token of a pair, in the stable
+// token. Drifts and volatilities of the models are annualized.
+type Model interface {
+	// Return draws the log return of the price over the given interval.
+	Return(rng *rand.Rand, interval time.Duration) float64
+}
+
+// fraction converts the given interval into a fraction of a year.
+func fraction(interval time.Duration) float64 {
+	return float64(interval) / float64(year)
+}
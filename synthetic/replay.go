@@ -0,0 +1,111 @@
+package synthetic
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/optakt/wilhelmus/source"
+)
+
+// Replay replays the historical log returns of a pair, multiplied by a scale
+// so that moves can be amplified or dampened. Each call to `Return` yields the
+// sum of the next historical returns that cover the interval, given the
+// cadence of the history, and the history starts over once it is exhausted.
+type Replay struct {
+	Scale   float64
+	Cadence time.Duration // typical interval between the historical snapshots
+	returns []float64
+	index   int
+}
+
+// NewReplay reads the returns from the given source; flipped indicates that
+// the stable token is token1 of the pair. The step at which returns are drawn
+// has to be a whole multiple of the cadence of the history, so that replayed
+// moves keep their historical size.
+func NewReplay(data source.Source, flipped bool, scale float64, step time.Duration) (*Replay, error) {
+
+	var (
+		returns   []float64
+		intervals []time.Duration
+		previous  float64
+		last      time.Time
+	)
+	for data.Next() {
+
+		snapshot, err := data.Snapshot()
+		if err != nil {
+			return nil, fmt.Errorf("could not read snapshot to replay: %w", err)
+		}
+
+		price, _ := big.NewRat(0, 1).SetFrac(snapshot.Reserve0, snapshot.Reserve1).Float64()
+		if flipped {
+			price = 1 / price
+		}
+
+		if previous != 0 {
+			returns = append(returns, math.Log(price/previous))
+			intervals = append(intervals, snapshot.Timestamp.Sub(last))
+		}
+		previous = price
+		last = snapshot.Timestamp
+	}
+
+	err := data.Err()
+	if err != nil {
+		return nil, fmt.Errorf("could not read source to replay: %w", err)
+	}
+	if len(returns) == 0 {
+		return nil, fmt.Errorf("not enough snapshots to replay")
+	}
+
+	// The median interval is robust against the gaps in the history.
+	sort.Slice(intervals, func(i int, j int) bool {
+		return intervals[i] < intervals[j]
+	})
+	cadence := intervals[len(intervals)/2]
+	if cadence <= 0 {
+		return nil, fmt.Errorf("history has no cadence (cadence: %s)", cadence)
+	}
+
+	r := Replay{
+		Scale:   scale,
+		Cadence: cadence,
+		returns: returns,
+		index:   0,
+	}
+
+	// We allow for a tenth of the cadence of jitter in the timestamps.
+	count := r.count(step)
+	mismatch := step - time.Duration(count)*cadence
+	if mismatch < 0 {
+		mismatch = -mismatch
+	}
+	if step < cadence || mismatch > cadence/10 {
+		return nil, fmt.Errorf("step is not a multiple of the history cadence (step: %s, cadence: %s)", step, cadence)
+	}
+
+	return &r, nil
+}
+
+func (r *Replay) Return(_ *rand.Rand, interval time.Duration) float64 {
+	ret := 0.0
+	for i := 0; i < r.count(interval); i++ {
+		ret += r.returns[r.index]
+		r.index = (r.index + 1) % len(r.returns)
+	}
+	return ret * r.Scale
+}
+
+// count returns the number of historical returns that cover the interval,
+// which is at least one.
+func (r *Replay) count(interval time.Duration) int {
+	count := int(math.Round(float64(interval) / float64(r.Cadence)))
+	if count < 1 {
+		return 1
+	}
+	return count
+}
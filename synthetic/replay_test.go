@@ -0,0 +1,121 @@
+package synthetic
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/optakt/wilhelmus/source"
+)
+
+// history is a source of snapshots with the given prices of token1 in token0,
+// one per cadence.
+type history struct {
+	snapshots []source.Snapshot
+	index     int
+}
+
+func newHistory(cadence time.Duration, prices ...int64) *history {
+
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := history{}
+	for i, price := range prices {
+		h.snapshots = append(h.snapshots, source.Snapshot{
+			Timestamp: start.Add(time.Duration(i) * cadence),
+			Reserve0:  big.NewInt(price * 1_000),
+			Reserve1:  big.NewInt(1_000),
+			Volume0:   big.NewInt(0),
+			Volume1:   big.NewInt(0),
+		})
+	}
+
+	return &h
+}
+
+func (h *history) Next() bool {
+	if h.index >= len(h.snapshots) {
+		return false
+	}
+	h.index++
+	return true
+}
+
+func (h *history) Snapshot() (source.Snapshot, error) {
+	return h.snapshots[h.index-1], nil
+}
+
+func (h *history) Err() error {
+	return nil
+}
+
+func TestReplayReturn(t *testing.T) {
+
+	prices := []int64{100, 110, 121, 100, 90}
+
+	tests := []struct {
+		name  string
+		step  time.Duration
+		scale float64
+		want  []float64
+	}{
+		{
+			name:  "same cadence",
+			step:  time.Minute,
+			scale: 1,
+			want:  []float64{math.Log(1.1), math.Log(1.1), math.Log(100.0 / 121), math.Log(0.9), math.Log(1.1)},
+		},
+		{
+			name:  "multiple of cadence",
+			step:  2 * time.Minute,
+			scale: 1,
+			want:  []float64{math.Log(1.21), math.Log(90.0 / 121), math.Log(1.21)},
+		},
+		{
+			name:  "scaled",
+			step:  time.Minute,
+			scale: 2,
+			want:  []float64{2 * math.Log(1.1), 2 * math.Log(1.1)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			replay, err := NewReplay(newHistory(time.Minute, prices...), false, test.scale, test.step)
+			if err != nil {
+				t.Fatalf("could not create replay: %s", err)
+			}
+			if replay.Cadence != time.Minute {
+				t.Errorf("wrong cadence (have: %s, want: %s)", replay.Cadence, time.Minute)
+			}
+
+			for i, want := range test.want {
+				have := replay.Return(nil, test.step)
+				if math.Abs(have-want) > 1e-12 {
+					t.Errorf("wrong return (index: %d, have: %f, want: %f)", i, have, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReplayStep(t *testing.T) {
+
+	tests := []struct {
+		name string
+		step time.Duration
+	}{
+		{name: "shorter than cadence", step: 30 * time.Second},
+		{name: "not a multiple of cadence", step: 90 * time.Second},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewReplay(newHistory(time.Minute, 100, 110, 121), false, 1, test.step)
+			if err == nil {
+				t.Errorf("expected error for step (step: %s)", test.step)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package synthetic
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Regime is one state of a regime-switching model, with its expected
+// duration before the model switches to another regime.
+type Regime struct {
+	Drift      float64
+	Volatility float64
+	Duration   time.Duration
+}
+
+// ParseRegime parses a regime given as `drift:volatility:duration`, such as
+// `-1.5:1.2:168h`.
+func ParseRegime(s string) (Regime, error) {
+
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return Regime{}, fmt.Errorf("regime must have drift, volatility and duration (regime: %s)", s)
+	}
+
+	drift, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return Regime{}, fmt.Errorf("could not parse regime drift: %w", err)
+	}
+	volatility, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil {
+		return Regime{}, fmt.Errorf("could not parse regime volatility: %w", err)
+	}
+	duration, err := time.ParseDuration(parts[2])
+	if err != nil {
+		return Regime{}, fmt.Errorf("could not parse regime duration: %w", err)
+	}
+
+	regime := Regime{
+		Drift:      drift,
+		Volatility: volatility,
+		Duration:   duration,
+	}
+
+	return regime, nil
+}
+
+// Switching models the price as a geometric Brownian motion whose drift and
+// volatility switch between regimes at random. It starts in the first regime
+// and switches to one of the other regimes with equal probability.
+type Switching struct {
+	Regimes []Regime
+	Current int
+}
+
+func NewSwitching(regimes ...Regime) (*Switching, error) {
+
+	if len(regimes) < 2 {
+		return nil, fmt.Errorf("regime switching needs at least two regimes (regimes: %d)", len(regimes))
+	}
+	for _, regime := range regimes {
+		switch {
+		case regime.Volatility < 0:
+			return nil, fmt.Errorf("regime volatility must not be negative (volatility: %f)", regime.Volatility)
+		case regime.Duration <= 0:
+			return nil, fmt.Errorf("regime duration must be positive (duration: %s)", regime.Duration)
+		}
+	}
+
+	s := Switching{
+		Regimes: regimes,
+		Current: 0,
+	}
+
+	return &s, nil
+}
+
+func (s *Switching) Return(rng *rand.Rand, interval time.Duration) float64 {
+
+	regime := s.Regimes[s.Current]
+	leave := 1 - math.Exp(-float64(interval)/float64(regime.Duration))
+	if rng.Float64() < leave {
+		next := rng.Intn(len(s.Regimes) - 1)
+		if next >= s.Current {
+			next++
+		}
+		s.Current = next
+		regime = s.Regimes[next]
+	}
+
+	g := GBM{
+		Drift:      regime.Drift,
+		Volatility: regime.Volatility,
+	}
+
+	return g.Return(rng, interval)
+}
@@ -0,0 +1,38 @@
+package synthetic
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Turnover models the volume of noise traders as a fraction of the value
+// locked in the pair: a base daily turnover, plus a share that grows with the
+// size of the price move, as volume picks up in volatile markets.
+type Turnover struct {
+	Base        float64 // fraction of the value locked traded per day
+	Sensitivity float64 // fraction of the value locked traded per unit of absolute log return
+}
+
+func NewTurnover(base float64, sensitivity float64) (*Turnover, error) {
+
+	switch {
+	case base < 0:
+		return nil, fmt.Errorf("base turnover must not be negative (base: %f)", base)
+	case sensitivity < 0:
+		return nil, fmt.Errorf("turnover sensitivity must not be negative (sensitivity: %f)", sensitivity)
+	}
+
+	t := Turnover{
+		Base:        base,
+		Sensitivity: sensitivity,
+	}
+
+	return &t, nil
+}
+
+// Fraction returns the fraction of the value locked that is traded over the
+// given interval with the given log return.
+func (t *Turnover) Fraction(interval time.Duration, ret float64) float64 {
+	return t.Base*float64(interval)/float64(24*time.Hour) + t.Sensitivity*math.Abs(ret)
+}
@@ -0,0 +1,22 @@
+package token
+
+// defaults holds the built-in tokens and Uniswap v2 pairs.
+const defaults = `[
+	{
+		"chain": "Ethereum Mainnet",
+		"slug": "ethereum",
+		"tokens": [
+			{"symbol": "USDC", "address": "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", "decimals": 6, "stable": true},
+			{"symbol": "USDT", "address": "0xdAC17F958D2ee523a2206206994597C13D831ec7", "decimals": 6, "stable": true},
+			{"symbol": "DAI", "address": "0x6B175474E89094C44Da98b954EedeAC495271d0F", "decimals": 18, "stable": true},
+			{"symbol": "WETH", "address": "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", "decimals": 18, "stable": false, "native": true},
+			{"symbol": "WBTC", "address": "0x2260FAC5E5542a773Aa44fBCfeDf7C193bc2C599", "decimals": 8, "stable": false}
+		],
+		"pairs": [
+			{"address": "0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc", "token0": "USDC", "token1": "WETH"},
+			{"address": "0xA478c2975Ab1Ea89e8196811F51A7B7Ade33eB11", "token0": "DAI", "token1": "WETH"},
+			{"address": "0x0d4a11d5EEaaC28EC3F61d100daF4d40471f1852", "token0": "WETH", "token1": "USDT"},
+			{"address": "0x004375Dff511095CC5A197A54140a24eFEF3A416", "token0": "WBTC", "token1": "USDC"}
+		]
+	}
+]`
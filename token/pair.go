@@ -0,0 +1,60 @@
+package token
+
+import (
+	"math/big"
+
+	"github.com/optakt/wilhelmus/b"
+)
+
+// Pair holds the metadata of a Uniswap v2 pair, with its tokens in the same
+// order as in the pair contract. Exactly one of the tokens is stable.
+type Pair struct {
+	Chain     string // display name of the chain, such as "Ethereum Mainnet"
+	ChainSlug string // short name of the chain used for tags, such as "ethereum"
+	Name      string
+	Address   string
+	Token0    Token
+	Token1    Token
+}
+
+// Flipped returns whether the stable token is token1 of the pair.
+func (p Pair) Flipped() bool {
+	return p.Token1.Stable
+}
+
+// Stable returns the stable token of the pair.
+func (p Pair) Stable() Token {
+	if p.Flipped() {
+		return p.Token1
+	}
+	return p.Token0
+}
+
+// Volatile returns the volatile token of the pair.
+func (p Pair) Volatile() Token {
+	if p.Flipped() {
+		return p.Token0
+	}
+	return p.Token1
+}
+
+// Orient takes two values in the order of the pair contract, and returns them
+// with the value of the stable token first.
+func (p Pair) Orient(value0 *big.Int, value1 *big.Int) (*big.Int, *big.Int) {
+	if p.Flipped() {
+		return value1, value0
+	}
+	return value0, value1
+}
+
+// Amounts takes two values in the order of the pair contract, and returns them
+// as amounts of the stable and the volatile token, in that order.
+func (p Pair) Amounts(value0 *big.Int, value1 *big.Int) (b.Amount, b.Amount) {
+	stable, volatile := p.Orient(value0, value1)
+	return p.Stable().Amount(stable), p.Volatile().Amount(volatile)
+}
+
+// Liquidity converts the given liquidity of the pair to a float.
+func (p Pair) Liquidity(amount *big.Int) float64 {
+	return liquidity(amount, p.Token0, p.Token1)
+}
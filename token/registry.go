@@ -0,0 +1,151 @@
+package token
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Registry holds the tokens and pairs known on each chain. It starts out with
+// built-in defaults, which can be extended or overridden with JSON files.
+type Registry struct {
+	chains map[string]*chain
+}
+
+// chain is the JSON format of the tokens and pairs of one chain; pairs refer
+// to their tokens by symbol, in the order of the pair contract. The slug is
+// the short name of the chain that points written to InfluxDB are tagged with.
+type chain struct {
+	Name   string  `json:"chain"`
+	Slug   string  `json:"slug"`
+	Tokens []Token `json:"tokens"`
+	Pairs  []struct {
+		Address string `json:"address"`
+		Token0  string `json:"token0"`
+		Token1  string `json:"token1"`
+	} `json:"pairs"`
+}
+
+func NewRegistry(files ...string) (*Registry, error) {
+
+	r := Registry{
+		chains: make(map[string]*chain),
+	}
+
+	err := json.Unmarshal([]byte(defaults), &r)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode default tokens: %w", err)
+	}
+
+	for _, file := range files {
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("could not read token file: %w", err)
+		}
+
+		err = json.Unmarshal(data, &r)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode token file (file: %s): %w", file, err)
+		}
+	}
+
+	return &r, nil
+}
+
+// UnmarshalJSON merges a list of chains into the registry, so that tokens and
+// pairs of later files override the ones with the same symbols or names.
+func (r *Registry) UnmarshalJSON(data []byte) error {
+
+	var chains []chain
+	err := json.Unmarshal(data, &chains)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range chains {
+		existing, ok := r.chains[c.Name]
+		if !ok {
+			r.chains[c.Name] = &chain{Name: c.Name, Slug: c.Slug, Tokens: c.Tokens, Pairs: c.Pairs}
+			continue
+		}
+		if c.Slug != "" {
+			existing.Slug = c.Slug
+		}
+		existing.Tokens = append(existing.Tokens, c.Tokens...)
+		existing.Pairs = append(existing.Pairs, c.Pairs...)
+	}
+
+	return nil
+}
+
+// Pair returns the pair with the given name, such as "USDC/WETH", on the
+// given chain.
+func (r *Registry) Pair(chainName string, pairName string) (Pair, error) {
+
+	c, ok := r.chains[chainName]
+	if !ok {
+		return Pair{}, fmt.Errorf("unknown chain (chain: %s)", chainName)
+	}
+	if c.Slug == "" {
+		return Pair{}, fmt.Errorf("chain has no slug (chain: %s)", chainName)
+	}
+
+	tokens := make(map[string]Token, len(c.Tokens))
+	for _, token := range c.Tokens {
+		tokens[token.Symbol] = token
+	}
+
+	// Later entries override earlier ones, so we search from the back.
+	for i := len(c.Pairs) - 1; i >= 0; i-- {
+
+		entry := c.Pairs[i]
+		if entry.Token0+"/"+entry.Token1 != pairName {
+			continue
+		}
+
+		token0, ok := tokens[entry.Token0]
+		if !ok {
+			return Pair{}, fmt.Errorf("unknown token0 for pair (pair: %s, token: %s)", pairName, entry.Token0)
+		}
+		token1, ok := tokens[entry.Token1]
+		if !ok {
+			return Pair{}, fmt.Errorf("unknown token1 for pair (pair: %s, token: %s)", pairName, entry.Token1)
+		}
+		if token0.Stable == token1.Stable {
+			return Pair{}, fmt.Errorf("pair needs exactly one stable token (pair: %s)", pairName)
+		}
+
+		pair := Pair{
+			Chain:     chainName,
+			ChainSlug: c.Slug,
+			Name:      pairName,
+			Address:   entry.Address,
+			Token0:    token0,
+			Token1:    token1,
+		}
+
+		return pair, nil
+	}
+
+	return Pair{}, fmt.Errorf("unknown pair (chain: %s, pair: %s)", chainName, pairName)
+}
+
+// Native returns the wrapped native token of the given chain, which gas is
+// paid in.
+func (r *Registry) Native(chainName string) (Token, error) {
+
+	c, ok := r.chains[chainName]
+	if !ok {
+		return Token{}, fmt.Errorf("unknown chain (chain: %s)", chainName)
+	}
+
+	// Later entries override earlier ones, so we search from the back.
+	for i := len(c.Tokens) - 1; i >= 0; i-- {
+		if c.Tokens[i].Native {
+			return c.Tokens[i], nil
+		}
+	}
+
+	return Token{}, fmt.Errorf("chain has no native token (chain: %s)", chainName)
+}
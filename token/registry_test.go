@@ -0,0 +1,96 @@
+package token
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegistryChain(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		data    string
+		chain   string
+		pair    string
+		slug    string
+		native  string
+		invalid bool
+	}{
+		{
+			name:   "built-in chain",
+			chain:  "Ethereum Mainnet",
+			pair:   "WBTC/USDC",
+			slug:   "ethereum",
+			native: "WETH",
+		},
+		{
+			name:   "new chain",
+			data:   `[{"chain": "Polygon", "slug": "polygon", "tokens": [{"symbol": "USDC", "address": "0x1", "decimals": 6, "stable": true}, {"symbol": "WMATIC", "address": "0x2", "decimals": 18, "native": true}], "pairs": [{"address": "0x3", "token0": "WMATIC", "token1": "USDC"}]}]`,
+			chain:  "Polygon",
+			pair:   "WMATIC/USDC",
+			slug:   "polygon",
+			native: "WMATIC",
+		},
+		{
+			name:    "new chain without slug",
+			data:    `[{"chain": "Polygon", "tokens": [{"symbol": "USDC", "address": "0x1", "decimals": 6, "stable": true}, {"symbol": "WMATIC", "address": "0x2", "decimals": 18, "native": true}], "pairs": [{"address": "0x3", "token0": "WMATIC", "token1": "USDC"}]}]`,
+			chain:   "Polygon",
+			pair:    "WMATIC/USDC",
+			invalid: true,
+		},
+		{
+			name:   "known chain without slug",
+			data:   `[{"chain": "Ethereum Mainnet", "tokens": [{"symbol": "FRAX", "address": "0x4", "decimals": 18, "stable": true}], "pairs": [{"address": "0x5", "token0": "FRAX", "token1": "WETH"}]}]`,
+			chain:  "Ethereum Mainnet",
+			pair:   "FRAX/WETH",
+			slug:   "ethereum",
+			native: "WETH",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			var files []string
+			if test.data != "" {
+				file := filepath.Join(t.TempDir(), "tokens.json")
+				err := os.WriteFile(file, []byte(test.data), 0o600)
+				if err != nil {
+					t.Fatalf("could not write token file: %s", err)
+				}
+				files = append(files, file)
+			}
+
+			registry, err := NewRegistry(files...)
+			if err != nil {
+				t.Fatalf("could not create registry: %s", err)
+			}
+
+			pair, err := registry.Pair(test.chain, test.pair)
+			if test.invalid {
+				if err == nil {
+					t.Errorf("no error for chain without slug (chain: %s)", test.chain)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("could not find pair: %s", err)
+			}
+			if pair.Chain != test.chain {
+				t.Errorf("wrong chain (have: %s, want: %s)", pair.Chain, test.chain)
+			}
+			if pair.ChainSlug != test.slug {
+				t.Errorf("wrong slug (have: %s, want: %s)", pair.ChainSlug, test.slug)
+			}
+
+			native, err := registry.Native(test.chain)
+			if err != nil {
+				t.Fatalf("could not find native token: %s", err)
+			}
+			if native.Symbol != test.native {
+				t.Errorf("wrong native token (have: %s, want: %s)", native.Symbol, test.native)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package token
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/optakt/wilhelmus/b"
+)
+
+// Token holds the metadata of an ERC20 token on a given chain.
+type Token struct {
+	Symbol   string `json:"symbol"`
+	Address  string `json:"address"`
+	Decimals uint   `json:"decimals"`
+	Stable   bool   `json:"stable"`
+	Native   bool   `json:"native"` // wrapped native token of the chain, which gas is paid in
+}
+
+// Unit returns one whole token in its smallest unit.
+func (t Token) Unit() *big.Int {
+	return big.NewInt(0).Exp(b.D10, big.NewInt(int64(t.Decimals)), nil)
+}
+
+// Float converts the given amount in the smallest unit of the token to a
+// float of whole tokens.
+func (t Token) Float(amount *big.Int) float64 {
+	return b.ToFloat(amount, t.Decimals)
+}
+
+// Int converts the given float of whole tokens to an amount in the smallest
+// unit of the token.
+func (t Token) Int(f float64) *big.Int {
+	return b.FromFloat(f, t.Decimals)
+}
+
+// Amount returns the given amount in the smallest unit of the token as a typed
+// amount with the decimals of the token.
+func (t Token) Amount(amount *big.Int) b.Amount {
+	return b.NewAmount(amount, t.Decimals)
+}
+
+// liquidity converts the given liquidity of a pair between both tokens to a
+// float, using the geometric mean of the units of both tokens.
+func liquidity(amount *big.Int, token0 Token, token1 Token) float64 {
+	n, _ := big.NewFloat(0).SetInt(amount).Float64()
+	d := math.Pow(10, float64(token0.Decimals+token1.Decimals)/2)
+	return n / d
+}
@@ -0,0 +1,44 @@
+package util
+
+import (
+	"github.com/optakt/wilhelmus/b"
+)
+
+// CalculateAmountIn generalizes `GetAmountIn` to an arbitrary swap rate,
+// given in basis points, so that it can be used for pairs and forks that do
+// not charge the default Uniswap v2 fee of 0.3%.
+func CalculateAmountIn(amountOut b.Amount, reserveIn b.Amount, reserveOut b.Amount, swapRate b.Bps) (b.Amount, error) {
+
+	err := checkToken(amountOut, reserveOut)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	err = checkLiquidity(reserveIn, reserveOut)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	keep := b.NewAmount(swapRate.Complement().Int(), 0)
+	numerator, err := reserveIn.Mul(amountOut)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	numerator, err = numerator.Mul(b.NewAmount(b.E4, 0))
+	if err != nil {
+		return b.Amount{}, err
+	}
+	denominator, err := reserveOut.Sub(amountOut)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	denominator, err = denominator.Mul(keep)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	amountIn, err := numerator.Div(denominator, b.RoundDown)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	return amountIn.Add(b.NewAmount(b.D1, amountIn.Decimals()))
+}
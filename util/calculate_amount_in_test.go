@@ -0,0 +1,61 @@
+package util
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/optakt/wilhelmus/b"
+)
+
+// The expected amounts at 30 basis points are the `getAmountIn` and
+// `swapTokensForExactTokens` cases of the Uniswap v2 router tests
+// (Uniswap/v2-periphery, test/UniswapV2Router02.spec.ts).
+func TestCalculateAmountIn(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		amountOut  b.Amount
+		reserveIn  b.Amount
+		reserveOut b.Amount
+		swapRate   b.Bps
+		want       b.Amount
+	}{
+		{name: "router 1 out of 100 to 100", amountOut: wei("1"), reserveIn: wei("100"), reserveOut: wei("100"), swapRate: rate30, want: wei("2")},
+		{name: "router 1 out of 5 to 10", amountOut: ether(1), reserveIn: ether(5), reserveOut: ether(10), swapRate: rate30, want: wei("557227237267357629")},
+
+		// Without a fee, the input keeps the product of the reserves, and is
+		// rounded up by one unit like the library does.
+		{name: "no fee keeps product", amountOut: wei("500000000000000000"), reserveIn: ether(1), reserveOut: ether(1), swapRate: b.NewBps(big.NewInt(0)), want: wei("1000000000000000001")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := CalculateAmountIn(test.amountOut, test.reserveIn, test.reserveOut, test.swapRate)
+			assertAmount(t, "amount in", test.want, got, err)
+
+			// Swapping the returned input has to yield at least the output.
+			out, err := CalculateAmountOut(got, test.reserveIn, test.reserveOut, test.swapRate)
+			if err != nil {
+				t.Fatalf("could not calculate amount out: %s", err)
+			}
+			if out.Int().Cmp(test.amountOut.Int()) < 0 {
+				t.Errorf("amount out: want at least %s, got %s", test.amountOut, out)
+			}
+		})
+	}
+}
+
+// At 30 basis points, the generalized function has to match the Uniswap v2
+// library function exactly; note that the library takes the output reserve
+// first.
+func TestCalculateAmountInDefaultRate(t *testing.T) {
+
+	for _, amountOut := range []b.Amount{wei("1"), wei("123456789"), wei("500000000000000000000")} {
+		want, err := GetAmountIn(amountOut, reserveWETH, reserveUSDC)
+		if err != nil {
+			t.Fatalf("could not get amount in: %s", err)
+		}
+		got, err := CalculateAmountIn(amountOut, reserveUSDC, reserveWETH, rate30)
+		assertAmount(t, "amount in for "+amountOut.String(), want, got, err)
+	}
+}
@@ -0,0 +1,40 @@
+package util
+
+import (
+	"github.com/optakt/wilhelmus/b"
+)
+
+// CalculateAmountOut generalizes `GetAmountOut` to an arbitrary swap rate,
+// given in basis points, so that it can be used for pairs and forks that do
+// not charge the default Uniswap v2 fee of 0.3%.
+func CalculateAmountOut(amountIn b.Amount, reserveIn b.Amount, reserveOut b.Amount, swapRate b.Bps) (b.Amount, error) {
+
+	err := checkToken(amountIn, reserveIn)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	err = checkLiquidity(reserveIn, reserveOut)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	keep := b.NewAmount(swapRate.Complement().Int(), 0)
+	amountInWithFee, err := amountIn.Mul(keep)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	numerator, err := amountInWithFee.Mul(reserveOut)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	denominator, err := reserveIn.Mul(b.NewAmount(b.E4, 0))
+	if err != nil {
+		return b.Amount{}, err
+	}
+	denominator, err = denominator.Add(amountInWithFee)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	return numerator.Div(denominator, b.RoundDown)
+}
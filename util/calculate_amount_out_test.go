@@ -0,0 +1,86 @@
+package util
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/optakt/wilhelmus/b"
+)
+
+// The expected amounts at 30 basis points are the swap test cases of the
+// Uniswap v2 pair contract (Uniswap/v2-core, test/UniswapV2Pair.spec.ts,
+// `swapTestCases`), where swapping one unit more than the expected output
+// fails the constant product check of the contract, and the `getAmountOut`
+// case of the router tests (Uniswap/v2-periphery,
+// test/UniswapV2Router02.spec.ts).
+func TestCalculateAmountOut(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		amountIn   b.Amount
+		reserveIn  b.Amount
+		reserveOut b.Amount
+		swapRate   b.Bps
+		want       b.Amount
+	}{
+		{name: "pair 1 in 5 to 10", amountIn: ether(1), reserveIn: ether(5), reserveOut: ether(10), swapRate: rate30, want: wei("1662497915624478906")},
+		{name: "pair 1 in 10 to 5", amountIn: ether(1), reserveIn: ether(10), reserveOut: ether(5), swapRate: rate30, want: wei("453305446940074565")},
+		{name: "pair 2 in 5 to 10", amountIn: ether(2), reserveIn: ether(5), reserveOut: ether(10), swapRate: rate30, want: wei("2851015155847869602")},
+		{name: "pair 2 in 10 to 5", amountIn: ether(2), reserveIn: ether(10), reserveOut: ether(5), swapRate: rate30, want: wei("831248957812239453")},
+		{name: "pair 1 in 10 to 10", amountIn: ether(1), reserveIn: ether(10), reserveOut: ether(10), swapRate: rate30, want: wei("906610893880149131")},
+		{name: "pair 1 in 100 to 100", amountIn: ether(1), reserveIn: ether(100), reserveOut: ether(100), swapRate: rate30, want: wei("987158034397061298")},
+		{name: "pair 1 in 1000 to 1000", amountIn: ether(1), reserveIn: ether(1000), reserveOut: ether(1000), swapRate: rate30, want: wei("996006981039903216")},
+		{name: "router 2 in 100 to 100", amountIn: wei("2"), reserveIn: wei("100"), reserveOut: wei("100"), swapRate: rate30, want: wei("1")},
+
+		// Without a fee, the output keeps the product of the reserves, so
+		// swapping as much as the input reserve takes half of the output.
+		{name: "no fee keeps product", amountIn: ether(1), reserveIn: ether(1), reserveOut: ether(1), swapRate: b.NewBps(big.NewInt(0)), want: wei("500000000000000000")},
+		{name: "full fee gives nothing", amountIn: ether(1), reserveIn: ether(1), reserveOut: ether(1), swapRate: b.NewBps(big.NewInt(10_000)), want: wei("0")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := CalculateAmountOut(test.amountIn, test.reserveIn, test.reserveOut, test.swapRate)
+			assertAmount(t, "amount out", test.want, got, err)
+		})
+	}
+}
+
+// At 30 basis points, the generalized function has to match the Uniswap v2
+// library function exactly.
+func TestCalculateAmountOutDefaultRate(t *testing.T) {
+
+	for _, amountIn := range []b.Amount{usdc("1"), usdc("123456789"), usdc("5000000000000")} {
+		want, err := GetAmountOut(amountIn, reserveUSDC, reserveWETH)
+		if err != nil {
+			t.Fatalf("could not get amount out: %s", err)
+		}
+		got, err := CalculateAmountOut(amountIn, reserveUSDC, reserveWETH, rate30)
+		assertAmount(t, "amount out for "+amountIn.String(), want, got, err)
+	}
+}
+
+// Like the Uniswap v2 library, swaps fail without liquidity, and amounts have
+// to be of the token of the input reserve.
+func TestCalculateAmountOutInvalid(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		amountIn   b.Amount
+		reserveIn  b.Amount
+		reserveOut b.Amount
+	}{
+		{name: "mismatched decimals", amountIn: usdc("1000000"), reserveIn: reserveWETH, reserveOut: reserveUSDC},
+		{name: "empty input reserve", amountIn: ether(1), reserveIn: wei("0"), reserveOut: ether(10)},
+		{name: "empty output reserve", amountIn: ether(1), reserveIn: ether(5), reserveOut: wei("0")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := CalculateAmountOut(test.amountIn, test.reserveIn, test.reserveOut, rate30)
+			if err == nil {
+				t.Errorf("no error for invalid swap (amount out: %s)", got)
+			}
+		})
+	}
+}
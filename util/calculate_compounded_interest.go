@@ -8,10 +8,13 @@ import (
 
 // CalculateCompoundedInterest adopted from AAVE v2:
 // => https://github.com/aave/protocol-v2/blob/master/contracts/protocol/libraries/math/MathUtils.sol#L32-L70
-func CalculateCompoundedInterest(rate *big.Int, exp *big.Int) *big.Int {
+//
+// It returns the interest accrued per unit over the given seconds, without the
+// unit itself, unlike the original.
+func CalculateCompoundedInterest(rate b.Ray, exp *big.Int) b.Ray {
 
 	if exp.Cmp(b.D0) == 0 {
-		return big.NewInt(0)
+		return b.NewRay(b.D0)
 	}
 
 	em1 := big.NewInt(0).Sub(exp, b.D1)
@@ -20,29 +23,24 @@ func CalculateCompoundedInterest(rate *big.Int, exp *big.Int) *big.Int {
 		em2 = big.NewInt(0)
 	}
 
-	rps := big.NewInt(0).Div(rate, b.SPY)
+	rps := b.NewRay(big.NewInt(0).Div(rate.Int(), b.SPY))
 
-	bp2 := big.NewInt(0).Mul(rps, rps)
-	bp2.Add(bp2, b.HALF)
-	bp2.Div(bp2, b.E27)
+	bp2 := rps.Mul(rps)
+	bp3 := bp2.Mul(rps)
 
-	bp3 := big.NewInt(0).Mul(bp2, rps)
-	bp3.Add(bp3, b.HALF)
-	bp3.Div(bp3, b.E27)
-
-	t1 := big.NewInt(0).Mul(exp, rps)
+	t1 := big.NewInt(0).Mul(exp, rps.Int())
 
 	t2 := big.NewInt(0).Mul(exp, em1)
-	t2.Mul(t2, bp2)
+	t2.Mul(t2, bp2.Int())
 	t2.Div(t2, b.D2)
 
 	t3 := big.NewInt(0).Mul(exp, em1)
 	t3.Mul(t3, em2)
-	t3.Mul(t3, bp3)
+	t3.Mul(t3, bp3.Int())
 	t3.Div(t3, b.D6)
 
 	out := big.NewInt(0).Add(t1, t2)
 	out.Add(out, t3)
 
-	return out
+	return b.NewRay(out)
 }
@@ -0,0 +1,83 @@
+package util
+
+import (
+	"github.com/optakt/wilhelmus/b"
+)
+
+// CalculateOptimalSwap adopted from the Alpha Homora / Zapper single-sided entry:
+// => https://blog.alphaventuredao.io/onesideduniswap/
+//
+// It returns the amount of the input asset that should be swapped on the pair
+// so that the remainder and the swap output can be added as liquidity in the
+// exact ratio of the post-swap reserves. For the Uniswap v2 default rate of 30
+// basis points, this is the well-known formula:
+//
+//	swapAmount = (sqrt(reserveIn * (amountIn * 3988000 + reserveIn * 3988009)) - reserveIn * 1997) / 1994
+func CalculateOptimalSwap(amountIn b.Amount, reserveIn b.Amount, swapRate b.Bps) (b.Amount, error) {
+
+	err := checkToken(amountIn, reserveIn)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	err = checkLiquidity(reserveIn)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	keep := b.NewAmount(swapRate.Complement().Int(), 0) // 9970
+	both, err := b.NewAmount(b.E4, 0).Add(keep)         // 19970
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	square, err := both.Mul(both) // 398800900
+	if err != nil {
+		return b.Amount{}, err
+	}
+	square, err = square.Mul(reserveIn)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	linear, err := keep.Mul(b.NewAmount(b.E4, 0)) // 99700000
+	if err != nil {
+		return b.Amount{}, err
+	}
+	linear, err = linear.Mul(b.NewAmount(b.D4, 0)) // 398800000
+	if err != nil {
+		return b.Amount{}, err
+	}
+	linear, err = linear.Mul(amountIn)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	root, err := square.Add(linear)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	root, err = root.Mul(reserveIn)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	root, err = root.Sqrt()
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	offset, err := reserveIn.Mul(both)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	swapAmount, err := root.Sub(offset)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	divisor, err := keep.Mul(b.NewAmount(b.D2, 0))
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	return swapAmount.Div(divisor, b.RoundDown)
+}
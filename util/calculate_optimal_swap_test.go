@@ -0,0 +1,90 @@
+package util
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/optakt/wilhelmus/b"
+)
+
+// There is no on-chain reference for the single-sided entry, so the swap
+// amount is checked against a search for the largest swap whose output can
+// still be matched by the remainder at the reserves after the swap, using
+// the swap output that is checked against the Uniswap v2 test cases.
+func TestCalculateOptimalSwap(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		amountIn   b.Amount
+		reserveIn  b.Amount
+		reserveOut b.Amount
+		swapRate   b.Bps
+	}{
+		{name: "1 into 5 to 10 at 30 bps", amountIn: ether(1), reserveIn: ether(5), reserveOut: ether(10), swapRate: rate30},
+		{name: "2 into 10 to 5 at 30 bps", amountIn: ether(2), reserveIn: ether(10), reserveOut: ether(5), swapRate: rate30},
+		{name: "1 into 5 to 10 at 5 bps", amountIn: ether(1), reserveIn: ether(5), reserveOut: ether(10), swapRate: rate5},
+		{name: "2 into 10 to 5 at 100 bps", amountIn: ether(2), reserveIn: ether(10), reserveOut: ether(5), swapRate: b.NewBps(big.NewInt(100))},
+		{name: "1M USDC at 30 bps", amountIn: usdc("1000000000000"), reserveIn: reserveUSDC, reserveOut: reserveWETH, swapRate: rate30},
+		{name: "10M USDC at 30 bps", amountIn: usdc("10000000000000"), reserveIn: reserveUSDC, reserveOut: reserveWETH, swapRate: rate30},
+		{name: "1M USDC at 5 bps", amountIn: usdc("1000000000000"), reserveIn: reserveUSDC, reserveOut: reserveWETH, swapRate: rate5},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			// The remainder is short of matching the output by a number that
+			// shrinks as the swap grows, so the largest swap that leaves no
+			// shortfall is found by bisection.
+			short := func(swap *big.Int) bool {
+				out, err := CalculateAmountOut(b.NewAmount(swap, test.amountIn.Decimals()), test.reserveIn, test.reserveOut, test.swapRate)
+				if err != nil {
+					t.Fatalf("could not calculate amount out: %s", err)
+				}
+				remainder := big.NewInt(0).Sub(test.amountIn.Int(), swap)
+				left := big.NewInt(0).Mul(remainder, big.NewInt(0).Sub(test.reserveOut.Int(), out.Int()))
+				right := big.NewInt(0).Mul(out.Int(), big.NewInt(0).Add(test.reserveIn.Int(), swap))
+				return left.Cmp(right) < 0
+			}
+			low := big.NewInt(0)
+			high := test.amountIn.Int()
+			for big.NewInt(0).Sub(high, low).Cmp(b.D1) > 0 {
+				middle := big.NewInt(0).Add(low, high)
+				middle.Rsh(middle, 1)
+				if short(middle) {
+					high = middle
+				} else {
+					low = middle
+				}
+			}
+
+			// The closed form rounds its square root down, so it can end up
+			// a couple of units below the search.
+			got, err := CalculateOptimalSwap(test.amountIn, test.reserveIn, test.swapRate)
+			if err != nil {
+				t.Fatalf("could not calculate swap amount: %s", err)
+			}
+			diff := big.NewInt(0).Sub(low, got.Int())
+			if diff.Sign() < 0 || diff.Cmp(b.D2) > 0 {
+				t.Errorf("wrong swap amount (have: %s, want: %s)", got, low)
+			}
+		})
+	}
+}
+
+// At 30 basis points, the generalized function has to match the well-known
+// closed form used by Zapper and Alpha Homora.
+func TestCalculateOptimalSwapDefaultRate(t *testing.T) {
+
+	for _, amountIn := range []b.Amount{usdc("1000000"), usdc("250000000000"), usdc("50000000000000")} {
+
+		root := big.NewInt(0).Mul(amountIn.Int(), big.NewInt(3988000))
+		root.Add(root, big.NewInt(0).Mul(reserveUSDC.Int(), big.NewInt(3988009)))
+		root.Mul(root, reserveUSDC.Int())
+		root.Sqrt(root)
+		want := big.NewInt(0).Sub(root, big.NewInt(0).Mul(reserveUSDC.Int(), b.D1997))
+		want.Div(want, big.NewInt(1994))
+
+		got, err := CalculateOptimalSwap(amountIn, reserveUSDC, rate30)
+		assertAmount(t, "swap amount for "+amountIn.String(), b.NewAmount(want, 6), got, err)
+	}
+}
@@ -0,0 +1,27 @@
+package util
+
+import (
+	"fmt"
+
+	"github.com/optakt/wilhelmus/b"
+)
+
+// checkToken returns an error if the given amount does not have the decimals
+// of the token of the given reserve.
+func checkToken(amount b.Amount, reserve b.Amount) error {
+	if amount.Decimals() != reserve.Decimals() {
+		return fmt.Errorf("amount does not match reserve (amount: %d decimals, reserve: %d decimals)", amount.Decimals(), reserve.Decimals())
+	}
+	return nil
+}
+
+// checkLiquidity returns an error if one of the given reserves is empty, like
+// the `INSUFFICIENT_LIQUIDITY` requirement of the Uniswap v2 library.
+func checkLiquidity(reserves ...b.Amount) error {
+	for _, reserve := range reserves {
+		if reserve.Sign() <= 0 {
+			return fmt.Errorf("insufficient liquidity (reserve: %s)", reserve)
+		}
+	}
+	return nil
+}
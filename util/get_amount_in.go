@@ -1,8 +1,6 @@
 package util
 
 import (
-	"math/big"
-
 	"github.com/optakt/wilhelmus/b"
 )
 
@@ -18,12 +16,37 @@ import (
 //		uint denominator = reserveOut.sub(amountOut).mul(997);
 //		amountIn = (numerator / denominator).add(1);
 //	}
-func GetAmountIn(amountOut *big.Int, reserveOut *big.Int, reserveIn *big.Int) *big.Int {
-	numerator := big.NewInt(0).Mul(reserveIn, amountOut)
-	numerator.Mul(numerator, b.D1000)
-	denominator := big.NewInt(0).Sub(reserveOut, amountOut)
-	denominator.Mul(denominator, b.D997)
-	amountIn := big.NewInt(0).Div(numerator, denominator)
-	amountIn.Add(amountIn, b.D1)
-	return amountIn
+func GetAmountIn(amountOut b.Amount, reserveOut b.Amount, reserveIn b.Amount) (b.Amount, error) {
+
+	err := checkToken(amountOut, reserveOut)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	err = checkLiquidity(reserveIn, reserveOut)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	numerator, err := reserveIn.Mul(amountOut)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	numerator, err = numerator.Mul(b.NewAmount(b.D1000, 0))
+	if err != nil {
+		return b.Amount{}, err
+	}
+	denominator, err := reserveOut.Sub(amountOut)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	denominator, err = denominator.Mul(b.NewAmount(b.D997, 0))
+	if err != nil {
+		return b.Amount{}, err
+	}
+	amountIn, err := numerator.Div(denominator, b.RoundDown)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	return amountIn.Add(b.NewAmount(b.D1, amountIn.Decimals()))
 }
@@ -1,8 +1,6 @@
 package util
 
 import (
-	"math/big"
-
 	"github.com/optakt/wilhelmus/b"
 )
 
@@ -19,11 +17,33 @@ import (
 //	    uint denominator = reserveIn.mul(1000).add(amountInWithFee);
 //	    amountOut = numerator / denominator;
 //	}
-func GetAmountOut(amountIn *big.Int, reserveIn *big.Int, reserveOut *big.Int) *big.Int {
-	amountInWithFee := big.NewInt(0).Mul(amountIn, b.D997)
-	numerator := big.NewInt(0).Mul(amountInWithFee, reserveOut)
-	denominator := big.NewInt(0).Mul(reserveIn, b.D1000)
-	denominator.Add(denominator, amountInWithFee)
-	amountOut := big.NewInt(0).Div(numerator, denominator)
-	return amountOut
+func GetAmountOut(amountIn b.Amount, reserveIn b.Amount, reserveOut b.Amount) (b.Amount, error) {
+
+	err := checkToken(amountIn, reserveIn)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	err = checkLiquidity(reserveIn, reserveOut)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	amountInWithFee, err := amountIn.Mul(b.NewAmount(b.D997, 0))
+	if err != nil {
+		return b.Amount{}, err
+	}
+	numerator, err := amountInWithFee.Mul(reserveOut)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	denominator, err := reserveIn.Mul(b.NewAmount(b.D1000, 0))
+	if err != nil {
+		return b.Amount{}, err
+	}
+	denominator, err = denominator.Add(amountInWithFee)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	return numerator.Div(denominator, b.RoundDown)
 }
@@ -1,7 +1,7 @@
 package util
 
 import (
-	"math/big"
+	"github.com/optakt/wilhelmus/b"
 )
 
 // Quote adopted from Uniswap v2:
@@ -14,8 +14,23 @@ import (
 //	    require(reserveA > 0 && reserveB > 0, 'UniswapV2Library: INSUFFICIENT_LIQUIDITY');
 //	    amountB = amountA.mul(reserveB) / reserveA;
 //	}
-func Quote(amountA *big.Int, reserveA *big.Int, reserveB *big.Int) *big.Int {
-	amountB := big.NewInt(0).Mul(amountA, reserveB)
-	amountB.Div(amountB, reserveA)
-	return amountB
+//
+// The returned amount has the decimals of the token of the second reserve.
+func Quote(amountA b.Amount, reserveA b.Amount, reserveB b.Amount) (b.Amount, error) {
+
+	err := checkToken(amountA, reserveA)
+	if err != nil {
+		return b.Amount{}, err
+	}
+	err = checkLiquidity(reserveA, reserveB)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	amountB, err := amountA.Mul(reserveB)
+	if err != nil {
+		return b.Amount{}, err
+	}
+
+	return amountB.Div(reserveA, b.RoundDown)
 }
@@ -0,0 +1,54 @@
+package util
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/optakt/wilhelmus/b"
+)
+
+// Reserves of the mainnet USDC/WETH pair, with 6 and 18 decimals, in the
+// range they held in 2022, to check the generalized functions against the
+// Uniswap v2 library functions at realistic magnitudes.
+var (
+	reserveUSDC = usdc("54963236190374")
+	reserveWETH = wei("30437815372104693858232")
+
+	rate30 = b.NewBps(big.NewInt(30))
+	rate5  = b.NewBps(big.NewInt(5))
+)
+
+func mustInt(s string) *big.Int {
+	value, ok := big.NewInt(0).SetString(s, 10)
+	if !ok {
+		panic("invalid integer: " + s)
+	}
+	return value
+}
+
+// wei returns the given amount of a token with 18 decimals.
+func wei(s string) b.Amount {
+	return b.NewAmount(mustInt(s), 18)
+}
+
+// usdc returns the given amount of a token with 6 decimals.
+func usdc(s string) b.Amount {
+	return b.NewAmount(mustInt(s), 6)
+}
+
+// ether returns the given number of whole tokens with 18 decimals, like the
+// `expandTo18Decimals` helper of the Uniswap v2 tests.
+func ether(n int64) b.Amount {
+	return b.NewAmount(big.NewInt(0).Mul(big.NewInt(n), b.E18), 18)
+}
+
+func assertAmount(t *testing.T, name string, want b.Amount, got b.Amount, err error) {
+	t.Helper()
+	if err != nil {
+		t.Errorf("%s: could not compute amount: %s", name, err)
+		return
+	}
+	if want.Int().Cmp(got.Int()) != 0 || want.Decimals() != got.Decimals() {
+		t.Errorf("%s: want %s, got %s", name, want, got)
+	}
+}
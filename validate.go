@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"time"
+
+	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+	"github.com/rs/zerolog"
+	"github.com/spf13/pflag"
+
+	"github.com/optakt/wilhelmus/b"
+	"github.com/optakt/wilhelmus/check"
+	"github.com/optakt/wilhelmus/source"
+	"github.com/optakt/wilhelmus/token"
+)
+
+// validate scans the records of the configured pair for gaps and implausible
+// values, and outputs the issues it found as JSON.
+func validate(args []string) {
+
+	var (
+		logLevel string
+		output   string
+
+		chainName  string
+		pairName   string
+		tokenFiles []string
+		startTime  string
+		endTime    string
+
+		maxGap         time.Duration
+		flagMaxJump    string
+		flagMinReserve float64
+
+		influxAPI           string
+		influxToken         string
+		influxOrg           string
+		influxBucketMetrics string
+	)
+
+	now := time.Now().UTC()
+	oya := now.AddDate(-1, 0, 0)
+
+	flags := pflag.NewFlagSet("validate", pflag.ExitOnError)
+
+	flags.StringVarP(&logLevel, "log-level", "l", "info", "Zerolog logger logging message severity")
+	flags.StringVar(&output, "output", "", "JSON file for the validation report, instead of standard output")
+
+	flags.StringVarP(&chainName, "chain-name", "c", "Ethereum Mainnet", "chain name to filter metrics")
+	flags.StringVarP(&pairName, "pair-name", "p", "USDC/WETH", "asset pair to filter metrics")
+	flags.StringSliceVar(&tokenFiles, "token-files", nil, "JSON files with tokens and pairs per chain, in addition to the built-in ones")
+	flags.StringVarP(&startTime, "start-time", "s", oya.Format(time.RFC3339), "start timestamp for the validation")
+	flags.StringVarP(&endTime, "end-time", "e", now.Format(time.RFC3339), "end timestamp for the validation")
+
+	flags.DurationVar(&maxGap, "max-gap", time.Hour, "longest expected interval between records")
+	flags.StringVar(&flagMaxJump, "max-jump", "0.2", "largest expected change of a reserve between records")
+	flags.Float64Var(&flagMinReserve, "min-reserve", 1000, "smallest plausible reserve of the stable token")
+
+	flags.StringVarP(&influxAPI, "influx-api", "i", "https://eu-central-1-1.aws.cloud2.influxdata.com", "InfluxDB API URL")
+	flags.StringVarP(&influxToken, "influx-token", "t", "", "InfluxDB authentication token")
+	flags.StringVarP(&influxOrg, "influx-org", "o", "optakt", "InfluxDB organization name")
+	flags.StringVar(&influxBucketMetrics, "influx-bucket-metrics", "metrics", "InfluxDB bucket name for Uniswap metrics")
+
+	_ = flags.Parse(args)
+
+	// The report goes to standard output, so logs go to standard error.
+	log := zerolog.New(os.Stderr)
+	level, err := zerolog.ParseLevel(logLevel)
+	if err != nil {
+		log.Fatal().Err(err).Str("log_level", logLevel).Msg("invalid log level")
+	}
+	log = log.Level(level)
+
+	registry, err := token.NewRegistry(tokenFiles...)
+	if err != nil {
+		log.Fatal().Err(err).Strs("token_files", tokenFiles).Msg("could not create token registry")
+	}
+	pair, err := registry.Pair(chainName, pairName)
+	if err != nil {
+		log.Fatal().Err(err).Str("chain_name", chainName).Str("pair_name", pairName).Msg("could not find pair")
+	}
+
+	maxJump, err := b.ParseBps(flagMaxJump)
+	if err != nil {
+		log.Fatal().Err(err).Str("max_jump", flagMaxJump).Msg("invalid maximum jump")
+	}
+
+	// The source yields values in the token order of the pair contract, so
+	// the minimum applies to whichever side holds the stable token.
+	minimum0, minimum1 := pair.Orient(pair.Stable().Int(flagMinReserve), big.NewInt(0))
+
+	start, err := time.Parse(time.RFC3339, startTime)
+	if err != nil {
+		log.Fatal().Err(err).Str("start_time", startTime).Msg("invalid start time")
+	}
+	end, err := time.Parse(time.RFC3339, endTime)
+	if err != nil {
+		log.Fatal().Err(err).Str("end_time", endTime).Msg("invalid end time")
+	}
+
+	validator, err := check.New(maxGap, maxJump, minimum0, minimum1)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not create validator")
+	}
+
+	client := influxdb2.NewClientWithOptions(influxAPI, influxToken,
+		influxdb2.DefaultOptions().SetHTTPRequestTimeout(uint(15*time.Minute)),
+	)
+	defer client.Close()
+
+	metrics, err := source.NewInflux(client.QueryAPI(influxOrg), influxBucketMetrics, startTime, endTime, chainName, pairName)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not initialize metrics source")
+	}
+
+	report, err := validator.Run(metrics, start, end)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not validate records")
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not encode validation report")
+	}
+	data = append(data, '\n')
+
+	if output == "" {
+		_, err = os.Stdout.Write(data)
+	} else {
+		err = os.WriteFile(output, data, 0644)
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not write validation report")
+	}
+
+	log.Info().
+		Uint("records", report.Records).
+		Int("issues", len(report.Issues)).
+		Msg("validation of records completed")
+
+	if len(report.Issues) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,54 @@
+package write
+
+import (
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/influxdata/influxdb-client-go/v2/api"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+
+	"github.com/optakt/wilhelmus/attribution"
+	"github.com/optakt/wilhelmus/token"
+)
+
+func AttributionPoint(timestamp time.Time, size uint64, result attribution.Attribution, pair token.Pair, outbound api.WriteAPI) {
+
+	token0 := pair.Stable()
+
+	number, suffix := humanize.ComputeSI(float64(size))
+	sizeLabel := humanize.Ftoa(number) + suffix
+
+	scopes := map[string]attribution.Breakdown{
+		"step":       result.Step,
+		"cumulative": result.Total,
+	}
+	for scope, breakdown := range scopes {
+
+		tags := map[string]string{
+			"strategy": result.Strategy,
+			"scope":    scope,
+			"chain":    pair.ChainSlug,
+			"pair":     pair.Name,
+			"size":     sizeLabel,
+		}
+		fields := map[string]interface{}{
+			"exposure":    token0.Float(breakdown.Exposure0),
+			"impermanent": token0.Float(breakdown.Impermanent0),
+			"income":      token0.Float(breakdown.Income0),
+			"rewards":     token0.Float(breakdown.Rewards0),
+			"yield":       token0.Float(breakdown.Yield0),
+			"interest":    token0.Float(breakdown.Interest0),
+			"funding":     token0.Float(breakdown.Funding0),
+			"hedge":       token0.Float(breakdown.Hedge0),
+			"fees":        token0.Float(breakdown.Fees0),
+			"slippage":    token0.Float(breakdown.Slippage0),
+			"gas":         token0.Float(breakdown.Gas0),
+			"residual":    token0.Float(breakdown.Residual0),
+			"total":       token0.Float(breakdown.Sum()),
+		}
+
+		point := write.NewPoint("attribution", tags, fields, timestamp)
+		outbound.WritePoint(point)
+	}
+}
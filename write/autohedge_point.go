@@ -1,7 +1,7 @@
 package write
 
 import (
-	"math/big"
+	"fmt"
 	"time"
 
 	"github.com/dustin/go-humanize"
@@ -11,47 +11,80 @@ import (
 
 	"github.com/optakt/wilhelmus/b"
 	"github.com/optakt/wilhelmus/position"
+	"github.com/optakt/wilhelmus/token"
 	"github.com/optakt/wilhelmus/util"
 )
 
-func AutohedgePoint(timestamp time.Time, reserve0 *big.Int, reserve1 *big.Int, autohedge position.Autohedge, outbound api.WriteAPI) {
+func AutohedgePoint(timestamp time.Time, reserve0 b.Amount, reserve1 b.Amount, autohedge position.Autohedge, pair token.Pair, outbound api.WriteAPI) error {
 
 	number, suffix := humanize.ComputeSI(float64(autohedge.Size))
 	size := humanize.Ftoa(number) + suffix
 
-	rehedgeFloat, _ := big.NewFloat(0).SetInt(autohedge.Rehedge).Float64()
-	rehedge := humanize.Ftoa(rehedgeFloat/10) + "%"
+	rehedge := humanize.Ftoa(autohedge.Rehedge.Float()*100) + "%"
 
-	interest0 := util.Quote(autohedge.Interest1, reserve1, reserve0)
-	debt0 := util.Quote(autohedge.Debt1, reserve1, reserve0)
-	debt0.Add(debt0, interest0)
-	debt0.Sub(debt0, autohedge.Yield0)
+	value0, err := autohedge.Value0(reserve0, reserve1)
+	if err != nil {
+		return fmt.Errorf("could not compute value: %w", err)
+	}
+
+	interest0, err := util.Quote(autohedge.Interest1, reserve1, reserve0)
+	if err != nil {
+		return fmt.Errorf("could not quote interest: %w", err)
+	}
+	debt0, err := util.Quote(autohedge.Debt1, reserve1, reserve0)
+	if err != nil {
+		return fmt.Errorf("could not quote debt: %w", err)
+	}
+	debt0, err = b.Sum(debt0, interest0, autohedge.Yield0.Neg())
+	if err != nil {
+		return fmt.Errorf("could not compute debt: %w", err)
+	}
 
-	loss0 := big.NewInt(0).Add(autohedge.Fees0, autohedge.Cost0)
-	loss0.Add(loss0, interest0)
+	loss0, err := b.Sum(autohedge.Fees0, autohedge.Cost0, interest0)
+	if err != nil {
+		return fmt.Errorf("could not compute loss: %w", err)
+	}
 
-	change0 := big.NewInt(0).Sub(autohedge.Profit0, loss0)
+	change0, err := autohedge.Profit0.Sub(loss0)
+	if err != nil {
+		return fmt.Errorf("could not compute change: %w", err)
+	}
+
+	pending0, err := autohedge.Unharvested0(reserve0, reserve1)
+	if err != nil {
+		return fmt.Errorf("could not compute pending fees: %w", err)
+	}
 
 	tags := map[string]string{
 		"strategy": "autohedge",
-		"chain":    "ethereum",
+		"chain":    pair.ChainSlug,
+		"pair":     pair.Name,
 		"size":     size,
 		"leverage": "2x",
 		"rehedge":  rehedge,
 	}
 	fields := map[string]interface{}{
-		"value":     b.ToFloat(autohedge.Value0(reserve0, reserve1), 6),
-		"principal": b.ToFloat(autohedge.Principal0, 6),
-		"yield":     b.ToFloat(autohedge.Yield0, 6),
-		"debt":      b.ToFloat(debt0, 6),
-		"interest":  b.ToFloat(interest0, 6),
-		"fees":      b.ToFloat(autohedge.Fees0, 6),
-		"cost":      b.ToFloat(autohedge.Cost0, 6),
-		"profit":    b.ToFloat(autohedge.Profit0, 6),
-		"loss":      b.ToFloat(loss0, 6),
-		"change":    b.ToFloat(change0, 6),
+		"value":         value0.Float(),
+		"principal":     autohedge.Principal0.Float(),
+		"yield":         autohedge.Yield0.Float(),
+		"debt":          debt0.Float(),
+		"interest":      interest0.Float(),
+		"fees":          autohedge.Fees0.Float(),
+		"cost":          autohedge.Cost0.Float(),
+		"profit":        autohedge.Profit0.Float(),
+		"loss":          loss0.Float(),
+		"change":        change0.Float(),
+		"pending":       pending0.Float(),
+		"harvests":      autohedge.Harvests,
+		"rewards":       autohedge.Rewards.Value0.Float(),
+		"rewards_sold":  autohedge.Rewards.Sold0.Float(),
+		"rewards_fees":  autohedge.Rewards.Fees0.Float(),
+		"rewards_cost":  autohedge.Rewards.Cost0.Float(),
+		"rewards_sales": autohedge.Rewards.Sales,
 	}
 
 	point := write.NewPoint("uniswapv2", tags, fields, timestamp)
 	outbound.WritePoint(point)
+
+	return nil
 }
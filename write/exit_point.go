@@ -0,0 +1,43 @@
+package write
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/influxdata/influxdb-client-go/v2/api"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+
+	"github.com/optakt/wilhelmus/position"
+	"github.com/optakt/wilhelmus/token"
+)
+
+func ExitPoint(timestamp time.Time, strategy string, size uint64, exit position.Exit, pair token.Pair, outbound api.WriteAPI) error {
+
+	number, suffix := humanize.ComputeSI(float64(size))
+	sizeLabel := humanize.Ftoa(number) + suffix
+
+	realizable0, err := exit.Realizable0()
+	if err != nil {
+		return fmt.Errorf("could not compute realizable value: %w", err)
+	}
+
+	tags := map[string]string{
+		"strategy": strategy,
+		"chain":    pair.ChainSlug,
+		"pair":     pair.Name,
+		"size":     sizeLabel,
+	}
+	fields := map[string]interface{}{
+		"value":      exit.Value0.Float(),
+		"fees":       exit.Fees0.Float(),
+		"cost":       exit.Cost0.Float(),
+		"realizable": realizable0.Float(),
+	}
+
+	point := write.NewPoint("exit", tags, fields, timestamp)
+	outbound.WritePoint(point)
+
+	return nil
+}
@@ -1,7 +1,7 @@
 package write
 
 import (
-	"math/big"
+	"fmt"
 	"time"
 
 	"github.com/dustin/go-humanize"
@@ -11,24 +11,33 @@ import (
 
 	"github.com/optakt/wilhelmus/b"
 	"github.com/optakt/wilhelmus/position"
+	"github.com/optakt/wilhelmus/token"
 )
 
-func HoldPoint(timestamp time.Time, reserve0 *big.Int, reserve1 *big.Int, hold position.Hold, outbound api.WriteAPI) {
+func HoldPoint(timestamp time.Time, reserve0 b.Amount, reserve1 b.Amount, hold position.Hold, pair token.Pair, outbound api.WriteAPI) error {
 
 	number, suffix := humanize.ComputeSI(float64(hold.Size))
 	size := humanize.Ftoa(number) + suffix
 
+	value0, err := hold.Value0(reserve0, reserve1)
+	if err != nil {
+		return fmt.Errorf("could not compute value: %w", err)
+	}
+
 	tags := map[string]string{
 		"strategy": "hold",
-		"chain":    "ethereum",
+		"chain":    pair.ChainSlug,
+		"pair":     pair.Name,
 		"size":     size,
 	}
 	fields := map[string]interface{}{
-		"value": b.ToFloat(hold.Value0(reserve0, reserve1), 6),
-		"fees":  b.ToFloat(hold.Fees0, 6),
-		"cost":  b.ToFloat(hold.Cost0, 6),
+		"value": value0.Float(),
+		"fees":  hold.Fees0.Float(),
+		"cost":  hold.Cost0.Float(),
 	}
 
 	point := write.NewPoint("uniswapv2", tags, fields, timestamp)
 	outbound.WritePoint(point)
+
+	return nil
 }
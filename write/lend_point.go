@@ -0,0 +1,45 @@
+package write
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/influxdata/influxdb-client-go/v2/api"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+
+	"github.com/optakt/wilhelmus/b"
+	"github.com/optakt/wilhelmus/position"
+	"github.com/optakt/wilhelmus/token"
+)
+
+func LendPoint(timestamp time.Time, reserve0 b.Amount, reserve1 b.Amount, lend position.Lend, pair token.Pair, outbound api.WriteAPI) error {
+
+	number, suffix := humanize.ComputeSI(float64(lend.Size))
+	size := humanize.Ftoa(number) + suffix
+
+	value0, err := lend.Value0(reserve0, reserve1)
+	if err != nil {
+		return fmt.Errorf("could not compute value: %w", err)
+	}
+
+	tags := map[string]string{
+		"strategy": "lend",
+		"chain":    pair.ChainSlug,
+		"pair":     pair.Name,
+		"size":     size,
+	}
+	fields := map[string]interface{}{
+		"value":     value0.Float(),
+		"principal": lend.Principal0.Float(),
+		"yield":     lend.Yield0.Float(),
+		"cost":      lend.Cost0.Float(),
+		"rate":      lend.Rate.Float(),
+	}
+
+	point := write.NewPoint("uniswapv2", tags, fields, timestamp)
+	outbound.WritePoint(point)
+
+	return nil
+}
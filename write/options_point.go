@@ -0,0 +1,73 @@
+package write
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/influxdata/influxdb-client-go/v2/api"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+
+	"github.com/optakt/wilhelmus/b"
+	"github.com/optakt/wilhelmus/position"
+	"github.com/optakt/wilhelmus/token"
+)
+
+func OptionsPoint(timestamp time.Time, reserve0 b.Amount, reserve1 b.Amount, options position.Options, pair token.Pair, outbound api.WriteAPI) error {
+
+	number, suffix := humanize.ComputeSI(float64(options.Size))
+	size := humanize.Ftoa(number) + suffix
+
+	value0, err := options.Value0(reserve0, reserve1)
+	if err != nil {
+		return fmt.Errorf("could not compute value: %w", err)
+	}
+
+	loss0, err := b.Sum(options.Fees0, options.Cost0, options.Premium0)
+	if err != nil {
+		return fmt.Errorf("could not compute loss: %w", err)
+	}
+
+	change0, err := b.Sum(options.Profit0, loss0.Neg(), options.Payoff0, options.Mark0)
+	if err != nil {
+		return fmt.Errorf("could not compute change: %w", err)
+	}
+
+	pending0, err := options.Unharvested0(reserve0, reserve1)
+	if err != nil {
+		return fmt.Errorf("could not compute pending fees: %w", err)
+	}
+
+	tags := map[string]string{
+		"strategy": "options",
+		"chain":    pair.ChainSlug,
+		"pair":     pair.Name,
+		"size":     size,
+		"kind":     options.Kind,
+	}
+	fields := map[string]interface{}{
+		"value":         value0.Float(),
+		"mark":          options.Mark0.Float(),
+		"premium":       options.Premium0.Float(),
+		"payoff":        options.Payoff0.Float(),
+		"fees":          options.Fees0.Float(),
+		"cost":          options.Cost0.Float(),
+		"profit":        options.Profit0.Float(),
+		"loss":          loss0.Float(),
+		"change":        change0.Float(),
+		"pending":       pending0.Float(),
+		"harvests":      options.Harvests,
+		"rewards":       options.Rewards.Value0.Float(),
+		"rewards_sold":  options.Rewards.Sold0.Float(),
+		"rewards_fees":  options.Rewards.Fees0.Float(),
+		"rewards_cost":  options.Rewards.Cost0.Float(),
+		"rewards_sales": options.Rewards.Sales,
+		"rolls":         options.Rolls,
+	}
+
+	point := write.NewPoint("uniswapv2", tags, fields, timestamp)
+	outbound.WritePoint(point)
+
+	return nil
+}
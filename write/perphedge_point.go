@@ -0,0 +1,87 @@
+package write
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/influxdata/influxdb-client-go/v2/api"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+
+	"github.com/optakt/wilhelmus/b"
+	"github.com/optakt/wilhelmus/position"
+	"github.com/optakt/wilhelmus/token"
+	"github.com/optakt/wilhelmus/util"
+)
+
+func PerpHedgePoint(timestamp time.Time, reserve0 b.Amount, reserve1 b.Amount, perpHedge position.PerpHedge, pair token.Pair, outbound api.WriteAPI) error {
+
+	number, suffix := humanize.ComputeSI(float64(perpHedge.Size))
+	size := humanize.Ftoa(number) + suffix
+
+	rehedge := humanize.Ftoa(perpHedge.Rehedge.Float()*100) + "%"
+
+	value0, err := perpHedge.Value0(reserve0, reserve1)
+	if err != nil {
+		return fmt.Errorf("could not compute value: %w", err)
+	}
+
+	equity0, err := perpHedge.Equity0(reserve0, reserve1)
+	if err != nil {
+		return fmt.Errorf("could not compute equity: %w", err)
+	}
+
+	short0, err := util.Quote(perpHedge.Short1, reserve1, reserve0)
+	if err != nil {
+		return fmt.Errorf("could not quote short: %w", err)
+	}
+
+	loss0, err := perpHedge.Fees0.Add(perpHedge.Cost0)
+	if err != nil {
+		return fmt.Errorf("could not compute loss: %w", err)
+	}
+
+	change0, err := b.Sum(perpHedge.Profit0, loss0.Neg(), perpHedge.Funding0)
+	if err != nil {
+		return fmt.Errorf("could not compute change: %w", err)
+	}
+
+	pending0, err := perpHedge.Unharvested0(reserve0, reserve1)
+	if err != nil {
+		return fmt.Errorf("could not compute pending fees: %w", err)
+	}
+
+	tags := map[string]string{
+		"strategy": "perphedge",
+		"chain":    pair.ChainSlug,
+		"pair":     pair.Name,
+		"size":     size,
+		"rehedge":  rehedge,
+	}
+	fields := map[string]interface{}{
+		"value":         value0.Float(),
+		"short":         short0.Float(),
+		"margin":        perpHedge.Margin0.Float(),
+		"equity":        equity0.Float(),
+		"funding":       perpHedge.Funding0.Float(),
+		"fees":          perpHedge.Fees0.Float(),
+		"cost":          perpHedge.Cost0.Float(),
+		"profit":        perpHedge.Profit0.Float(),
+		"loss":          loss0.Float(),
+		"change":        change0.Float(),
+		"pending":       pending0.Float(),
+		"harvests":      perpHedge.Harvests,
+		"rewards":       perpHedge.Rewards.Value0.Float(),
+		"rewards_sold":  perpHedge.Rewards.Sold0.Float(),
+		"rewards_fees":  perpHedge.Rewards.Fees0.Float(),
+		"rewards_cost":  perpHedge.Rewards.Cost0.Float(),
+		"rewards_sales": perpHedge.Rewards.Sales,
+		"liquidations":  perpHedge.Liquidations,
+	}
+
+	point := write.NewPoint("uniswapv2", tags, fields, timestamp)
+	outbound.WritePoint(point)
+
+	return nil
+}
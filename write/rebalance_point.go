@@ -0,0 +1,47 @@
+package write
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/influxdata/influxdb-client-go/v2/api"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+
+	"github.com/optakt/wilhelmus/b"
+	"github.com/optakt/wilhelmus/position"
+	"github.com/optakt/wilhelmus/token"
+)
+
+func RebalancePoint(timestamp time.Time, reserve0 b.Amount, reserve1 b.Amount, rebalance position.Rebalance, pair token.Pair, outbound api.WriteAPI) error {
+
+	number, suffix := humanize.ComputeSI(float64(rebalance.Size))
+	size := humanize.Ftoa(number) + suffix
+
+	drift := humanize.Ftoa(rebalance.Drift.Float()*100) + "%"
+
+	value0, err := rebalance.Value0(reserve0, reserve1)
+	if err != nil {
+		return fmt.Errorf("could not compute value: %w", err)
+	}
+
+	tags := map[string]string{
+		"strategy": "rebalance",
+		"chain":    pair.ChainSlug,
+		"pair":     pair.Name,
+		"size":     size,
+		"drift":    drift,
+	}
+	fields := map[string]interface{}{
+		"value": value0.Float(),
+		"fees":  rebalance.Fees0.Float(),
+		"cost":  rebalance.Cost0.Float(),
+		"count": rebalance.Count,
+	}
+
+	point := write.NewPoint("uniswapv2", tags, fields, timestamp)
+	outbound.WritePoint(point)
+
+	return nil
+}
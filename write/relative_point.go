@@ -0,0 +1,28 @@
+package write
+
+import (
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+
+	"github.com/optakt/wilhelmus/analytics"
+	"github.com/optakt/wilhelmus/token"
+)
+
+func RelativePoint(timestamp time.Time, relative analytics.Relative, pair token.Pair, outbound api.WriteAPI) {
+
+	tags := map[string]string{
+		"strategy":  relative.Strategy,
+		"benchmark": relative.Benchmark,
+		"chain":     pair.ChainSlug,
+		"pair":      pair.Name,
+	}
+	fields := map[string]interface{}{
+		"ratio":  relative.Ratio,
+		"excess": relative.Excess,
+	}
+
+	point := write.NewPoint("relative", tags, fields, timestamp)
+	outbound.WritePoint(point)
+}
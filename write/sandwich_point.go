@@ -0,0 +1,35 @@
+package write
+
+import (
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/influxdata/influxdb-client-go/v2/api"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+
+	"github.com/optakt/wilhelmus/b"
+	"github.com/optakt/wilhelmus/token"
+)
+
+func SandwichPoint(timestamp time.Time, strategy string, action string, size uint64, amount0 b.Amount, front0 b.Amount, loss0 b.Amount, pair token.Pair, outbound api.WriteAPI) {
+
+	number, suffix := humanize.ComputeSI(float64(size))
+	sizeLabel := humanize.Ftoa(number) + suffix
+
+	tags := map[string]string{
+		"strategy": strategy,
+		"action":   action,
+		"chain":    pair.ChainSlug,
+		"pair":     pair.Name,
+		"size":     sizeLabel,
+	}
+	fields := map[string]interface{}{
+		"amount": amount0.Float(),
+		"front":  front0.Float(),
+		"loss":   loss0.Float(),
+	}
+
+	point := write.NewPoint("mev", tags, fields, timestamp)
+	outbound.WritePoint(point)
+}
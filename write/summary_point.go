@@ -0,0 +1,45 @@
+package write
+
+import (
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/influxdata/influxdb-client-go/v2/api"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+
+	"github.com/optakt/wilhelmus/analytics"
+	"github.com/optakt/wilhelmus/token"
+)
+
+func SummaryPoint(timestamp time.Time, size uint64, summary analytics.Summary, pair token.Pair, outbound api.WriteAPI) {
+
+	number, suffix := humanize.ComputeSI(float64(size))
+	sizeLabel := humanize.Ftoa(number) + suffix
+
+	tags := map[string]string{
+		"strategy": summary.Strategy,
+		"chain":    pair.ChainSlug,
+		"pair":     pair.Name,
+		"size":     sizeLabel,
+	}
+	fields := map[string]interface{}{
+		"return":            summary.Return,
+		"apr":               summary.APR,
+		"apy":               summary.APY,
+		"volatility":        summary.Volatility,
+		"sharpe":            summary.Sharpe,
+		"sortino":           summary.Sortino,
+		"max_drawdown":      summary.MaxDrawdown,
+		"drawdown_duration": summary.DrawdownDuration.Hours(),
+		"calmar":            summary.Calmar,
+		"actions":           int64(summary.Actions),
+		"fees":              summary.Fees,
+		"costs":             summary.Costs,
+		"twr":               summary.TWR,
+		"mwr":               summary.MWR,
+	}
+
+	point := write.NewPoint("summary", tags, fields, timestamp)
+	outbound.WritePoint(point)
+}
@@ -1,7 +1,7 @@
 package write
 
 import (
-	"math/big"
+	"fmt"
 	"time"
 
 	"github.com/dustin/go-humanize"
@@ -11,31 +11,58 @@ import (
 
 	"github.com/optakt/wilhelmus/b"
 	"github.com/optakt/wilhelmus/position"
+	"github.com/optakt/wilhelmus/token"
 )
 
-func UniswapPoint(timestamp time.Time, reserve0 *big.Int, reserve1 *big.Int, uniswap position.Uniswap, outbound api.WriteAPI) {
+func UniswapPoint(timestamp time.Time, reserve0 b.Amount, reserve1 b.Amount, uniswap position.Uniswap, pair token.Pair, outbound api.WriteAPI) error {
 
 	number, suffix := humanize.ComputeSI(float64(uniswap.Size))
 	size := humanize.Ftoa(number) + suffix
 
-	loss0 := big.NewInt(0).Add(uniswap.Fees0, uniswap.Cost0)
+	value0, err := uniswap.Value0(reserve0, reserve1)
+	if err != nil {
+		return fmt.Errorf("could not compute value: %w", err)
+	}
+
+	loss0, err := uniswap.Fees0.Add(uniswap.Cost0)
+	if err != nil {
+		return fmt.Errorf("could not compute loss: %w", err)
+	}
 
-	change0 := big.NewInt(0).Sub(uniswap.Profit0, loss0)
+	change0, err := uniswap.Profit0.Sub(loss0)
+	if err != nil {
+		return fmt.Errorf("could not compute change: %w", err)
+	}
+
+	pending0, err := uniswap.Unharvested0(reserve0, reserve1)
+	if err != nil {
+		return fmt.Errorf("could not compute pending fees: %w", err)
+	}
 
 	tags := map[string]string{
 		"strategy": "uniswap",
-		"chain":    "ethereum",
+		"chain":    pair.ChainSlug,
+		"pair":     pair.Name,
 		"size":     size,
 	}
 	fields := map[string]interface{}{
-		"value":  b.ToFloat(uniswap.Value0(reserve0, reserve1), 6),
-		"fees":   b.ToFloat(uniswap.Fees0, 6),
-		"cost":   b.ToFloat(uniswap.Cost0, 6),
-		"profit": b.ToFloat(uniswap.Profit0, 6),
-		"loss":   b.ToFloat(loss0, 6),
-		"change": b.ToFloat(change0, 6),
+		"value":         value0.Float(),
+		"fees":          uniswap.Fees0.Float(),
+		"cost":          uniswap.Cost0.Float(),
+		"profit":        uniswap.Profit0.Float(),
+		"loss":          loss0.Float(),
+		"change":        change0.Float(),
+		"pending":       pending0.Float(),
+		"harvests":      uniswap.Harvests,
+		"rewards":       uniswap.Rewards.Value0.Float(),
+		"rewards_sold":  uniswap.Rewards.Sold0.Float(),
+		"rewards_fees":  uniswap.Rewards.Fees0.Float(),
+		"rewards_cost":  uniswap.Rewards.Cost0.Float(),
+		"rewards_sales": uniswap.Rewards.Sales,
 	}
 
 	point := write.NewPoint("uniswapv2", tags, fields, timestamp)
 	outbound.WritePoint(point)
+
+	return nil
 }